@@ -34,6 +34,7 @@ func init() {
 	rootCmd.AddCommand(addCmd)
 
 	persistentFlags := addCmd.PersistentFlags()
+	persistentFlags.BoolVar(&config.add.options.Create, "create", false, "add files that should only be created if they do not exist")
 	persistentFlags.BoolVarP(&config.add.options.Empty, "empty", "e", false, "add empty files")
 	persistentFlags.BoolVar(&config.add.options.Encrypt, "encrypt", false, "encrypt files")
 	persistentFlags.BoolVarP(&config.add.force, "force", "f", false, "overwrite source state, even if template would be lost")
@@ -80,10 +81,21 @@ func (c *Config) runAddCmd(cmd *cobra.Command, args []string) (err error) {
 				if err != nil {
 					return err
 				}
-				if ts.TargetIgnore.Match(strings.TrimPrefix(path, destDirPrefix)) {
+				ignoreName := strings.TrimPrefix(path, destDirPrefix)
+				if info.IsDir() {
+					ignoreName += "/"
+				}
+				if ts.TargetIgnore.Match(ignoreName) {
 					cmd.Printf("warning: %s: skipping file ignored by .chezmoiignore\n", path)
 					return nil
 				}
+				if ts.IsExternal(strings.TrimPrefix(path, destDirPrefix)) {
+					cmd.Printf("warning: %s: skipping external\n", path)
+					if info.IsDir() {
+						return vfs.SkipDir
+					}
+					return nil
+				}
 				if !c.add.force {
 					entry, err := ts.Get(c.fs, path)
 					if err != nil && !os.IsNotExist(err) {
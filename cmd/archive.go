@@ -2,10 +2,10 @@ package cmd
 
 import (
 	"archive/tar"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
+	vfs "github.com/twpayne/go-vfs"
 )
 
 type archiveCmdConfig struct {
@@ -36,9 +36,14 @@ func (c *Config) runArchiveCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	applyOptions, err := c.getApplyOptions(ts, nil)
+	if err != nil {
+		return err
+	}
+
 	output := &strings.Builder{}
 	w := tar.NewWriter(output)
-	if err := ts.Archive(w, os.FileMode(c.Umask)); err != nil {
+	if err := ts.Archive(vfs.NewReadOnlyFS(c.fs), w, applyOptions); err != nil {
 		return err
 	}
 	if err := w.Close(); err != nil {
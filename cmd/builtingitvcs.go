@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"github.com/twpayne/chezmoi/internal/git"
+)
+
+// A builtinGitVCS is a git VCS implemented in-process with go-git, for use
+// when the git binary is not available.
+type builtinGitVCS struct {
+	gitVCS
+}
+
+// A builtinGitLoader loads local bare and non-bare repositories for go-git's
+// in-process file transport.
+type builtinGitLoader struct{}
+
+// installBuiltinGitFileTransportOnce guards the installation of the in-process
+// file transport.
+var installBuiltinGitFileTransportOnce sync.Once
+
+// installBuiltinGitFileTransport replaces go-git's default file transport,
+// which runs git-upload-pack and git-receive-pack, with one that serves local
+// repositories in-process. go-git's transports are global and cannot be set per
+// operation, so it is only installed when the builtin git VCS first uses a
+// remote.
+func installBuiltinGitFileTransport() {
+	installBuiltinGitFileTransportOnce.Do(func() {
+		client.InstallProtocol("file", server.NewClient(builtinGitLoader{}))
+	})
+}
+
+// Add stages all changes to paths in the worktree dir below path, including
+// removals.
+func (builtinGitVCS) Add(dir, path string) error {
+	worktree, err := openBuiltinGitWorktree(dir)
+	if err != nil {
+		return err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	prefix := filepath.ToSlash(path)
+	for _, name := range sortedBuiltinGitStatusNames(status) {
+		if prefix != "." && name != prefix && !strings.HasPrefix(name, prefix+"/") {
+			continue
+		}
+		switch status[name].Worktree {
+		case gogit.Unmodified:
+			continue
+		case gogit.Deleted:
+			_, err = worktree.Remove(name)
+		default:
+			_, err = worktree.Add(name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clone clones repo into dir, including submodules.
+func (builtinGitVCS) Clone(repo, dir string) error {
+	installBuiltinGitFileTransport()
+	_, err := gogit.PlainClone(dir, false, &gogit.CloneOptions{
+		URL:               repo,
+		RecurseSubmodules: gogit.DefaultSubmoduleRecursionDepth,
+	})
+	return err
+}
+
+// Commit commits the staged changes in the worktree dir with message.
+func (builtinGitVCS) Commit(dir, message string) error {
+	worktree, err := openBuiltinGitWorktree(dir)
+	if err != nil {
+		return err
+	}
+	_, err = worktree.Commit(message, &gogit.CommitOptions{})
+	return err
+}
+
+// Init creates a new repository in dir.
+func (builtinGitVCS) Init(dir string) error {
+	_, err := gogit.PlainInit(dir, false)
+	return err
+}
+
+// Pull fast-forwards the worktree dir to its remote branch.
+func (builtinGitVCS) Pull(dir string) error {
+	installBuiltinGitFileTransport()
+	worktree, err := openBuiltinGitWorktree(dir)
+	if err != nil {
+		return err
+	}
+	if err := worktree.Pull(&gogit.PullOptions{
+		RecurseSubmodules: gogit.DefaultSubmoduleRecursionDepth,
+	}); err != nil && err != gogit.NoErrAlreadyUpToDate {
+		return err
+	}
+	return nil
+}
+
+// Push pushes the repository dir to its remote.
+func (builtinGitVCS) Push(dir string) error {
+	installBuiltinGitFileTransport()
+	repo, err := gogit.PlainOpen(dir)
+	if err != nil {
+		return err
+	}
+	if err := repo.Push(&gogit.PushOptions{}); err != nil && err != gogit.NoErrAlreadyUpToDate {
+		return err
+	}
+	return nil
+}
+
+// Status returns the status of the worktree dir, including its branch.
+func (builtinGitVCS) Status(dir string) (*git.Status, error) {
+	repo, err := gogit.PlainOpen(dir)
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	branch, err := builtinGitBranchStatus(repo)
+	if err != nil {
+		return nil, err
+	}
+	result := &git.Status{
+		Branch: branch,
+	}
+	for _, name := range sortedBuiltinGitStatusNames(status) {
+		fileStatus := status[name]
+		x := builtinGitStatusCode(fileStatus.Staging)
+		y := builtinGitStatusCode(fileStatus.Worktree)
+		switch {
+		case fileStatus.Staging == gogit.Untracked:
+			result.Untracked = append(result.Untracked, git.UntrackedStatus{
+				Path: name,
+			})
+		case fileStatus.Staging == gogit.Renamed || fileStatus.Staging == gogit.Copied:
+			result.RenamedOrCopied = append(result.RenamedOrCopied, git.RenamedOrCopiedStatus{
+				X:        x,
+				Y:        y,
+				Path:     name,
+				OrigPath: fileStatus.Extra,
+			})
+		case fileStatus.Staging == gogit.UpdatedButUnmerged || fileStatus.Worktree == gogit.UpdatedButUnmerged:
+			result.Unmerged = append(result.Unmerged, git.UnmergedStatus{
+				X:    x,
+				Y:    y,
+				Path: name,
+			})
+		default:
+			result.Ordinary = append(result.Ordinary, git.OrdinaryStatus{
+				X:    x,
+				Y:    y,
+				Path: name,
+			})
+		}
+	}
+	return result, nil
+}
+
+// Load loads the repository at ep's path, or at the .git directory below it.
+func (builtinGitLoader) Load(ep *transport.Endpoint) (storer.Storer, error) {
+	for _, dir := range []string{
+		ep.Path,
+		filepath.Join(ep.Path, gogit.GitDirName),
+	} {
+		if _, err := os.Stat(filepath.Join(dir, "HEAD")); err == nil {
+			return filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault()), nil
+		}
+	}
+	return nil, transport.ErrRepositoryNotFound
+}
+
+// builtinGitAncestors returns the set of commits reachable from hash in repo,
+// including hash itself.
+func builtinGitAncestors(repo *gogit.Repository, hash plumbing.Hash) (map[plumbing.Hash]struct{}, error) {
+	commits, err := repo.Log(&gogit.LogOptions{
+		From: hash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ancestors := make(map[plumbing.Hash]struct{})
+	if err := commits.ForEach(func(commit *object.Commit) error {
+		ancestors[commit.Hash] = struct{}{}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ancestors, nil
+}
+
+// builtinGitBranchStatus returns the status of the current branch of repo, in
+// the same form as git status --branch --porcelain=v2.
+func builtinGitBranchStatus(repo *gogit.Repository) (*git.BranchStatus, error) {
+	head, err := repo.Head()
+	switch {
+	case err == plumbing.ErrReferenceNotFound:
+		// There are no commits yet, so HEAD refers to a branch that does not
+		// exist.
+		symbolicHead, err := repo.Storer.Reference(plumbing.HEAD)
+		if err != nil {
+			return nil, err
+		}
+		return &git.BranchStatus{
+			OID:  "(initial)",
+			Head: symbolicHead.Target().Short(),
+		}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	branchStatus := &git.BranchStatus{
+		OID: head.Hash().String(),
+	}
+	if !head.Name().IsBranch() {
+		branchStatus.Head = "(detached)"
+		return branchStatus, nil
+	}
+	branchStatus.Head = head.Name().Short()
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, err
+	}
+	branch, ok := cfg.Branches[branchStatus.Head]
+	if !ok || branch.Remote == "" || branch.Merge == "" {
+		return branchStatus, nil
+	}
+	branchStatus.Upstream = branch.Remote + "/" + branch.Merge.Short()
+
+	// As with git, only report the ahead and behind counts if the upstream
+	// branch exists.
+	upstream, err := repo.Reference(plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short()), true)
+	switch {
+	case err == plumbing.ErrReferenceNotFound:
+		return branchStatus, nil
+	case err != nil:
+		return nil, err
+	}
+	headAncestors, err := builtinGitAncestors(repo, head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	upstreamAncestors, err := builtinGitAncestors(repo, upstream.Hash())
+	if err != nil {
+		return nil, err
+	}
+	for hash := range headAncestors {
+		if _, ok := upstreamAncestors[hash]; !ok {
+			branchStatus.Ahead++
+		}
+	}
+	for hash := range upstreamAncestors {
+		if _, ok := headAncestors[hash]; !ok {
+			branchStatus.Behind++
+		}
+	}
+	return branchStatus, nil
+}
+
+// builtinGitStatusCode returns the porcelain v2 status code for code.
+func builtinGitStatusCode(code gogit.StatusCode) byte {
+	if code == gogit.Unmodified {
+		return '.'
+	}
+	return byte(code)
+}
+
+// openBuiltinGitWorktree opens the worktree of the repository in dir.
+func openBuiltinGitWorktree(dir string) (*gogit.Worktree, error) {
+	repo, err := gogit.PlainOpen(dir)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Worktree()
+}
+
+// sortedBuiltinGitStatusNames returns the names in status in order.
+func sortedBuiltinGitStatusNames(status gogit.Status) []string {
+	names := make([]string, 0, len(status))
+	for name := range status {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/twpayne/chezmoi/internal/git"
+)
+
+func TestBuiltinGitVCS(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "chezmoi")
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, os.RemoveAll(tempDir))
+	}()
+
+	vcs := builtinGitVCS{}
+	bareDir := filepath.Join(tempDir, "bare.git")
+	workDir := filepath.Join(tempDir, "work")
+	cloneDir := filepath.Join(tempDir, "clone")
+
+	_, err = gogit.PlainInit(bareDir, true)
+	require.NoError(t, err)
+
+	require.NoError(t, vcs.Init(workDir))
+	initialized, err := vcs.Initialized(workDir)
+	require.NoError(t, err)
+	assert.True(t, initialized)
+	repo, err := gogit.PlainOpen(workDir)
+	require.NoError(t, err)
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	cfg.User.Name = "Your Name"
+	cfg.User.Email = "you@example.com"
+	require.NoError(t, repo.SetConfig(cfg))
+	_, err = repo.CreateRemote(&gogitconfig.RemoteConfig{
+		Name: "origin",
+		URLs: []string{bareDir},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(workDir, "dot_bashrc"), []byte("# contents of .bashrc\n"), 0o666))
+	status, err := vcs.Status(workDir)
+	require.NoError(t, err)
+	assert.Equal(t, &git.Status{
+		Branch: &git.BranchStatus{
+			OID:  "(initial)",
+			Head: "master",
+		},
+		Untracked: []git.UntrackedStatus{
+			{Path: "dot_bashrc"},
+		},
+	}, status)
+
+	require.NoError(t, vcs.Add(workDir, "."))
+	status, err = vcs.Status(workDir)
+	require.NoError(t, err)
+	assert.Equal(t, &git.Status{
+		Branch: &git.BranchStatus{
+			OID:  "(initial)",
+			Head: "master",
+		},
+		Ordinary: []git.OrdinaryStatus{
+			{X: 'A', Y: '.', Path: "dot_bashrc"},
+		},
+	}, status)
+	require.NoError(t, vcs.Commit(workDir, "Add dot_bashrc"))
+	require.NoError(t, vcs.Push(workDir))
+	require.NoError(t, vcs.Push(workDir))
+
+	require.NoError(t, vcs.Clone(bareDir, cloneDir))
+	actualContents, err := ioutil.ReadFile(filepath.Join(cloneDir, "dot_bashrc"))
+	require.NoError(t, err)
+	assert.Equal(t, "# contents of .bashrc\n", string(actualContents))
+	status, err = vcs.Status(cloneDir)
+	require.NoError(t, err)
+	assert.True(t, status.Empty())
+	assert.Equal(t, "master", status.Branch.Head)
+	assert.Equal(t, "origin/master", status.Branch.Upstream)
+	assert.Equal(t, 0, status.Branch.Ahead)
+	assert.Equal(t, 0, status.Branch.Behind)
+
+	require.NoError(t, os.Remove(filepath.Join(workDir, "dot_bashrc")))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(workDir, "dot_zshrc"), []byte("# contents of .zshrc\n"), 0o666))
+	require.NoError(t, vcs.Add(workDir, "."))
+	status, err = vcs.Status(workDir)
+	require.NoError(t, err)
+	assert.Equal(t, []git.OrdinaryStatus{
+		{X: 'D', Y: '.', Path: "dot_bashrc"},
+		{X: 'A', Y: '.', Path: "dot_zshrc"},
+	}, status.Ordinary)
+	assert.Equal(t, "master", status.Branch.Head)
+	assert.Equal(t, "", status.Branch.Upstream)
+	require.NoError(t, vcs.Commit(workDir, "Replace dot_bashrc with dot_zshrc"))
+	require.NoError(t, vcs.Push(workDir))
+
+	require.NoError(t, vcs.Pull(cloneDir))
+	require.NoError(t, vcs.Pull(cloneDir))
+	_, err = os.Stat(filepath.Join(cloneDir, "dot_bashrc"))
+	assert.True(t, os.IsNotExist(err))
+	actualContents, err = ioutil.ReadFile(filepath.Join(cloneDir, "dot_zshrc"))
+	require.NoError(t, err)
+	assert.Equal(t, "# contents of .zshrc\n", string(actualContents))
+
+	// Create diverged branches by fetching a commit pushed from the work dir
+	// and then committing in the clone.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(workDir, "dot_zshrc"), []byte("# edited\n"), 0o666))
+	require.NoError(t, vcs.Add(workDir, "."))
+	require.NoError(t, vcs.Commit(workDir, "Update dot_zshrc"))
+	require.NoError(t, vcs.Push(workDir))
+	cloneRepo, err := gogit.PlainOpen(cloneDir)
+	require.NoError(t, err)
+	require.NoError(t, cloneRepo.Fetch(&gogit.FetchOptions{}))
+	status, err = vcs.Status(cloneDir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, status.Branch.Ahead)
+	assert.Equal(t, 1, status.Branch.Behind)
+	assert.False(t, status.Branch.Diverged())
+	cloneCfg, err := cloneRepo.Config()
+	require.NoError(t, err)
+	cloneCfg.User = cfg.User
+	require.NoError(t, cloneRepo.SetConfig(cloneCfg))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cloneDir, "dot_vimrc"), []byte("# contents of .vimrc\n"), 0o666))
+	require.NoError(t, vcs.Add(cloneDir, "."))
+	require.NoError(t, vcs.Commit(cloneDir, "Add dot_vimrc"))
+	status, err = vcs.Status(cloneDir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.Branch.Ahead)
+	assert.Equal(t, 1, status.Branch.Behind)
+	assert.True(t, status.Branch.Diverged())
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	vfs "github.com/twpayne/go-vfs"
 
 	"github.com/twpayne/chezmoi/internal/chezmoi"
 )
@@ -11,7 +12,7 @@ import (
 var catCmd = &cobra.Command{
 	Use:     "cat targets...",
 	Args:    cobra.MinimumNArgs(1),
-	Short:   "Print the target contents of a file or symlink",
+	Short:   "Print the target contents of a file, script, or symlink",
 	Long:    mustGetLongHelp("cat"),
 	Example: getExample("cat"),
 	PreRunE: config.ensureNoError,
@@ -33,9 +34,22 @@ func (c *Config) runCatCmd(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	applyOptions, err := c.getApplyOptions(ts, nil)
+	if err != nil {
+		return err
+	}
+	fs := vfs.NewReadOnlyFS(c.fs)
 	for i, entry := range entries {
 		switch entry := entry.(type) {
 		case *chezmoi.File:
+			contents, err := entry.TargetContents(fs, chezmoi.NullMutator{}, applyOptions)
+			if err != nil {
+				return err
+			}
+			if _, err := c.Stdout.Write(contents); err != nil {
+				return err
+			}
+		case *chezmoi.Script:
 			contents, err := entry.Contents()
 			if err != nil {
 				return err
@@ -50,7 +64,7 @@ func (c *Config) runCatCmd(cmd *cobra.Command, args []string) error {
 			}
 			fmt.Println(linkname)
 		default:
-			return fmt.Errorf("%s: not a file or symlink", args[i])
+			return fmt.Errorf("%s: not a file, script, or symlink", args[i])
 		}
 	}
 	return nil
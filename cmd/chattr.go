@@ -26,6 +26,7 @@ var chattrCmd = &cobra.Command{
 type boolModifier int
 
 type attributeModifiers struct {
+	create     boolModifier
 	empty      boolModifier
 	encrypted  boolModifier
 	exact      boolModifier
@@ -38,6 +39,7 @@ func init() {
 	rootCmd.AddCommand(chattrCmd)
 
 	attributes := []string{
+		"create",
 		"empty", "e",
 		"encrypted",
 		"exact",
@@ -64,7 +66,7 @@ func (c *Config) runChattrCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	entries, err := c.getEntries(ts, args[1:])
+	entries, err := c.getSourceEntries(ts, args[1:])
 	if err != nil {
 		return err
 	}
@@ -99,6 +101,10 @@ func (c *Config) runChattrCmd(cmd *cobra.Command, args []string) error {
 				mode &= 0o700
 			}
 			fa.Mode = mode
+			fa.Create = ams.create.modify(entry.Create)
+			if fa.Create && (fa.Block || fa.Merge || fa.Modify) {
+				return fmt.Errorf("%s: create cannot be combined with block, merge, or modify", entry.TargetName())
+			}
 			fa.Encrypted = ams.encrypted.modify(entry.Encrypted)
 			fa.Empty = ams.empty.modify(entry.Empty)
 			fa.Template = ams.template.modify(entry.Template)
@@ -110,9 +116,9 @@ func (c *Config) runChattrCmd(cmd *cobra.Command, args []string) error {
 				}
 				var newContents []byte
 				if fa.Encrypted {
-					newContents, err = ts.GPG.Encrypt(entry.TargetName(), oldContents)
+					newContents, err = ts.Encryption.Encrypt(entry.TargetName(), oldContents)
 				} else {
-					newContents, err = ts.GPG.Decrypt(entry.TargetName(), oldContents)
+					newContents, err = ts.Encryption.Decrypt(entry.TargetName(), oldContents)
 				}
 				if err != nil {
 					return err
@@ -184,6 +190,8 @@ func parseAttributeModifiers(s string) (*attributeModifiers, error) {
 			attribute = attributeModifier
 		}
 		switch attribute {
+		case "create":
+			ams.create = modifier
 		case "empty", "e":
 			ams.empty = modifier
 		case "encrypted":
@@ -10,10 +10,11 @@ import (
 
 func TestChattrCommand(t *testing.T) {
 	for _, tc := range []struct {
-		name  string
-		args  []string
-		root  interface{}
-		tests interface{}
+		name    string
+		args    []string
+		root    interface{}
+		wantErr bool
+		tests   interface{}
 	}{
 		{
 			name: "dir_add_exact",
@@ -247,6 +248,62 @@ func TestChattrCommand(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "file_add_create_to_block",
+			args: []string{"+create", "/home/user/foo"},
+			root: map[string]interface{}{
+				"/home/user/.local/share/chezmoi": map[string]interface{}{
+					"block_foo": "# contents of block in ~/foo\n",
+				},
+			},
+			wantErr: true,
+			tests: []vfst.Test{
+				vfst.TestPath("/home/user/.local/share/chezmoi/block_foo",
+					vfst.TestModeIsRegular,
+					vfst.TestContentsString("# contents of block in ~/foo\n"),
+				),
+				vfst.TestPath("/home/user/.local/share/chezmoi/create_foo",
+					vfst.TestDoesNotExist,
+				),
+			},
+		},
+		{
+			name: "file_add_create_to_merge",
+			args: []string{"+create", "/home/user/foo.json"},
+			root: map[string]interface{}{
+				"/home/user/.local/share/chezmoi": map[string]interface{}{
+					"merge_foo.json": "{}\n",
+				},
+			},
+			wantErr: true,
+			tests: []vfst.Test{
+				vfst.TestPath("/home/user/.local/share/chezmoi/merge_foo.json",
+					vfst.TestModeIsRegular,
+				),
+				vfst.TestPath("/home/user/.local/share/chezmoi/create_foo.json",
+					vfst.TestDoesNotExist,
+				),
+			},
+		},
+		{
+			name: "file_add_create_to_modify",
+			args: []string{"+create", "/home/user/foo"},
+			root: map[string]interface{}{
+				"/home/user/.local/share/chezmoi": map[string]interface{}{
+					"modify_foo": "#!/bin/sh\n\ncat\n",
+				},
+			},
+			wantErr: true,
+			tests: []vfst.Test{
+				vfst.TestPath("/home/user/.local/share/chezmoi/modify_foo",
+					vfst.TestModeIsRegular,
+					vfst.TestContentsString("#!/bin/sh\n\ncat\n"),
+				),
+				vfst.TestPath("/home/user/.local/share/chezmoi/create_foo",
+					vfst.TestDoesNotExist,
+				),
+			},
+		},
 		{
 			name: "symlink_add_template",
 			args: []string{"+template", "/home/user/foo"},
@@ -289,7 +346,11 @@ func TestChattrCommand(t *testing.T) {
 			require.NoError(t, err)
 			defer cleanup()
 			c := newTestConfig(fs)
-			assert.NoError(t, c.runChattrCmd(nil, tc.args))
+			if tc.wantErr {
+				assert.Error(t, c.runChattrCmd(nil, tc.args))
+			} else {
+				assert.NoError(t, c.runChattrCmd(nil, tc.args))
+			}
 			vfst.RunTests(t, fs, "", tc.tests)
 		})
 	}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/twpayne/chezmoi/internal/chezmoi"
+	"github.com/twpayne/chezmoi/internal/git"
+)
+
+// A commitMessageData is the data passed to commit message templates. It is a
+// git.Status where each source path is annotated with its target, and the name
+// of the command that triggered the commit.
+type commitMessageData struct {
+	Command         string
+	Ordinary        []commitMessageOrdinaryStatus
+	RenamedOrCopied []commitMessageRenamedOrCopiedStatus
+	Unmerged        []commitMessageUnmergedStatus
+	Untracked       []commitMessageUntrackedStatus
+	Ignored         []git.IgnoredStatus
+}
+
+// A commitMessageTarget describes the target of a source path. TargetName is
+// empty if the source path is not the source of a target, for example
+// .chezmoiignore.
+type commitMessageTarget struct {
+	TargetName string
+	Type       string
+	Attributes []string
+}
+
+type commitMessageOrdinaryStatus struct {
+	git.OrdinaryStatus
+	commitMessageTarget
+}
+
+type commitMessageRenamedOrCopiedStatus struct {
+	git.RenamedOrCopiedStatus
+	commitMessageTarget
+	OrigTargetName string
+}
+
+type commitMessageUnmergedStatus struct {
+	git.UnmergedStatus
+	commitMessageTarget
+}
+
+type commitMessageUntrackedStatus struct {
+	git.UntrackedStatus
+	commitMessageTarget
+}
+
+// commitMessage returns the commit message for status after command.
+func (c *Config) commitMessage(command string, status *git.Status) (string, error) {
+	name, text, err := c.commitMessageTemplate()
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New(name).Funcs(c.templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	sb := &strings.Builder{}
+	if err := tmpl.Execute(sb, newCommitMessageData(command, status)); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// commitMessageTemplate returns the name and text of the commit message
+// template. The template in the config file takes precedence over the template
+// in the source directory, which takes precedence over the default template.
+func (c *Config) commitMessageTemplate() (string, string, error) {
+	if c.SourceVCS.CommitMessageTemplate != "" {
+		return "sourceVCS.commitMessageTemplate", c.SourceVCS.CommitMessageTemplate, nil
+	}
+	path := filepath.Join(c.SourceDir, commitMessageTemplateName)
+	data, err := c.fs.ReadFile(path)
+	switch {
+	case err == nil:
+		return path, string(data), nil
+	case !os.IsNotExist(err):
+		return "", "", err
+	}
+	data, err = getAsset(commitMessageTemplateAsset)
+	if err != nil {
+		return "", "", err
+	}
+	return "commit_message", string(data), nil
+}
+
+// newCommitMessageData returns a new commitMessageData for status after
+// command.
+func newCommitMessageData(command string, status *git.Status) *commitMessageData {
+	data := &commitMessageData{
+		Command: command,
+		Ignored: status.Ignored,
+	}
+	for _, s := range status.Ordinary {
+		data.Ordinary = append(data.Ordinary, commitMessageOrdinaryStatus{
+			OrdinaryStatus:      s,
+			commitMessageTarget: newCommitMessageTarget(s.Path),
+		})
+	}
+	for _, s := range status.RenamedOrCopied {
+		data.RenamedOrCopied = append(data.RenamedOrCopied, commitMessageRenamedOrCopiedStatus{
+			RenamedOrCopiedStatus: s,
+			commitMessageTarget:   newCommitMessageTarget(s.Path),
+			OrigTargetName:        newCommitMessageTarget(s.OrigPath).TargetName,
+		})
+	}
+	for _, s := range status.Unmerged {
+		data.Unmerged = append(data.Unmerged, commitMessageUnmergedStatus{
+			UnmergedStatus:      s,
+			commitMessageTarget: newCommitMessageTarget(s.Path),
+		})
+	}
+	for _, s := range status.Untracked {
+		data.Untracked = append(data.Untracked, commitMessageUntrackedStatus{
+			UntrackedStatus:     s,
+			commitMessageTarget: newCommitMessageTarget(s.Path),
+		})
+	}
+	return data
+}
+
+// newCommitMessageTarget returns the target of the slash-separated source path
+// path. A .keep file is treated as its parent directory.
+func newCommitMessageTarget(path string) commitMessageTarget {
+	components := strings.Split(path, "/")
+	keep := len(components) > 1 && components[len(components)-1] == ".keep"
+	if keep {
+		components = components[:len(components)-1]
+	}
+	for _, component := range components {
+		if strings.HasPrefix(component, ".") {
+			return commitMessageTarget{
+				Attributes: []string{},
+			}
+		}
+	}
+	var entryType string
+	attributes := []string{}
+	addAttribute := func(attribute string, ok bool) {
+		if ok {
+			attributes = append(attributes, attribute)
+		}
+	}
+	if keep {
+		targetNames := make([]string, 0, len(components))
+		var da chezmoi.DirAttributes
+		for _, component := range components {
+			da = chezmoi.ParseDirAttributes(component)
+			targetNames = append(targetNames, da.Name)
+		}
+		addAttribute("exact", da.Exact)
+		addAttribute("private", da.Perm&0o77 == 0)
+		return commitMessageTarget{
+			TargetName: strings.Join(targetNames, "/"),
+			Type:       "dir",
+			Attributes: attributes,
+		}
+	}
+	targetName, fa, sa := chezmoi.ParseSourceFilePath(filepath.FromSlash(path))
+	switch {
+	case sa != nil:
+		entryType = "script"
+		addAttribute("after", sa.After)
+		addAttribute("before", sa.Before)
+		addAttribute("encrypted", sa.Encrypted)
+		addAttribute("once", sa.Once)
+		addAttribute("onchange", sa.OnChange)
+		addAttribute("template", sa.Template)
+	case fa.Mode&os.ModeType == os.ModeSymlink:
+		entryType = "symlink"
+		addAttribute("template", fa.Template)
+	default:
+		entryType = "file"
+		addAttribute("block", fa.Block)
+		addAttribute("create", fa.Create)
+		addAttribute("empty", fa.Empty)
+		addAttribute("encrypted", fa.Encrypted)
+		addAttribute("executable", fa.Mode&0o111 != 0)
+		addAttribute("merge", fa.Merge)
+		addAttribute("modify", fa.Modify)
+		addAttribute("private", fa.Mode&0o77 == 0)
+		addAttribute("template", fa.Template)
+	}
+	return commitMessageTarget{
+		TargetName: filepath.ToSlash(targetName),
+		Type:       entryType,
+		Attributes: attributes,
+	}
+}
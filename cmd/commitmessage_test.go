@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-vfs/vfst"
+
+	"github.com/twpayne/chezmoi/internal/git"
+)
+
+func TestCommitMessage(t *testing.T) {
+	status := &git.Status{
+		Ordinary: []git.OrdinaryStatus{
+			{X: 'A', Y: '.', Path: "private_dot_ssh/encrypted_private_config"},
+			{X: 'M', Y: '.', Path: ".chezmoiignore"},
+		},
+		RenamedOrCopied: []git.RenamedOrCopiedStatus{
+			{X: 'R', Y: '.', Path: "dot_zshrc", OrigPath: "dot_bashrc"},
+		},
+	}
+	for _, tc := range []struct {
+		name                  string
+		root                  interface{}
+		commitMessageTemplate string
+		expectedMessage       string
+	}{
+		{
+			name: "default",
+			root: map[string]interface{}{
+				"/home/user/.local/share/chezmoi": &vfst.Dir{Perm: 0o700},
+			},
+			expectedMessage: "" +
+				"Add private_dot_ssh/encrypted_private_config\n" +
+				"Update .chezmoiignore\n" +
+				"Rename dot_bashrc to dot_zshrc\n",
+		},
+		{
+			name: "source_dir",
+			root: map[string]interface{}{
+				"/home/user/.local/share/chezmoi/.chezmoicommitmessage.tmpl": "" +
+					"chore({{ .Command }}): update dotfiles\n" +
+					"{{ range .Ordinary }}\n" +
+					"{{ .X | printf \"%c\" }} {{ .TargetName | default .Path }} {{ .Type }} {{ .Attributes | join \",\" }}" +
+					"{{ end }}\n" +
+					"{{ range .RenamedOrCopied }}" +
+					"{{ .X | printf \"%c\" }} {{ .OrigTargetName }} -> {{ .TargetName }}\n" +
+					"{{ end }}",
+			},
+			expectedMessage: "" +
+				"chore(add): update dotfiles\n" +
+				"\n" +
+				"A .ssh/config file encrypted,private\n" +
+				"M .chezmoiignore  \n" +
+				"R .bashrc -> .zshrc\n",
+		},
+		{
+			name: "config",
+			root: map[string]interface{}{
+				"/home/user/.local/share/chezmoi/.chezmoicommitmessage.tmpl": "source dir\n",
+			},
+			commitMessageTemplate: "config {{ .Command }}\n",
+			expectedMessage:       "config add\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fs, cleanup, err := vfst.NewTestFS(tc.root)
+			require.NoError(t, err)
+			defer cleanup()
+
+			c := newTestConfig(fs)
+			c.SourceVCS.CommitMessageTemplate = tc.commitMessageTemplate
+			actualMessage, err := c.commitMessage("add", status)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMessage, actualMessage)
+		})
+	}
+}
+
+func TestNewCommitMessageTarget(t *testing.T) {
+	for _, tc := range []struct {
+		path     string
+		expected commitMessageTarget
+	}{
+		{
+			path: "dot_bashrc",
+			expected: commitMessageTarget{
+				TargetName: ".bashrc",
+				Type:       "file",
+				Attributes: []string{},
+			},
+		},
+		{
+			path: "private_dot_ssh/encrypted_private_config.tmpl",
+			expected: commitMessageTarget{
+				TargetName: ".ssh/config",
+				Type:       "file",
+				Attributes: []string{"encrypted", "private", "template"},
+			},
+		},
+		{
+			path: "dot_local/bin/executable_foo",
+			expected: commitMessageTarget{
+				TargetName: ".local/bin/foo",
+				Type:       "file",
+				Attributes: []string{"executable"},
+			},
+		},
+		{
+			path: "symlink_dot_vimrc.tmpl",
+			expected: commitMessageTarget{
+				TargetName: ".vimrc",
+				Type:       "symlink",
+				Attributes: []string{"template"},
+			},
+		},
+		{
+			path: "run_once_before_install.sh",
+			expected: commitMessageTarget{
+				TargetName: "install.sh",
+				Type:       "script",
+				Attributes: []string{"before", "once"},
+			},
+		},
+		{
+			path: "exact_private_dot_config/.keep",
+			expected: commitMessageTarget{
+				TargetName: ".config",
+				Type:       "dir",
+				Attributes: []string{"exact", "private"},
+			},
+		},
+		{
+			path: ".chezmoitemplates/foo",
+			expected: commitMessageTarget{
+				Attributes: []string{},
+			},
+		},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			assert.Equal(t, tc.expected, newCommitMessageTarget(tc.path))
+		})
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -30,17 +32,24 @@ import (
 	"github.com/twpayne/chezmoi/internal/git"
 )
 
-const commitMessageTemplateAsset = "assets/templates/COMMIT_MESSAGE.tmpl"
+const (
+	commitMessageTemplateAsset = "assets/templates/COMMIT_MESSAGE.tmpl"
+	commitMessageTemplateName  = ".chezmoicommitmessage.tmpl"
+	genericCommitMessage       = "Update chezmoi source state"
+)
 
 var whitespaceRegexp = regexp.MustCompile(`\s+`)
 
 type sourceVCSConfig struct {
-	Command    string
-	AutoCommit bool
-	AutoPush   bool
-	Init       interface{}
-	NotGit     bool
-	Pull       interface{}
+	Command               string
+	AutoCommit            bool
+	CommitMessageTemplate string
+	AutoPush              bool
+	Driver                *vcsDriverConfig
+	Init                  interface{}
+	NotGit                bool
+	Pull                  interface{}
+	UseBuiltinGit         string
 }
 
 type templateConfig struct {
@@ -49,58 +58,72 @@ type templateConfig struct {
 
 // A Config represents a configuration.
 type Config struct {
-	configFile        string
-	err               error
-	fs                vfs.FS
-	mutator           chezmoi.Mutator
-	SourceDir         string
-	DestDir           string
-	Umask             permValue
-	DryRun            bool
-	Follow            bool
-	Remove            bool
-	Verbose           bool
-	Color             string
-	Debug             bool
-	GPG               chezmoi.GPG
-	GPGRecipient      string
-	SourceVCS         sourceVCSConfig
-	Template          templateConfig
-	Merge             mergeConfig
-	Bitwarden         bitwardenCmdConfig
-	CD                cdCmdConfig
-	Diff              diffCmdConfig
-	GenericSecret     genericSecretCmdConfig
-	Gopass            gopassCmdConfig
-	KeePassXC         keePassXCCmdConfig
-	Lastpass          lastpassCmdConfig
-	Onepassword       onepasswordCmdConfig
-	Vault             vaultCmdConfig
-	Pass              passCmdConfig
-	Data              map[string]interface{}
-	colored           bool
-	maxDiffDataSize   int
-	templateFuncs     template.FuncMap
-	add               addCmdConfig
-	archive           archiveCmdConfig
-	completion        completionCmdConfig
-	data              dataCmdConfig
-	dump              dumpCmdConfig
-	edit              editCmdConfig
-	executeTemplate   executeTemplateCmdConfig
-	_import           importCmdConfig
-	init              initCmdConfig
-	keyring           keyringCmdConfig
-	managed           managedCmdConfig
-	purge             purgeCmdConfig
-	remove            removeCmdConfig
-	update            updateCmdConfig
-	upgrade           upgradeCmdConfig
-	Stdin             io.Reader
-	Stdout            io.Writer
-	Stderr            io.Writer
-	bds               *xdg.BaseDirectorySpecification
-	scriptStateBucket []byte
+	configFile         string
+	err                error
+	fs                 vfs.FS
+	mutator            chezmoi.Mutator
+	dryRunScript       func(string)
+	SourceDir          string
+	DestDir            string
+	CacheDir           string
+	Umask              permValue
+	DryRun             bool
+	Follow             bool
+	Remove             bool
+	Verbose            bool
+	Color              string
+	Debug              bool
+	Encryption         string
+	Age                chezmoi.AgeEncryption
+	ExternalEncryption chezmoi.ExternalEncryption
+	GPG                chezmoi.GPG
+	GPGRecipient       string
+	ModifiedPolicy     string
+	BlockName          string
+	PersistentState    string
+	Interpreters       map[string]*chezmoi.Interpreter
+	ScriptEnv          map[string]string
+	SourceVCS          sourceVCSConfig
+	Template           templateConfig
+	Merge              mergeConfig
+	Bitwarden          bitwardenCmdConfig
+	CD                 cdCmdConfig
+	Diff               diffCmdConfig
+	GenericSecret      genericSecretCmdConfig
+	Gopass             gopassCmdConfig
+	KeePassXC          keePassXCCmdConfig
+	Lastpass           lastpassCmdConfig
+	Onepassword        onepasswordCmdConfig
+	Vault              vaultCmdConfig
+	Pass               passCmdConfig
+	Data               map[string]interface{}
+	colored            bool
+	format             string
+	maxDiffDataSize    int
+	refreshExternals   bool
+	templateFuncs      template.FuncMap
+	add                addCmdConfig
+	archive            archiveCmdConfig
+	completion         completionCmdConfig
+	dump               dumpCmdConfig
+	edit               editCmdConfig
+	executeTemplate    executeTemplateCmdConfig
+	_import            importCmdConfig
+	init               initCmdConfig
+	keyring            keyringCmdConfig
+	managed            managedCmdConfig
+	purge              purgeCmdConfig
+	remove             removeCmdConfig
+	state              stateCmdConfig
+	update             updateCmdConfig
+	upgrade            upgradeCmdConfig
+	Stdin              io.Reader
+	Stdout             io.Writer
+	Stderr             io.Writer
+	bds                *xdg.BaseDirectorySpecification
+	entryStateBucket   []byte
+	scriptStateBucket  []byte
+	agePassphrase      *string
 
 	//nolint:structcheck,unused
 	ioregData ioregData
@@ -131,6 +154,18 @@ var (
 		"URL":  {},
 	}
 
+	modifiedPolicies = map[string]struct{}{
+		"overwrite": {},
+		"prompt":    {},
+		"skip":      {},
+		"warn":      {},
+	}
+
+	persistentStateFileExtensions = map[string]string{
+		"bolt": "boltdb",
+		"json": "json",
+	}
+
 	identifierRegexp = regexp.MustCompile(`\A[\pL_][\pL\p{Nd}_]*\z`)
 
 	assets = make(map[string][]byte)
@@ -139,10 +174,12 @@ var (
 // newConfig creates a new Config with the given options.
 func newConfig(options ...configOption) *Config {
 	c := &Config{
-		Umask: permValue(getUmask()),
-		Color: "auto",
+		Umask:      permValue(getUmask()),
+		Color:      "auto",
+		Encryption: "gpg",
 		SourceVCS: sourceVCSConfig{
-			Command: "git",
+			Command:       "git",
+			UseBuiltinGit: "auto",
 		},
 		Template: templateConfig{
 			Options: chezmoi.DefaultTemplateOptions,
@@ -156,8 +193,11 @@ func newConfig(options ...configOption) *Config {
 		GPG: chezmoi.GPG{
 			Command: "gpg",
 		},
+		ModifiedPolicy:    "warn",
+		PersistentState:   "bolt",
 		maxDiffDataSize:   1 * 1024 * 1024, // 1MB
 		templateFuncs:     sprig.TxtFuncMap(),
+		entryStateBucket:  []byte("entryState"),
 		scriptStateBucket: []byte("script"),
 		Stdin:             os.Stdin,
 		Stdout:            os.Stdout,
@@ -180,21 +220,18 @@ func (c *Config) addTemplateFunc(key string, value interface{}) {
 }
 
 func (c *Config) applyArgs(args []string, persistentState chezmoi.PersistentState) error {
-	fs := vfs.NewReadOnlyFS(c.fs)
 	ts, err := c.getTargetState(nil)
 	if err != nil {
 		return err
 	}
-	applyOptions := &chezmoi.ApplyOptions{
-		DestDir:           ts.DestDir,
-		DryRun:            c.DryRun,
-		Ignore:            ts.TargetIgnore.Match,
-		PersistentState:   persistentState,
-		Remove:            c.Remove,
-		ScriptStateBucket: c.scriptStateBucket,
-		Stdout:            c.Stdout,
-		Umask:             ts.Umask,
-		Verbose:           c.Verbose,
+	return c.applyTargetStateArgs(ts, args, persistentState)
+}
+
+func (c *Config) applyTargetStateArgs(ts *chezmoi.TargetState, args []string, persistentState chezmoi.PersistentState) error {
+	fs := vfs.NewReadOnlyFS(c.fs)
+	applyOptions, err := c.getApplyOptions(ts, persistentState)
+	if err != nil {
+		return err
 	}
 	if len(args) == 0 {
 		return ts.Apply(fs, c.mutator, c.Follow, applyOptions)
@@ -203,46 +240,67 @@ func (c *Config) applyArgs(args []string, persistentState chezmoi.PersistentStat
 	if err != nil {
 		return err
 	}
-	for _, entry := range entries {
-		if err := entry.Apply(fs, c.mutator, c.Follow, applyOptions); err != nil {
+	return ts.ApplyEntries(fs, c.mutator, c.Follow, applyOptions, entries)
+}
+
+func (c *Config) autoCommit(vcs VCS, command string) error {
+	if builtinVCS, ok := vcs.(builtinVCS); ok {
+		rawSourceDir, err := c.fs.RawPath(c.SourceDir)
+		if err != nil {
+			return err
+		}
+		if err := builtinVCS.Add(rawSourceDir, "."); err != nil {
+			return err
+		}
+	} else {
+		addArgs, err := vcs.AddArgs(".")
+		if err != nil {
+			return err
+		}
+		if addArgs == nil {
+			return fmt.Errorf("%s: autocommit not supported", c.SourceVCS.Command)
+		}
+		if err := c.run(c.SourceDir, c.SourceVCS.Command, addArgs...); err != nil {
 			return err
 		}
 	}
-	return nil
-}
 
-func (c *Config) autoCommit(vcs VCS) error {
-	addArgs := vcs.AddArgs(".")
-	if addArgs == nil {
-		return fmt.Errorf("%s: autocommit not supported", c.SourceVCS.Command)
-	}
-	if err := c.run(c.SourceDir, c.SourceVCS.Command, addArgs...); err != nil {
-		return err
-	}
-	output, err := c.output(c.SourceDir, c.SourceVCS.Command, vcs.StatusArgs()...)
-	if err != nil {
-		return err
-	}
-	status, err := vcs.ParseStatusOutput(output)
+	status, err := c.getSourceVCSStatus(vcs)
 	if err != nil {
 		return err
 	}
-	if gitStatus, ok := status.(*git.Status); ok && gitStatus.Empty() {
+
+	var message string
+	switch status := status.(type) {
+	case nil:
 		return nil
+	case string:
+		// The status output could not be parsed, so the commit message
+		// template cannot describe the changes.
+		message = genericCommitMessage
+	case *git.Status:
+		if status.Empty() {
+			return nil
+		}
+		message, err = c.commitMessage(command, status)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%s: unsupported status %T", c.SourceVCS.Command, status)
 	}
-	commitMessageText, err := getAsset(commitMessageTemplateAsset)
-	if err != nil {
-		return err
+
+	if builtinVCS, ok := vcs.(builtinVCS); ok {
+		rawSourceDir, err := c.fs.RawPath(c.SourceDir)
+		if err != nil {
+			return err
+		}
+		return builtinVCS.Commit(rawSourceDir, message)
 	}
-	commitMessageTmpl, err := template.New("commit_message").Funcs(c.templateFuncs).Parse(string(commitMessageText))
+	commitArgs, err := vcs.CommitArgs(message)
 	if err != nil {
 		return err
 	}
-	sb := &strings.Builder{}
-	if err := commitMessageTmpl.Execute(sb, status); err != nil {
-		return err
-	}
-	commitArgs := vcs.CommitArgs(sb.String())
 	return c.run(c.SourceDir, c.SourceVCS.Command, commitArgs...)
 }
 
@@ -255,7 +313,7 @@ func (c *Config) autoCommitAndAutoPush(cmd *cobra.Command, args []string) error
 		return nil
 	}
 	if c.SourceVCS.AutoCommit || c.SourceVCS.AutoPush {
-		if err := c.autoCommit(vcs); err != nil {
+		if err := c.autoCommit(vcs, cmd.Name()); err != nil {
 			return err
 		}
 	}
@@ -268,7 +326,17 @@ func (c *Config) autoCommitAndAutoPush(cmd *cobra.Command, args []string) error
 }
 
 func (c *Config) autoPush(vcs VCS) error {
-	pushArgs := vcs.PushArgs()
+	if builtinVCS, ok := vcs.(builtinVCS); ok {
+		rawSourceDir, err := c.fs.RawPath(c.SourceDir)
+		if err != nil {
+			return err
+		}
+		return builtinVCS.Push(rawSourceDir)
+	}
+	pushArgs, err := vcs.PushArgs()
+	if err != nil {
+		return err
+	}
 	if pushArgs == nil {
 		return fmt.Errorf("%s: autopush not supported", c.SourceVCS.Command)
 	}
@@ -309,6 +377,41 @@ func (c *Config) ensureSourceDirectory() error {
 	}
 }
 
+// getApplyOptions returns the options for applying ts, recording state in
+// persistentState.
+func (c *Config) getApplyOptions(ts *chezmoi.TargetState, persistentState chezmoi.PersistentState) (*chezmoi.ApplyOptions, error) {
+	if _, ok := modifiedPolicies[c.ModifiedPolicy]; !ok {
+		return nil, fmt.Errorf("%s: unknown modified policy", c.ModifiedPolicy)
+	}
+	scriptEnv, err := c.getScriptEnv()
+	if err != nil {
+		return nil, err
+	}
+	// Only apply the modified policy when targets are written, so that dry
+	// runs, diff, status, and verify always show the real differences.
+	var modified func(string) (bool, error)
+	if !c.DryRun {
+		modified = c.modified
+	}
+	return &chezmoi.ApplyOptions{
+		BlockName:         c.BlockName,
+		DestDir:           ts.DestDir,
+		DryRun:            c.DryRun,
+		DryRunScript:      c.dryRunScript,
+		EntryStateBucket:  c.entryStateBucket,
+		Ignore:            ts.TargetIgnore.Match,
+		Interpreters:      c.Interpreters,
+		Modified:          modified,
+		PersistentState:   persistentState,
+		Remove:            c.Remove,
+		ScriptEnv:         scriptEnv,
+		ScriptStateBucket: c.scriptStateBucket,
+		Stdout:            c.Stdout,
+		Umask:             ts.Umask,
+		Verbose:           c.Verbose,
+	}, nil
+}
+
 func (c *Config) getData() (map[string]interface{}, error) {
 	defaultData, err := c.getDefaultData()
 	if err != nil {
@@ -415,35 +518,176 @@ func (c *Config) getEntries(ts *chezmoi.TargetState, args []string) ([]chezmoi.E
 	return entries, nil
 }
 
+func (c *Config) getEncryption() (chezmoi.Encryption, error) {
+	switch c.Encryption {
+	case "age":
+		var err error
+		if c.Age.Identity, err = expandTilde(c.Age.Identity); err != nil {
+			return nil, err
+		}
+		for i, identity := range c.Age.Identities {
+			if c.Age.Identities[i], err = expandTilde(identity); err != nil {
+				return nil, err
+			}
+		}
+		if c.Age.PassphraseFunc == nil {
+			c.Age.PassphraseFunc = c.readAgePassphrase
+		}
+		return &c.Age, nil
+	case "external":
+		if c.ExternalEncryption.Command == "" {
+			return nil, errors.New("externalEncryption.command not set")
+		}
+		return &c.ExternalEncryption, nil
+	case "gpg":
+		// For backwards compatibility, prioritize gpgRecipient over
+		// gpg.recipient.
+		if c.GPGRecipient != "" {
+			c.GPG.Recipient = c.GPGRecipient
+		}
+		return &c.GPG, nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported encryption", c.Encryption)
+	}
+}
+
+// getDataFormat returns the function to write a value in the format given by
+// the --format flag, which defaults to JSON.
+func (c *Config) getDataFormat() (func(io.Writer, interface{}) error, error) {
+	if c.format == "" {
+		return formatMap["json"], nil
+	}
+	format, ok := formatMap[strings.ToLower(c.format)]
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown format", c.format)
+	}
+	return format, nil
+}
+
+// getFormat returns the function to write structured output in the format
+// given by the --format flag, or nil if output should be text.
+func (c *Config) getFormat() (func(io.Writer, interface{}) error, error) {
+	switch format := strings.ToLower(c.format); format {
+	case "":
+		return nil, nil
+	case "json", "yaml":
+		return formatMap[format], nil
+	default:
+		return nil, fmt.Errorf("%s: unknown format", c.format)
+	}
+}
+
 func (c *Config) getPersistentState(options *bolt.Options) (chezmoi.PersistentState, error) {
-	persistentStateFile := c.getPersistentStateFile()
-	if options == nil {
-		options = &bolt.Options{}
+	if !c.DryRun {
+		return c.openPersistentState(c.PersistentState, options)
 	}
-	if options.Timeout == 0 {
-		options.Timeout = 2 * time.Second
+
+	// In dry run mode, use an in-memory snapshot of the persistent state so
+	// that any changes are discarded.
+	var readOnlyOptions bolt.Options
+	if options != nil {
+		readOnlyOptions = *options
 	}
-	if c.DryRun {
-		options.ReadOnly = true
+	readOnlyOptions.ReadOnly = true
+	persistentState, err := c.openPersistentState(c.PersistentState, &readOnlyOptions)
+	if err != nil {
+		return nil, err
 	}
-	state, err := chezmoi.NewBoltPersistentState(c.fs, persistentStateFile, options)
-	if errors.Is(err, bolt.ErrTimeout) {
-		return nil, fmt.Errorf("failed to lock database: %w", err)
+	defer persistentState.Close()
+	snapshot := chezmoi.NewMemoryPersistentState()
+	if err := chezmoi.CopyPersistentState(snapshot, persistentState); err != nil {
+		return nil, err
 	}
-	return state, err
+	return snapshot, nil
 }
 
-func (c *Config) getPersistentStateFile() string {
+func (c *Config) getPersistentStateFile(persistentStateFormat string) string {
+	basename := "chezmoistate." + persistentStateFileExtensions[persistentStateFormat]
 	if c.configFile != "" {
-		return filepath.Join(filepath.Dir(c.configFile), "chezmoistate.boltdb")
+		return filepath.Join(filepath.Dir(c.configFile), basename)
 	}
 	for _, configDir := range c.bds.ConfigDirs {
-		persistentStateFile := filepath.Join(configDir, "chezmoi", "chezmoistate.boltdb")
+		persistentStateFile := filepath.Join(configDir, "chezmoi", basename)
 		if _, err := os.Stat(persistentStateFile); err == nil {
 			return persistentStateFile
 		}
 	}
-	return filepath.Join(filepath.Dir(getDefaultConfigFile(c.bds)), "chezmoistate.boltdb")
+	return filepath.Join(filepath.Dir(getDefaultConfigFile(c.bds)), basename)
+}
+
+// getScriptEnv returns the extra environment variables passed to scripts.
+func (c *Config) getScriptEnv() ([]string, error) {
+	defaultData, err := c.getDefaultData()
+	if err != nil {
+		return nil, err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	env := []string{
+		"CHEZMOI=1",
+		"CHEZMOI_ARCH=" + fmt.Sprint(defaultData["arch"]),
+		"CHEZMOI_CONFIG_FILE=" + c.configFile,
+		"CHEZMOI_DEST_DIR=" + c.DestDir,
+		"CHEZMOI_DRY_RUN=" + strconv.FormatBool(c.DryRun),
+		"CHEZMOI_EXECUTABLE=" + executable,
+		"CHEZMOI_OS=" + fmt.Sprint(defaultData["os"]),
+		"CHEZMOI_SOURCE_DIR=" + c.SourceDir,
+		"CHEZMOI_VERBOSE=" + strconv.FormatBool(c.Verbose),
+	}
+	// Config keys are case insensitive, so environment variable names from
+	// scriptEnv are converted to upper case.
+	keys := make([]string, 0, len(c.ScriptEnv))
+	for key := range c.ScriptEnv {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, strings.ToUpper(key)+"="+c.ScriptEnv[key])
+	}
+	return env, nil
+}
+
+// getSourceEntries returns the entries for args, like getEntries, for commands
+// that modify the source state of the entries. It returns an error if any
+// entry is part of an external, as the source state of an external is the
+// .chezmoiexternal file that declares it.
+func (c *Config) getSourceEntries(ts *chezmoi.TargetState, args []string) ([]chezmoi.Entry, error) {
+	entries, err := c.getEntries(ts, args)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		if ts.IsExternal(entry.TargetName()) {
+			return nil, fmt.Errorf("%s: part of an external declared in %s", args[i], filepath.Join(ts.SourceDir, entry.SourceName()))
+		}
+	}
+	return entries, nil
+}
+
+// getSourceVCSStatus returns the status of the source directory reported by
+// vcs. The status is a *git.Status if vcs can parse its status output.
+func (c *Config) getSourceVCSStatus(vcs VCS) (interface{}, error) {
+	if builtinVCS, ok := vcs.(builtinVCS); ok {
+		rawSourceDir, err := c.fs.RawPath(c.SourceDir)
+		if err != nil {
+			return nil, err
+		}
+		return builtinVCS.Status(rawSourceDir)
+	}
+	statusArgs, err := vcs.StatusArgs()
+	if err != nil {
+		return nil, err
+	}
+	if statusArgs == nil {
+		return nil, fmt.Errorf("%s: status not supported", c.SourceVCS.Command)
+	}
+	output, err := c.output(c.SourceDir, c.SourceVCS.Command, statusArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return vcs.ParseStatusOutput(output)
 }
 
 func (c *Config) getTargetState(populateOptions *chezmoi.PopulateOptions) (*chezmoi.TargetState, error) {
@@ -462,14 +706,15 @@ func (c *Config) getTargetState(populateOptions *chezmoi.PopulateOptions) (*chez
 		}
 	}
 
-	// For backwards compatibility, prioritize gpgRecipient over gpg.recipient.
-	if c.GPGRecipient != "" {
-		c.GPG.Recipient = c.GPGRecipient
+	encryption, err := c.getEncryption()
+	if err != nil {
+		return nil, err
 	}
 
 	ts := chezmoi.NewTargetState(
 		chezmoi.WithDestDir(destDir),
-		chezmoi.WithGPG(&c.GPG),
+		chezmoi.WithEncryption(encryption),
+		chezmoi.WithReadExternal(c.readExternal),
 		chezmoi.WithSourceDir(c.SourceDir),
 		chezmoi.WithTemplateData(data),
 		chezmoi.WithTemplateFuncs(c.templateFuncs),
@@ -486,13 +731,71 @@ func (c *Config) getTargetState(populateOptions *chezmoi.PopulateOptions) (*chez
 }
 
 func (c *Config) getVCS() (VCS, error) {
+	if c.SourceVCS.Driver != nil {
+		return newDriverVCS(c.SourceVCS.Driver)
+	}
 	vcs, ok := vcses[filepath.Base(c.SourceVCS.Command)]
 	if !ok {
 		return nil, fmt.Errorf("%s: unsupported source VCS command", c.SourceVCS.Command)
 	}
+	if _, ok := vcs.(gitVCS); ok {
+		useBuiltinGit, err := c.useBuiltinGit()
+		if err != nil {
+			return nil, err
+		}
+		if useBuiltinGit {
+			return builtinGitVCS{}, nil
+		}
+	}
 	return vcs, nil
 }
 
+// modified is called when the target targetName has been modified since
+// chezmoi last wrote it, and returns whether it should be overwritten according
+// to c.ModifiedPolicy.
+func (c *Config) modified(targetName string) (bool, error) {
+	targetPath := filepath.Join(c.DestDir, targetName)
+	switch c.ModifiedPolicy {
+	case "overwrite":
+		return true, nil
+	case "prompt":
+		choice, err := c.prompt(fmt.Sprintf("%s has been modified since chezmoi last wrote it, overwrite", targetPath), "yn")
+		if err != nil {
+			return false, err
+		}
+		return choice == 'y', nil
+	case "skip":
+		_, err := fmt.Fprintf(c.Stderr, "warning: %s: modified since chezmoi last wrote it, skipping\n", targetPath)
+		return false, err
+	default:
+		_, err := fmt.Fprintf(c.Stderr, "warning: %s: modified since chezmoi last wrote it, overwriting\n", targetPath)
+		return true, err
+	}
+}
+
+func (c *Config) openPersistentState(persistentStateFormat string, options *bolt.Options) (chezmoi.PersistentState, error) {
+	if options == nil {
+		options = &bolt.Options{}
+	}
+	if options.Timeout == 0 {
+		options.Timeout = 2 * time.Second
+	}
+	switch persistentStateFormat {
+	case "bolt":
+		state, err := chezmoi.NewBoltPersistentState(c.fs, c.getPersistentStateFile(persistentStateFormat), options)
+		if errors.Is(err, bolt.ErrTimeout) {
+			return nil, fmt.Errorf("failed to lock database: %w", err)
+		}
+		return state, err
+	case "json":
+		return chezmoi.NewJSONPersistentState(c.fs, c.getPersistentStateFile(persistentStateFormat), options.Timeout)
+	case "memory":
+		return chezmoi.NewMemoryPersistentState(), nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported persistent state", persistentStateFormat)
+	}
+}
+
 func (c *Config) output(dir, name string, argv ...string) ([]byte, error) {
 	cmd := exec.Command(name, argv...)
 	if dir != "" {
@@ -524,6 +827,21 @@ func (c *Config) prompt(s, choices string) (byte, error) {
 	}
 }
 
+// readAgePassphrase prompts for the age passphrase. The passphrase is only
+// read once per invocation.
+func (c *Config) readAgePassphrase() (string, error) {
+	if c.agePassphrase != nil {
+		return *c.agePassphrase, nil
+	}
+	passphrase, err := readPassword("Enter age passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	passphraseStr := string(passphrase)
+	c.agePassphrase = &passphraseStr
+	return passphraseStr, nil
+}
+
 // run runs name argv... in dir.
 func (c *Config) run(dir, name string, argv ...string) error {
 	cmd := exec.Command(name, argv...)
@@ -545,10 +863,52 @@ func (c *Config) runEditor(argv ...string) error {
 	return c.run("", editorName, append(editorArgs, argv...)...)
 }
 
+// useBuiltinGit returns whether to use the builtin git implementation instead
+// of running the source VCS command.
+func (c *Config) useBuiltinGit() (bool, error) {
+	if useBuiltinGit, err := strconv.ParseBool(c.SourceVCS.UseBuiltinGit); err == nil {
+		return useBuiltinGit, nil
+	}
+	switch c.SourceVCS.UseBuiltinGit {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	case "auto":
+		_, err := exec.LookPath(c.SourceVCS.Command)
+		return err != nil, nil
+	default:
+		return false, fmt.Errorf("invalid sourceVCS.useBuiltinGit value: %s", c.SourceVCS.UseBuiltinGit)
+	}
+}
+
 func (c *Config) validateData() error {
 	return validateKeys(config.Data, identifierRegexp)
 }
 
+// validateSourceVCS checks that the source VCS driver, if any, is valid, so
+// that errors in it are reported before any command modifies the source
+// directory.
+func (c *Config) validateSourceVCS() error {
+	if c.SourceVCS.Driver == nil {
+		return nil
+	}
+	_, err := newDriverVCS(c.SourceVCS.Driver)
+	return err
+}
+
+// expandTilde expands a leading ~ in path to the user's home directory.
+func expandTilde(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~")), nil
+}
+
 func getAsset(name string) ([]byte, error) {
 	asset, ok := assets[name]
 	if !ok {
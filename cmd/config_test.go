@@ -14,6 +14,7 @@ import (
 	xdg "github.com/twpayne/go-xdg/v3"
 
 	"github.com/twpayne/chezmoi/internal/chezmoi"
+	"github.com/twpayne/chezmoi/internal/git"
 )
 
 func TestAutoCommitCommitMessage(t *testing.T) {
@@ -57,7 +58,7 @@ func TestAutoCommitCommitMessage(t *testing.T) {
 			status, err := gitVCS{}.ParseStatusOutput([]byte(tc.statusStr))
 			require.NoError(t, err)
 			b := &bytes.Buffer{}
-			err = commitMessageTmpl.Execute(b, status)
+			err = commitMessageTmpl.Execute(b, newCommitMessageData("add", status.(*git.Status)))
 			if tc.wantErr {
 				require.Error(t, err)
 			} else {
@@ -1,15 +1,10 @@
 package cmd
 
 import (
-	"fmt"
-	"strings"
-
 	"github.com/spf13/cobra"
-)
 
-type dataCmdConfig struct {
-	format string
-}
+	"github.com/twpayne/chezmoi/internal/chezmoi"
+)
 
 var dataCmd = &cobra.Command{
 	Use:     "data",
@@ -25,17 +20,19 @@ func init() {
 	rootCmd.AddCommand(dataCmd)
 
 	persistentFlags := dataCmd.PersistentFlags()
-	persistentFlags.StringVarP(&config.data.format, "format", "f", "json", "format (JSON, TOML, or YAML)")
+	persistentFlags.StringVarP(&config.format, "format", "f", "", "format (JSON, TOML, or YAML, default JSON)")
 }
 
 func (c *Config) runDataCmd(cmd *cobra.Command, args []string) error {
-	format, ok := formatMap[strings.ToLower(c.data.format)]
-	if !ok {
-		return fmt.Errorf("%s: unknown format", c.data.format)
+	format, err := c.getDataFormat()
+	if err != nil {
+		return err
 	}
-	data, err := c.getData()
+	ts, err := c.getTargetState(&chezmoi.PopulateOptions{
+		ExecuteTemplates: false,
+	})
 	if err != nil {
 		return err
 	}
-	return format(c.Stdout, data)
+	return format(c.Stdout, ts.TemplateData)
 }
@@ -50,6 +50,10 @@ func (c *Config) runDiffCmd(cmd *cobra.Command, args []string) error {
 		c.mutator = chezmoi.NullMutator{}
 	case "git":
 		c.mutator = chezmoi.NewFSMutator(vfs.NewReadOnlyFS(c.fs))
+	case "json", "toml", "yaml":
+		// diff's --format flag selects the diff format, so structured output
+		// cannot be requested.
+		return fmt.Errorf("%s: diff does not support structured output, use --format=chezmoi or --format=git", c.Diff.Format)
 	default:
 		return fmt.Errorf("unknown diff format: %q", c.Diff.Format)
 	}
@@ -639,8 +639,13 @@ func init() {
 		"* [Ensure that a target is removed](#ensure-that-a-target-is-removed)\n" +
 		"* [Include a subdirectory from another repository, like Oh My Zsh](#include-a-subdirectory-from-another-repository-like-oh-my-zsh)\n" +
 		"* [Handle configuration files which are externally modified](#handle-configuration-files-which-are-externally-modified)\n" +
+		"* [Manage part, but not all, of a file](#manage-part-but-not-all-of-a-file)\n" +
+		"* [Manage a block of lines in a file](#manage-a-block-of-lines-in-a-file)\n" +
+		"* [Manage some keys of a structured file](#manage-some-keys-of-a-structured-file)\n" +
+		"* [Create a file only if it does not already exist](#create-a-file-only-if-it-does-not-already-exist)\n" +
 		"* [Handle different file locations on different systems with the same contents](#handle-different-file-locations-on-different-systems-with-the-same-contents)\n" +
 		"* [Keep data private](#keep-data-private)\n" +
+		"  * [Use age to keep your secrets](#use-age-to-keep-your-secrets)\n" +
 		"  * [Use Bitwarden to keep your secrets](#use-bitwarden-to-keep-your-secrets)\n" +
 		"  * [Use gopass to keep your secrets](#use-gopass-to-keep-your-secrets)\n" +
 		"  * [Use gpg to keep your secrets](#use-gpg-to-keep-your-secrets)\n" +
@@ -651,6 +656,7 @@ func init() {
 		"  * [Use pass to keep your secrets](#use-pass-to-keep-your-secrets)\n" +
 		"  * [Use Vault to keep your secrets](#use-vault-to-keep-your-secrets)\n" +
 		"  * [Use a generic tool to keep your secrets](#use-a-generic-tool-to-keep-your-secrets)\n" +
+		"  * [Use a generic tool to encrypt files](#use-a-generic-tool-to-encrypt-files)\n" +
 		"  * [Use templates variables to keep your secrets](#use-templates-variables-to-keep-your-secrets)\n" +
 		"* [Use scripts to perform actions](#use-scripts-to-perform-actions)\n" +
 		"  * [Understand how scripts work](#understand-how-scripts-work)\n" +
@@ -660,6 +666,7 @@ func init() {
 		"* [Run a PowerShell script as admin on Windows](#run-a-powershell-script-as-admin-on-windows)\n" +
 		"* [Import archives](#import-archives)\n" +
 		"* [Export archives](#export-archives)\n" +
+		"* [Use chezmoi without git installed](#use-chezmoi-without-git-installed)\n" +
 		"* [Use a non-git version control system](#use-a-non-git-version-control-system)\n" +
 		"* [Customize the `diff` command](#customize-the-diff-command)\n" +
 		"* [Use a merge tool other than vimdiff](#use-a-merge-tool-other-than-vimdiff)\n" +
@@ -703,6 +710,12 @@ func init() {
 		"\n" +
 		"This runs `git pull --rebase` in your source directory and then `chezmoi apply`.\n" +
 		"\n" +
+		"To avoid losing work, `chezmoi update` refuses to pull if your source directory\n" +
+		"has uncommitted changes or if you have local commits that have diverged from\n" +
+		"your repo. Run `chezmoi source status` to see what has changed, and `chezmoi\n" +
+		"doctor` will warn you if you have forgotten to push local commits. Pass\n" +
+		"`--force` to pull anyway.\n" +
+		"\n" +
 		"## Pull the latest changes from your repo and see what would change, without actually applying the changes\n" +
 		"\n" +
 		"Run:\n" +
@@ -736,6 +749,25 @@ func init() {
 		"changes. If you only set `autoCommit` to true then changes will be committed but\n" +
 		"not pushed.\n" +
 		"\n" +
+		"Automatic commits and pushes work with both git and Mercurial. For Mercurial,\n" +
+		"chezmoi runs `hg addremove`, so new files are added and deleted files are\n" +
+		"removed in each commit.\n" +
+		"\n" +
+		"To customize the commit message, create a `.chezmoicommitmessage.tmpl` file in\n" +
+		"your source directory, or set `sourceVCS.commitMessageTemplate` in your config\n" +
+		"file. The template can use the target path and attributes of each changed file,\n" +
+		"and the chezmoi command that made the change. For example, to write\n" +
+		"conventional commit messages that mention target paths:\n" +
+		"\n" +
+		"    chore({{ .Command }}): update dotfiles\n" +
+		"    {{ range .Ordinary }}\n" +
+		"    * {{ .TargetName | default .Path }}\n" +
+		"    {{- end }}\n" +
+		"\n" +
+		"See the [reference\n" +
+		"manual](https://github.com/twpayne/chezmoi/blob/master/docs/REFERENCE.md#chezmoicommitmessagetmpl)\n" +
+		"for the full list of fields.\n" +
+		"\n" +
 		"Be careful when using `autoPush`. If your dotfiles repo is public and you\n" +
 		"accidentally add a secret in plain text, that secret will be pushed to your\n" +
 		"public repo.\n" +
@@ -797,6 +829,20 @@ func init() {
 		"To disable automatic variable detection, use the `--template` or `-T` option to\n" +
 		"`chezmoi add` instead of `--autotemplate`.\n" +
 		"\n" +
+		"Variables that are the same on all machines, for example lists of packages or\n" +
+		"color schemes, can be defined in a `.chezmoidata.json`, `.chezmoidata.toml`, or\n" +
+		"`.chezmoidata.yaml` file in your source directory, so they are shared through\n" +
+		"your repo. For example, `~/.local/share/chezmoi/.chezmoidata.toml` might\n" +
+		"contain:\n" +
+		"\n" +
+		"    [colors]\n" +
+		"      background = \"black\"\n" +
+		"      foreground = \"white\"\n" +
+		"\n" +
+		"Variables in the `data` section of your config file override those in\n" +
+		"`.chezmoidata` files, so you can change them on individual machines. Run\n" +
+		"`chezmoi data` to see the resulting template data.\n" +
+		"\n" +
 		"Templates are often used to capture machine-specific differences. For example,\n" +
 		"in your `~/.local/share/chezmoi/dot_bashrc.tmpl` you might have:\n" +
 		"\n" +
@@ -993,8 +1039,25 @@ func init() {
 		"To include a subdirectory from another repository, e.g. [Oh My\n" +
 		"Zsh](https://github.com/robbyrussell/oh-my-zsh), you cannot use git submodules\n" +
 		"because chezmoi uses its own format for the source state and Oh My Zsh is not\n" +
-		"distributed in this format. Instead, you can use the `import` command to import\n" +
-		"a snapshot from a tarball:\n" +
+		"distributed in this format. Instead, you can declare it as an external in a\n" +
+		"`.chezmoiexternal.toml` file in your source directory:\n" +
+		"\n" +
+		"    [\".oh-my-zsh\"]\n" +
+		"        type = \"archive\"\n" +
+		"        url = \"https://github.com/robbyrussell/oh-my-zsh/archive/master.tar.gz\"\n" +
+		"        exact = true\n" +
+		"        stripComponents = 1\n" +
+		"        refreshPeriod = \"168h\"\n" +
+		"\n" +
+		"chezmoi downloads the archive, caches it for the refresh period, and then treats\n" +
+		"its contents like any other entries in the source state. `.chezmoiexternal`\n" +
+		"files can also declare single files and git repos, see the reference manual for\n" +
+		"details. To download all externals again, run:\n" +
+		"\n" +
+		"    chezmoi apply --refresh-externals\n" +
+		"\n" +
+		"Alternatively, you can use the `import` command to import a snapshot from a\n" +
+		"tarball:\n" +
 		"\n" +
 		"    curl -s -L -o oh-my-zsh-master.tar.gz https://github.com/robbyrussell/oh-my-zsh/archive/master.tar.gz\n" +
 		"    chezmoi import --strip-components 1 --destination ${HOME}/.oh-my-zsh oh-my-zsh-master.tar.gz\n" +
@@ -1040,6 +1103,78 @@ func init() {
 		"Now, when the program modifies its configuration file it will modify the file in\n" +
 		"the source state instead.\n" +
 		"\n" +
+		"Alternatively, if you only occasionally need to keep changes made to files in\n" +
+		"your home directory, you can pull them back into the source state with:\n" +
+		"\n" +
+		"    chezmoi re-add\n" +
+		"\n" +
+		"This updates the source state of every managed file that differs from its\n" +
+		"target contents, keeping its attributes and re-encrypting encrypted files.\n" +
+		"Templates are skipped, so you need to update them by hand.\n" +
+		"\n" +
+		"## Manage part, but not all, of a file\n" +
+		"\n" +
+		"Some programs store both configuration and state in the same file, so chezmoi\n" +
+		"can only manage part of it. For these files, create a script with the `modify_`\n" +
+		"prefix in your source directory. chezmoi runs the script with the current\n" +
+		"contents of the file on its stdin and writes the script's stdout to the file.\n" +
+		"\n" +
+		"For example, to manage only the `color` setting in `~/.settings`, create\n" +
+		"`~/.local/share/chezmoi/modify_dot_settings` containing:\n" +
+		"\n" +
+		"    #!/bin/sh\n" +
+		"\n" +
+		"    sed 's/^color = .*/color = red/'\n" +
+		"\n" +
+		"Modify scripts are run by `chezmoi cat`, `chezmoi diff`, and `chezmoi verify`\n" +
+		"too, so they must not have any side effects.\n" +
+		"\n" +
+		"## Manage a block of lines in a file\n" +
+		"\n" +
+		"Files like `~/.profile` or `~/.ssh/authorized_keys` are often also written to by\n" +
+		"other programs. To manage only a block of lines in such a file, create a file\n" +
+		"with the `block_` prefix in your source directory, for example\n" +
+		"`~/.local/share/chezmoi/block_dot_profile` containing:\n" +
+		"\n" +
+		"    export EDITOR=vi\n" +
+		"\n" +
+		"`chezmoi apply` then ensures that `~/.profile` contains:\n" +
+		"\n" +
+		"    # BEGIN chezmoi .profile\n" +
+		"    export EDITOR=vi\n" +
+		"    # END chezmoi\n" +
+		"\n" +
+		"leaving the rest of the file untouched. If you manage the same file from more\n" +
+		"than one source directory, set a different `blockName` in each config file so\n" +
+		"that the blocks do not overwrite each other.\n" +
+		"\n" +
+		"## Manage some keys of a structured file\n" +
+		"\n" +
+		"If a file is in JSON, TOML, YAML, or INI format, you can manage only some of its\n" +
+		"keys by creating a fragment with the `merge_` prefix in your source directory.\n" +
+		"chezmoi deep-merges the fragment into the current contents of the file.\n" +
+		"\n" +
+		"For example, to manage only the font size in VS Code's `settings.json`, create\n" +
+		"`~/.local/share/chezmoi/dot_config/Code/User/merge_settings.json` containing:\n" +
+		"\n" +
+		"    {\n" +
+		"      \"editor.fontSize\": 14\n" +
+		"    }\n" +
+		"\n" +
+		"`chezmoi diff` shows the result of the merge, and `chezmoi verify` succeeds as\n" +
+		"long as the file contains the keys from the fragment.\n" +
+		"\n" +
+		"## Create a file only if it does not already exist\n" +
+		"\n" +
+		"Some files only need initial contents, after which they are owned by you or by\n" +
+		"another program. Add them with the `--create` option:\n" +
+		"\n" +
+		"    chezmoi add --create ~/.gitconfig.local\n" +
+		"\n" +
+		"This creates `create_dot_gitconfig.local` in your source directory. `chezmoi\n" +
+		"apply` writes `~/.gitconfig.local` only if it does not already exist, and\n" +
+		"never overwrites it afterwards.\n" +
+		"\n" +
 		"## Handle different file locations on different systems with the same contents\n" +
 		"\n" +
 		"If you want to have the same file contents in different locations on different\n" +
@@ -1088,6 +1223,36 @@ func init() {
 		"There are several ways to keep these tokens secure, and to prevent them leaving\n" +
 		"your machine.\n" +
 		"\n" +
+		"### Use age to keep your secrets\n" +
+		"\n" +
+		"chezmoi includes built-in support for encrypting files with\n" +
+		"[age](https://age-encryption.org). Unlike gpg, age encryption and decryption\n" +
+		"happen inside chezmoi, so the `age` binary does not need to be installed and\n" +
+		"plaintexts are never written to temporary files.\n" +
+		"\n" +
+		"To use age, set `encryption` to `age` in your configuration file and specify\n" +
+		"your identity file, for example:\n" +
+		"\n" +
+		"    encryption = \"age\"\n" +
+		"    [age]\n" +
+		"      identity = \"~/.config/chezmoi/key.txt\"\n" +
+		"\n" +
+		"You can generate an identity file with `age-keygen`. Files are encrypted for\n" +
+		"the recipients given by `age.recipient` and `age.recipients`. If neither are\n" +
+		"set then files are encrypted for the public keys of your identities.\n" +
+		"\n" +
+		"Add files to be encrypted with the `--encrypt` flag, for example:\n" +
+		"\n" +
+		"    chezmoi add --encrypt ~/.ssh/id_rsa\n" +
+		"\n" +
+		"Alternatively, to encrypt files with a passphrase, set `age.passphrase`:\n" +
+		"\n" +
+		"    encryption = \"age\"\n" +
+		"    [age]\n" +
+		"      passphrase = true\n" +
+		"\n" +
+		"chezmoi will prompt for the passphrase once each time it is run.\n" +
+		"\n" +
 		"### Use Bitwarden to keep your secrets\n" +
 		"\n" +
 		"chezmoi includes support for [Bitwarden](https://bitwarden.com/) using the\n" +
@@ -1336,6 +1501,27 @@ func init() {
 		"| KeePassXC       | `keepassxc-cli`         | Not possible (interactive command only)           |\n" +
 		"| pass            | `pass`                  | `{{ secret \"show\" <id> }}`                        |\n" +
 		"\n" +
+		"### Use a generic tool to encrypt files\n" +
+		"\n" +
+		"If your encryption tool is not directly supported by chezmoi, you can configure\n" +
+		"an arbitrary command to encrypt and decrypt files by setting `encryption` to\n" +
+		"`external` and setting the `externalEncryption` section in your config file.\n" +
+		"`args` are passed to both encryption and decryption, followed by `encryptArgs`\n" +
+		"or `decryptArgs` respectively.\n" +
+		"\n" +
+		"Each argument is a template. `{{ .Input }}` is replaced by the name of a file\n" +
+		"containing the input and `{{ .Output }}` by the name of the file that the\n" +
+		"command should write. If no argument contains `{{ .Input }}` then the input is\n" +
+		"passed on the standard input, and if no argument contains `{{ .Output }}` then\n" +
+		"the output is read from the standard output. For example, to use\n" +
+		"[sops](https://github.com/mozilla/sops):\n" +
+		"\n" +
+		"    encryption = \"external\"\n" +
+		"    [externalEncryption]\n" +
+		"      command = \"sops\"\n" +
+		"      decryptArgs = [\"--decrypt\", \"{{ .Input }}\"]\n" +
+		"      encryptArgs = [\"--encrypt\", \"{{ .Input }}\"]\n" +
+		"\n" +
 		"### Use templates variables to keep your secrets\n" +
 		"\n" +
 		"Typically, `~/.config/chezmoi/chezmoi.toml` is not checked in to version control\n" +
@@ -1370,8 +1556,37 @@ func init() {
 		"dry-run mode, the script is not executed.\n" +
 		"\n" +
 		"Scripts are any file in the source directory with the prefix `run_`, and are\n" +
-		"executed in alphabetical order. Scripts that should only be run when their\n" +
-		"contents change have the prefix `run_once_`.\n" +
+		"executed in alphabetical order. Scripts that should only be run once, the first\n" +
+		"time that they are seen with their current contents, have the prefix\n" +
+		"`run_once_`. Scripts that should be run whenever their contents change since\n" +
+		"they were last run have the prefix `run_onchange_`.\n" +
+		"\n" +
+		"Scripts are normally run in alphabetical order alongside the files and\n" +
+		"directories in the source state. Scripts with the prefix `run_before_` are run\n" +
+		"before any files or directories are updated, and scripts with the prefix\n" +
+		"`run_after_` are run after all files and directories have been updated. Each\n" +
+		"group is run in alphabetical order of target name, including scripts in\n" +
+		"subdirectories. When you apply specific targets, for example with `chezmoi apply\n" +
+		"~/.config`, only the `run_before_` and `run_after_` scripts within those targets\n" +
+		"are run. For example, `run_before_install-packages.sh` can install the\n" +
+		"packages that your dotfiles depend on, and `run_after_reload.sh` can reload\n" +
+		"programs once their configuration is in place. `before_` and `after_` can be\n" +
+		"combined with `once_`, e.g. `run_once_before_install-packages.sh`.\n" +
+		"\n" +
+		"chezmoi records which `run_once_` and `run_onchange_` scripts have been run in\n" +
+		"the `script` bucket of its persistent state. To see this, run:\n" +
+		"\n" +
+		"    chezmoi state dump --bucket=script\n" +
+		"\n" +
+		"To make chezmoi run a `run_onchange_` script again, delete its state, for\n" +
+		"example:\n" +
+		"\n" +
+		"    chezmoi state delete --bucket=script --key=install-packages.sh\n" +
+		"\n" +
+		"The keys of `run_once_` scripts include the SHA256 sum of their contents, so to\n" +
+		"run all `run_once_` scripts again, run:\n" +
+		"\n" +
+		"    chezmoi state reset --bucket=script\n" +
 		"\n" +
 		"Scripts break chezmoi's declarative approach, and as such should be used\n" +
 		"sparingly. Any script should be idempotent, even `run_once_` scripts.\n" +
@@ -1381,11 +1596,70 @@ func init() {
 		"directly using `exec` and must include a shebang line or be executable binaries.\n" +
 		"There is no need to set the executable bit on the script.\n" +
 		"\n" +
+		"Alternatively, scripts can be run with an interpreter chosen by their file\n" +
+		"extension, which does not need a shebang line and also works when the temporary\n" +
+		"directory is mounted `noexec`. Configure interpreters in the `interpreters`\n" +
+		"section of your config file, keyed by extension without the leading dot, for\n" +
+		"example:\n" +
+		"\n" +
+		"    [interpreters.py]\n" +
+		"        command = \"python3\"\n" +
+		"    [interpreters.ps1]\n" +
+		"        command = \"powershell\"\n" +
+		"        args = [\"-NoLogo\", \"-File\"]\n" +
+		"\n" +
+		"The script is passed as the last argument to the interpreter.\n" +
+		"\n" +
+		"Scripts are run in the directory that contains their target. chezmoi creates\n" +
+		"this directory before running `run_before_` scripts in it, so they do not need\n" +
+		"to create it themselves. If the directory does not exist and is not being\n" +
+		"applied, for example when only the script itself is given as a target, the\n" +
+		"script is run in its closest existing parent directory. In addition to the environment that chezmoi\n" +
+		"was run with, scripts have the following environment variables set:\n" +
+		"\n" +
+		"| Variable              | Value                                            |\n" +
+		"| --------------------- | ------------------------------------------------ |\n" +
+		"| `CHEZMOI`             | `1`                                              |\n" +
+		"| `CHEZMOI_ARCH`        | The architecture, as in `.chezmoi.arch`          |\n" +
+		"| `CHEZMOI_CONFIG_FILE` | The path to the config file                      |\n" +
+		"| `CHEZMOI_DEST_DIR`    | The destination directory                        |\n" +
+		"| `CHEZMOI_DRY_RUN`     | `true` if running in dry-run mode, else `false`  |\n" +
+		"| `CHEZMOI_EXECUTABLE`  | The path to the `chezmoi` executable             |\n" +
+		"| `CHEZMOI_OS`          | The operating system, as in `.chezmoi.os`        |\n" +
+		"| `CHEZMOI_SOURCE_DIR`  | The source directory                             |\n" +
+		"| `CHEZMOI_VERBOSE`     | `true` if running in verbose mode, else `false`  |\n" +
+		"\n" +
+		"Extra environment variables can be set with the `scriptEnv` section of your\n" +
+		"config file. Config keys are case insensitive, so the variable names are\n" +
+		"converted to upper case, for example:\n" +
+		"\n" +
+		"    [scriptEnv]\n" +
+		"        github_token = \"xxx\"\n" +
+		"\n" +
+		"sets `GITHUB_TOKEN=xxx`.\n" +
+		"\n" +
 		"Scripts with the suffix `.tmpl` are treated as templates, with the usual\n" +
 		"template variables available. If, after executing the template, the result is\n" +
 		"only whitespace or an empty string, then the script is not executed. This is\n" +
 		"useful for disabling scripts.\n" +
 		"\n" +
+		"Scripts that contain secrets can be encrypted with the `encrypted_` prefix,\n" +
+		"e.g. `run_encrypted_once_register.sh`, using your configured encryption tool.\n" +
+		"Encrypted scripts are decrypted before they are executed. `chezmoi dump` only\n" +
+		"includes their decrypted contents when they are explicitly given as targets, and\n" +
+		"`chezmoi cat` prints their decrypted contents. They are not printed by\n" +
+		"`--verbose` and are not included by `chezmoi archive`.\n" +
+		"\n" +
+		"`run_onchange_` scripts are particularly useful as templates: including a hash\n" +
+		"of another file in the script's contents causes the script to be re-run\n" +
+		"whenever that file changes. For example, to re-install packages whenever your\n" +
+		"`Brewfile` changes, create `run_onchange_install-packages.sh.tmpl` containing:\n" +
+		"\n" +
+		"    #!/bin/sh\n" +
+		"\n" +
+		"    # Brewfile hash: {{ include \"dot_Brewfile\" | sha256sum }}\n" +
+		"    brew bundle --global\n" +
+		"\n" +
 		"### Install packages with scripts\n" +
 		"\n" +
 		"Change to the source directory and create a file called\n" +
@@ -1583,6 +1857,22 @@ func init() {
 		"\n" +
 		"which lists all the targets in the target state.\n" +
 		"\n" +
+		"## Use chezmoi without git installed\n" +
+		"\n" +
+		"chezmoi includes a builtin implementation of git, so `chezmoi init`, `chezmoi\n" +
+		"update`, and automatically committing and pushing changes work on machines\n" +
+		"that do not have git installed, for example minimal containers. By default,\n" +
+		"chezmoi uses the builtin git only if it cannot find the `git` command. To always\n" +
+		"use it, specify:\n" +
+		"\n" +
+		"    [sourceVCS]\n" +
+		"      useBuiltinGit = true\n" +
+		"\n" +
+		"The builtin git clones submodules and only pulls changes that can be\n" +
+		"fast-forwarded. If you set `sourceVCS.init` or `sourceVCS.pull`, or run commands\n" +
+		"that run git directly, like `chezmoi git` and `chezmoi source`, then chezmoi\n" +
+		"still needs the `git` command.\n" +
+		"\n" +
 		"## Use a non-git version control system\n" +
 		"\n" +
 		"By default, chezmoi uses git, but you can use any version control system of your\n" +
@@ -1597,6 +1887,38 @@ func init() {
 		"you'd like to see your VCS better supported, please [open an issue on\n" +
 		"GitHub](https://github.com/twpayne/chezmoi/issues/new/choose).\n" +
 		"\n" +
+		"Alternatively, you can describe how chezmoi should drive your VCS in the\n" +
+		"`sourceVCS.driver` section of your config file. Each operation is a list of\n" +
+		"arguments to `sourceVCS.command`, and each argument is a template. The `clone`\n" +
+		"arguments can use `.Repo` and `.Dir`, the `add` arguments can use `.Path`, and\n" +
+		"the `commit` arguments can use `.Message`. Operations that are not defined are\n" +
+		"not supported. For example, to use [Fossil](https://fossil-scm.org/) specify:\n" +
+		"\n" +
+		"    [sourceVCS]\n" +
+		"      autoCommit = true\n" +
+		"      command = \"fossil\"\n" +
+		"      [sourceVCS.driver]\n" +
+		"        add = [\"add\", \"{{ .Path }}\"]\n" +
+		"        clone = [\"clone\", \"--workdir\", \"{{ .Dir }}\", \"{{ .Repo }}\"]\n" +
+		"        commit = [\"commit\", \"--comment\", \"{{ .Message }}\"]\n" +
+		"        dir = \".fslckout\"\n" +
+		"        init = [\"init\", \"--workdir\", \".\"]\n" +
+		"        pull = [\"update\"]\n" +
+		"        push = [\"push\"]\n" +
+		"        status = [\"changes\"]\n" +
+		"        version = [\"version\"]\n" +
+		"        versionRegexp = 'version\\s+(\\d+\\.\\d+)'\n" +
+		"\n" +
+		"`dir` is the file or directory that marks the source directory as a checkout. If\n" +
+		"it is not set, chezmoi considers any non-empty source directory to be a\n" +
+		"checkout. If `statusFormat` is `git-porcelain-v2` then chezmoi parses the\n" +
+		"output of `status` as `git status --porcelain=v2` output and autocommit\n" +
+		"messages describe the changes. Otherwise, chezmoi only commits if `status`\n" +
+		"prints anything, using a generic commit message.\n" +
+		"\n" +
+		"chezmoi checks the driver when it reads your config file, so invalid templates\n" +
+		"are reported before any command modifies your source directory.\n" +
+		"\n" +
 		"## Customize the `diff` command\n" +
 		"\n" +
 		"By default, chezmoi uses a built-in diff. You can change the format, and/or pipe\n" +
@@ -1861,6 +2183,9 @@ func init() {
 		"* [Source state attributes](#source-state-attributes)\n" +
 		"* [Special files and directories](#special-files-and-directories)\n" +
 		"  * [`.chezmoi.<format>.tmpl`](#chezmoiformattmpl)\n" +
+		"  * [`.chezmoicommitmessage.tmpl`](#chezmoicommitmessagetmpl)\n" +
+		"  * [`.chezmoidata.<format>`](#chezmoidataformat)\n" +
+		"  * [`.chezmoiexternal.<format>`](#chezmoiexternalformat)\n" +
 		"  * [`.chezmoiignore`](#chezmoiignore)\n" +
 		"  * [`.chezmoiremove`](#chezmoiremove)\n" +
 		"  * [`.chezmoitemplates`](#chezmoitemplates)\n" +
@@ -1891,11 +2216,14 @@ func init() {
 		"  * [`managed`](#managed)\n" +
 		"  * [`merge` *targets*](#merge-targets)\n" +
 		"  * [`purge`](#purge)\n" +
+		"  * [`re-add` [*targets*]](#re-add-targets)\n" +
 		"  * [`remove` *targets*](#remove-targets)\n" +
 		"  * [`rm` *targets*](#rm-targets)\n" +
 		"  * [`secret`](#secret)\n" +
 		"  * [`source` [*args*]](#source-args)\n" +
 		"  * [`source-path` [*targets*]](#source-path-targets)\n" +
+		"  * [`state`](#state)\n" +
+		"  * [`status` [*targets*]](#status-targets)\n" +
 		"  * [`unmanage` *targets*](#unmanage-targets)\n" +
 		"  * [`unmanaged`](#unmanaged)\n" +
 		"  * [`update`](#update)\n" +
@@ -1955,6 +2283,10 @@ func init() {
 		"\n" +
 		"Command line flags override any values set in the configuration file.\n" +
 		"\n" +
+		"### `--cache` *directory*\n" +
+		"\n" +
+		"Use *directory* as the cache directory.\n" +
+		"\n" +
 		"### `--color` *value*\n" +
 		"\n" +
 		"Colorize diffs, *value* can be `on`, `off`, `auto`, or any boolean-like value\n" +
@@ -1980,6 +2312,21 @@ func init() {
 		"If the last part of a target is a symlink, deal with what the symlink\n" +
 		"references, rather than the symlink itself.\n" +
 		"\n" +
+		"### `--format` *format*\n" +
+		"\n" +
+		"Print the output of the `doctor`, `managed`, `source status`, `status`,\n" +
+		"`unmanaged`, and `verify` commands as structured records in *format*, which can\n" +
+		"be `json` or `yaml`. Each record of `managed`, `unmanaged`, and `verify`\n" +
+		"contains the target path (`targetPath`), the source path (`sourcePath`, managed\n" +
+		"entries only), the type of entry (`type`), and its attributes (`attributes`).\n" +
+		"The `data`, `dump`, and `state dump` commands use the same flag to format their\n" +
+		"output and default to `json`. `data` and `state dump` also accept `toml`. The\n" +
+		"`data`, `dump`, `state dump`, and `status` commands also accept `-f` as a\n" +
+		"shorthand.\n" +
+		"\n" +
+		"The `diff` command is the exception: its `--format` flag selects the diff\n" +
+		"format, and structured output formats are rejected.\n" +
+		"\n" +
 		"### `-n`, `--dry-run`\n" +
 		"\n" +
 		"Set dry run mode. In dry run mode, the destination directory is never modified.\n" +
@@ -1994,6 +2341,10 @@ func init() {
 		"\n" +
 		"Also remove targets according to `.chezmoiremove`.\n" +
 		"\n" +
+		"### `-R`, `--refresh-externals`\n" +
+		"\n" +
+		"Download the contents of all externals again, ignoring the cache.\n" +
+		"\n" +
 		"### `-S`, `--source` *directory*\n" +
 		"\n" +
 		"Use *directory* as the source directory.\n" +
@@ -2024,40 +2375,70 @@ func init() {
 		"\n" +
 		"The following configuration variables are available:\n" +
 		"\n" +
-		"| Section         | Variable     | Type     | Default value             | Description                                         |\n" +
-		"| --------------- | ------------ | -------- | ------------------------- | --------------------------------------------------- |\n" +
-		"| Top level       | `color`      | string   | `auto`                    | Colorize diffs                                      |\n" +
-		"|                 | `data`       | any      | *none*                    | Template data                                       |\n" +
-		"|                 | `destDir`    | string   | `~`                       | Destination directory                               |\n" +
-		"|                 | `dryRun`     | bool     | `false`                   | Dry run mode                                        |\n" +
-		"|                 | `follow`     | bool     | `false`                   | Follow symlinks                                     |\n" +
-		"|                 | `remove`     | bool     | `false`                   | Remove targets                                      |\n" +
-		"|                 | `sourceDir`  | string   | `~/.local/share/chezmoi`  | Source directory                                    |\n" +
-		"|                 | `umask`      | int      | *from system*             | Umask                                               |\n" +
-		"|                 | `verbose`    | bool     | `false`                   | Verbose mode                                        |\n" +
-		"| `bitwarden`     | `command`    | string   | `bw`                      | Bitwarden CLI command                               |\n" +
-		"| `cd`            | `args`       | []string | *none*                    | Extra args to shell in `cd` command                 |\n" +
-		"|                 | `command`    | string   | *none*                    | Shell to run in `cd` command                        |\n" +
-		"| `diff`          | `format`     | string   | `chezmoi`                 | Diff format, either `chezmoi` or `git`              |\n" +
-		"|                 | `pager`      | string   | *none*                    | Pager                                               |\n" +
-		"| `genericSecret` | `command`    | string   | *none*                    | Generic secret command                              |\n" +
-		"| `gopass`        | `command`    | string   | `gopass`                  | gopass CLI command                                  |\n" +
-		"| `gpg`           | `command`    | string   | `gpg`                     | GPG CLI command                                     |\n" +
-		"|                 | `recipient`  | string   | *none*                    | GPG recipient                                       |\n" +
-		"|                 | `symmetric`  | bool     | `false`                   | Use symmetric GPG encryption                        |\n" +
-		"| `keepassxc`     | `args`       | []string | *none*                    | Extra args to KeePassXC CLI command                 |\n" +
-		"|                 | `command`    | string   | `keepassxc-cli`           | KeePassXC CLI command                               |\n" +
-		"|                 | `database`   | string   | *none*                    | KeePassXC database                                  |\n" +
-		"| `lastpass`      | `command`    | string   | `lpass`                   | Lastpass CLI command                                |\n" +
-		"| `merge`         | `args`       | []string | *none*                    | Extra args to 3-way merge command                   |\n" +
-		"|                 | `command`    | string   | `vimdiff`                 | 3-way merge command                                 |\n" +
-		"| `onepassword`   | `command`    | string   | `op`                      | 1Password CLI command                               |\n" +
-		"| `pass`          | `command`    | string   | `pass`                    | Pass CLI command                                    |\n" +
-		"| `sourceVCS`     | `autoCommit` | bool     | `false`                   | Commit changes to the source state after any change |\n" +
-		"|                 | `autoPush`   | bool     | `false`                   | Push changes to the source state after any change   |\n" +
-		"|                 | `command`    | string   | `git`                     | Source version control system                       |\n" +
-		"| `template`      | `options`    | []string | `[\"missingkey=error\"]`    | Template options                                    |\n" +
-		"| `vault`         | `command`    | string   | `vault`                   | Vault CLI command                                   |\n" +
+		"| Section              | Variable                | Type     | Default value            | Description                                         |\n" +
+		"| -------------------- | ----------------------- | -------- | ------------------------ | --------------------------------------------------- |\n" +
+		"| Top level            | `blockName`             | string   | *target name*            | Name of blocks managed by `block_` files            |\n" +
+		"|                      | `cacheDir`              | string   | `~/.cache/chezmoi`       | Cache directory                                     |\n" +
+		"|                      | `color`                 | string   | `auto`                   | Colorize diffs                                      |\n" +
+		"|                      | `data`                  | any      | *none*                   | Template data                                       |\n" +
+		"|                      | `destDir`               | string   | `~`                      | Destination directory                               |\n" +
+		"|                      | `dryRun`                | bool     | `false`                  | Dry run mode                                        |\n" +
+		"|                      | `encryption`            | string   | `gpg`                    | Encryption, `age`, `external`, or `gpg`             |\n" +
+		"|                      | `follow`                | bool     | `false`                  | Follow symlinks                                     |\n" +
+		"|                      | `modifiedPolicy`        | string   | `warn`                   | Policy for modified targets, see `apply`            |\n" +
+		"|                      | `persistentState`       | string   | `bolt`                   | Persistent state, `bolt`, `json`, or `memory`       |\n" +
+		"|                      | `remove`                | bool     | `false`                  | Remove targets                                      |\n" +
+		"|                      | `scriptEnv`             | object   | *none*                   | Extra environment variables for scripts             |\n" +
+		"|                      | `sourceDir`             | string   | `~/.local/share/chezmoi` | Source directory                                    |\n" +
+		"|                      | `umask`                 | int      | *from system*            | Umask                                               |\n" +
+		"|                      | `verbose`               | bool     | `false`                  | Verbose mode                                        |\n" +
+		"| `age`                | `identities`            | []string | *none*                   | Extra age identity files                            |\n" +
+		"|                      | `identity`              | string   | *none*                   | age identity file                                   |\n" +
+		"|                      | `passphrase`            | bool     | `false`                  | Use age passphrase encryption                       |\n" +
+		"|                      | `recipient`             | string   | *none*                   | age recipient                                       |\n" +
+		"|                      | `recipients`            | []string | *none*                   | Extra age recipients                                |\n" +
+		"| `bitwarden`          | `command`               | string   | `bw`                     | Bitwarden CLI command                               |\n" +
+		"| `cd`                 | `args`                  | []string | *none*                   | Extra args to shell in `cd` command                 |\n" +
+		"|                      | `command`               | string   | *none*                   | Shell to run in `cd` command                        |\n" +
+		"| `diff`               | `format`                | string   | `chezmoi`                | Diff format, either `chezmoi` or `git`              |\n" +
+		"|                      | `pager`                 | string   | *none*                   | Pager                                               |\n" +
+		"| `externalEncryption` | `args`                  | []string | *none*                   | Extra args to external encryption command           |\n" +
+		"|                      | `command`               | string   | *none*                   | External encryption command                         |\n" +
+		"|                      | `decryptArgs`           | []string | *none*                   | Args to decrypt with external command               |\n" +
+		"|                      | `encryptArgs`           | []string | *none*                   | Args to encrypt with external command               |\n" +
+		"| `genericSecret`      | `command`               | string   | *none*                   | Generic secret command                              |\n" +
+		"| `gopass`             | `command`               | string   | `gopass`                 | gopass CLI command                                  |\n" +
+		"| `gpg`                | `command`               | string   | `gpg`                    | GPG CLI command                                     |\n" +
+		"|                      | `recipient`             | string   | *none*                   | GPG recipient                                       |\n" +
+		"|                      | `symmetric`             | bool     | `false`                  | Use symmetric GPG encryption                        |\n" +
+		"| `interpreters.`*ext* | `args`                  | []string | *none*                   | Extra args to interpreter for *ext* scripts         |\n" +
+		"|                      | `command`               | string   | *none*                   | Interpreter for scripts with extension *ext*        |\n" +
+		"| `keepassxc`          | `args`                  | []string | *none*                   | Extra args to KeePassXC CLI command                 |\n" +
+		"|                      | `command`               | string   | `keepassxc-cli`          | KeePassXC CLI command                               |\n" +
+		"|                      | `database`              | string   | *none*                   | KeePassXC database                                  |\n" +
+		"| `lastpass`           | `command`               | string   | `lpass`                  | Lastpass CLI command                                |\n" +
+		"| `merge`              | `args`                  | []string | *none*                   | Extra args to 3-way merge command                   |\n" +
+		"|                      | `command`               | string   | `vimdiff`                | 3-way merge command                                 |\n" +
+		"| `onepassword`        | `command`               | string   | `op`                     | 1Password CLI command                               |\n" +
+		"| `pass`               | `command`               | string   | `pass`                   | Pass CLI command                                    |\n" +
+		"| `sourceVCS`          | `autoCommit`            | bool     | `false`                  | Commit changes to the source state after any change |\n" +
+		"|                      | `autoPush`              | bool     | `false`                  | Push changes to the source state after any change   |\n" +
+		"|                      | `commitMessageTemplate` | string   | *none*                   | Commit message template                             |\n" +
+		"|                      | `command`               | string   | `git`                    | Source version control system                       |\n" +
+		"|                      | `useBuiltinGit`         | string   | `auto`                   | Use builtin git instead of `command`                |\n" +
+		"| `sourceVCS.driver`   | `add`                   | []string | *none*                   | Args to add a path to the source VCS                |\n" +
+		"|                      | `clone`                 | []string | *none*                   | Args to clone a repo into the source directory      |\n" +
+		"|                      | `commit`                | []string | *none*                   | Args to commit changes with a message               |\n" +
+		"|                      | `dir`                   | string   | *none*                   | Source VCS metadata file or directory               |\n" +
+		"|                      | `init`                  | []string | *none*                   | Args to initialize a new repo                       |\n" +
+		"|                      | `pull`                  | []string | *none*                   | Args to pull changes                                |\n" +
+		"|                      | `push`                  | []string | *none*                   | Args to push changes                                |\n" +
+		"|                      | `status`                | []string | *none*                   | Args to print the status                            |\n" +
+		"|                      | `statusFormat`          | string   | *none*                   | Format of `status` output                           |\n" +
+		"|                      | `version`               | []string | *none*                   | Args to print the source VCS version                |\n" +
+		"|                      | `versionRegexp`         | string   | *none*                   | Regexp to extract the version                       |\n" +
+		"| `template`           | `options`               | []string | `[\"missingkey=error\"]`   | Template options                                    |\n" +
+		"| `vault`              | `command`               | string   | `vault`                  | Vault CLI command                                   |\n" +
 		"\n" +
 		"### Examples\n" +
 		"\n" +
@@ -2100,12 +2481,19 @@ func init() {
 		"\n" +
 		"| Prefix       | Effect                                                                         |\n" +
 		"| ------------ | ------------------------------------------------------------------------------ |\n" +
+		"| `after_`     | Run script after updating the destination.                                     |\n" +
+		"| `before_`    | Run script before updating the destination.                                    |\n" +
+		"| `block_`     | Manage only a delimited block in an existing file.                             |\n" +
+		"| `create_`    | Only create the file if it does not already exist.                             |\n" +
 		"| `encrypted_` | Encrypt the file in the source state.                                          |\n" +
 		"| `once_`      | Only run script once.                                                          |\n" +
+		"| `onchange_`  | Only run script when its contents have changed since it was last run.          |\n" +
 		"| `private_`   | Remove all group and world permissions from the target file or directory.      |\n" +
 		"| `empty_`     | Ensure the file exists, even if is empty. By default, empty files are removed. |\n" +
 		"| `exact_`     | Remove anything not managed by chezmoi.                                        |\n" +
 		"| `executable_`| Add executable permissions to the target file.                                 |\n" +
+		"| `merge_`     | Merge the contents into an existing structured file.                           |\n" +
+		"| `modify_`    | Treat the contents as a script that modifies an existing file.                 |\n" +
 		"| `run_`       | Treat the contents as a script to run.                                         |\n" +
 		"| `symlink_`   | Create a symlink instead of a regular file.                                    |\n" +
 		"| `dot_`       | Rename to use a leading dot, e.g. `dot_foo` becomes `.foo`.                    |\n" +
@@ -2114,17 +2502,77 @@ func init() {
 		"| ------- | ---------------------------------------------------- |\n" +
 		"| `.tmpl` | Treat the contents of the source file as a template. |\n" +
 		"\n" +
-		"Order of prefixes is important, the order is `run_`, `exact_`, `private_`,\n" +
-		"`empty_`, `executable_`, `symlink_`, `once_`, `dot_`.\n" +
+		"Order of prefixes is important, the order is `run_`, one of `block_`,\n" +
+		"`create_`, `merge_`, or `modify_`, `exact_`, `encrypted_`, `private_`,\n" +
+		"`empty_`, `executable_`, `symlink_`, `once_` or `onchange_`, `before_` or\n" +
+		"`after_`, `dot_`.\n" +
 		"\n" +
 		"Different target types allow different prefixes and suffixes:\n" +
 		"\n" +
-		"| Target type   | Allowed prefixes                                          | Allowed suffixes |\n" +
-		"| ------------- | --------------------------------------------------------- | ---------------- |\n" +
-		"| Directory     | `exact_`, `private_`, `dot_`                              | *none*           |\n" +
-		"| Regular file  | `encrypted_`, `private_`, `empty_`, `executable_`, `dot_` | `.tmpl`          |\n" +
-		"| Script        | `run_`, `once_`                                           | `.tmpl`          |\n" +
-		"| Symbolic link | `symlink_`, `dot_`,                                       | `.tmpl`          |\n" +
+		"| Target type   | Allowed prefixes                                                    | Allowed suffixes |\n" +
+		"| ------------- | ------------------------------------------------------------------- | ---------------- |\n" +
+		"| Directory     | `exact_`, `private_`, `dot_`                                        | *none*           |\n" +
+		"| Regular file  | `encrypted_`, `private_`, `empty_`, `executable_`, `dot_`           | `.tmpl`          |\n" +
+		"| Block file    | `block_`, `encrypted_`, `private_`, `executable_`, `dot_`           | `.tmpl`          |\n" +
+		"| Create file   | `create_`, `encrypted_`, `private_`, `executable_`, `dot_`          | `.tmpl`          |\n" +
+		"| Merge file    | `merge_`, `encrypted_`, `private_`, `executable_`, `dot_`           | `.tmpl`          |\n" +
+		"| Modify script | `modify_`, `encrypted_`, `private_`, `executable_`, `dot_`          | `.tmpl`          |\n" +
+		"| Script        | `run_`, `encrypted_`, `once_` or `onchange_`, `before_` or `after_` | `.tmpl`          |\n" +
+		"| Symbolic link | `symlink_`, `dot_`,                                                 | `.tmpl`          |\n" +
+		"\n" +
+		"If a file has the `modify_` prefix then its contents are a script that\n" +
+		"computes the contents of the target file from its current contents. chezmoi\n" +
+		"runs the script with the current contents of the target file on its stdin, or\n" +
+		"with an empty stdin if the target file does not exist, and uses the script's\n" +
+		"stdout as the contents of the target file. This allows chezmoi to manage only\n" +
+		"part of a file, for example a file that is also modified by another program.\n" +
+		"Modify scripts are run whenever chezmoi computes the target state of the file,\n" +
+		"including by `archive`, `cat`, `diff`, `dump`, `verify`, and in dry run mode, so\n" +
+		"they must not have any side effects. Like other scripts, modify scripts are run\n" +
+		"with the interpreter configured for their extension, if any.\n" +
+		"\n" +
+		"If a file has the `block_` prefix then chezmoi manages only a block of lines in\n" +
+		"the target file, delimited by the markers\n" +
+		"\n" +
+		"    # BEGIN chezmoi <name>\n" +
+		"    # END chezmoi\n" +
+		"\n" +
+		"where *name* is the value of the `blockName` configuration variable, or the\n" +
+		"target's name relative to the destination directory if `blockName` is not set.\n" +
+		"chezmoi replaces the lines between the markers with the contents of the source\n" +
+		"file, or appends the block to the target file if it does not yet contain it.\n" +
+		"The rest of the file is left unchanged. If the source file is empty then the\n" +
+		"block, including its markers, is removed.\n" +
+		"\n" +
+		"The permissions of existing `block_`, `merge_`, and `modify_` targets are kept,\n" +
+		"except that the `executable_` and `private_` prefixes are applied to them.\n" +
+		"\n" +
+		"If a file has the `merge_` prefix then its contents are a fragment that chezmoi\n" +
+		"deep-merges into the current contents of the target file. Values in the\n" +
+		"fragment take precedence over values in the target file, except that maps are\n" +
+		"merged. The format is determined by the target's extension:\n" +
+		"\n" +
+		"| Extension             | Format |\n" +
+		"| --------------------- | ------ |\n" +
+		"| `.gitconfig`, `.ini`  | INI    |\n" +
+		"| `.json`               | JSON   |\n" +
+		"| `.toml`               | TOML   |\n" +
+		"| `.yaml`, `.yml`       | YAML   |\n" +
+		"\n" +
+		"JSON targets may contain comments and trailing commas, as in Visual Studio\n" +
+		"Code's `settings.json`. The merged document is re-serialized, so JSON, TOML, and\n" +
+		"YAML targets lose their comments and formatting, and TOML and YAML targets also\n" +
+		"lose their key order. JSON targets keep their key order and the exact values of\n" +
+		"their numbers. INI targets keep all of their existing lines, including comments\n" +
+		"and formatting, except for the keys in the fragment, which replace all values\n" +
+		"of the same keys in the target, including repeated keys like git's\n" +
+		"`remote.<name>.fetch`.\n" +
+		"\n" +
+		"If a file has the `create_` prefix then chezmoi only writes it if the target\n" +
+		"does not already exist. Once the target exists, chezmoi leaves its contents\n" +
+		"and permissions unchanged and `verify` treats it as up to date. This is useful\n" +
+		"for files that should be initialized by chezmoi but are then managed by the\n" +
+		"user or another program.\n" +
 		"\n" +
 		"## Special files and directories\n" +
 		"\n" +
@@ -2143,18 +2591,130 @@ func init() {
 		"    data:\n" +
 		"        email: \"{{ $email }}\"\n" +
 		"\n" +
-		"### `.chezmoiignore`\n" +
+		"### `.chezmoicommitmessage.tmpl`\n" +
 		"\n" +
-		"If a file called `.chezmoiignore` exists in the source state then it is\n" +
-		"interpreted as a set of patterns to ignore. Patterns are matched using\n" +
-		"[`doublestar.PathMatch`](https://pkg.go.dev/github.com/bmatcuk/doublestar?tab=doc#PathMatch)\n" +
-		"and match against the target path, not the source path.\n" +
+		"If a file called `.chezmoicommitmessage.tmpl` exists in the root of the source\n" +
+		"directory then it is used as the template for commit messages when\n" +
+		"`sourceVCS.autoCommit` or `sourceVCS.autoPush` is true, instead of the builtin\n" +
+		"template. The `sourceVCS.commitMessageTemplate` configuration variable, if set,\n" +
+		"takes precedence.\n" +
+		"\n" +
+		"The template is executed with the parsed status of the source directory, which\n" +
+		"has the fields `Ordinary`, `RenamedOrCopied`, `Unmerged`, `Untracked`, and\n" +
+		"`Ignored`, as in `git status --porcelain=v2`. Each entry has the status fields\n" +
+		"`X`, `Y`, and `Path` (the source path), and also:\n" +
 		"\n" +
-		"Patterns can be excluded by prefixing them with a `!` character. All excludes\n" +
-		"take priority over all includes.\n" +
+		"| Field            | Type     | Description                                                          |\n" +
+		"| ---------------- | -------- | -------------------------------------------------------------------- |\n" +
+		"| `TargetName`     | string   | Target path relative to the destination directory, empty if none     |\n" +
+		"| `Type`           | string   | `dir`, `file`, `script`, or `symlink`                                |\n" +
+		"| `Attributes`     | []string | Source state attributes, e.g. `encrypted`, `private`                 |\n" +
+		"| `OrigTargetName` | string   | Original target path of renamed or copied entries                    |\n" +
+		"\n" +
+		"The template also has the field `Command`, the name of the chezmoi command that\n" +
+		"made the change, for example `add`.\n" +
+		"\n" +
+		"#### `.chezmoicommitmessage.tmpl` examples\n" +
+		"\n" +
+		"    {{ .Command }}: update dotfiles\n" +
+		"    {{ range .Ordinary }}\n" +
+		"    * {{ .TargetName | default .Path }}\n" +
+		"    {{- end }}\n" +
 		"\n" +
-		"Comments are introduced with the `#` character and run until the end of the\n" +
-		"line.\n" +
+		"### `.chezmoidata.<format>`\n" +
+		"\n" +
+		"If a file called `.chezmoidata.<format>` exists in the source state, where\n" +
+		"*format* is `json`, `toml`, or `yaml`, then its contents are merged into the\n" +
+		"template data. `.chezmoidata.<format>` files may also be placed in\n" +
+		"subdirectories. Files are read in lexical order of their paths, and are merged\n" +
+		"recursively so later files can override individual values in earlier files.\n" +
+		"Variables defined in the `data` section of the config file take precedence over\n" +
+		"variables defined in `.chezmoidata.<format>` files.\n" +
+		"\n" +
+		"`.chezmoidata.<format>` files are not templates. Use `chezmoi data` to see the\n" +
+		"resulting template data.\n" +
+		"\n" +
+		"#### `.chezmoidata.<format>` examples\n" +
+		"\n" +
+		"    colors:\n" +
+		"      background: black\n" +
+		"      foreground: white\n" +
+		"    packages:\n" +
+		"      - git\n" +
+		"      - zsh\n" +
+		"\n" +
+		"### `.chezmoiexternal.<format>`\n" +
+		"\n" +
+		"If a file called `.chezmoiexternal.<format>` exists in the source state, where\n" +
+		"*format* is `json`, `toml`, or `yaml`, then it is interpreted as a list of\n" +
+		"targets whose contents are read from URLs. Target names are relative to the\n" +
+		"directory containing the `.chezmoiexternal.<format>` file. Each target has the\n" +
+		"following fields:\n" +
+		"\n" +
+		"| Field             | Type   | Default value | Description                                          |\n" +
+		"| ----------------- | ------ | ------------- | ---------------------------------------------------- |\n" +
+		"| `type`            | string | *none*        | External type, `archive`, `file`, or `git-repo`      |\n" +
+		"| `url`             | string | *none*        | URL                                                  |\n" +
+		"| `exact`           | bool   | `false`       | Remove files not in the archive or git repo          |\n" +
+		"| `executable`      | bool   | `false`       | Make the file executable                             |\n" +
+		"| `format`          | string | *from URL*    | Archive format, `tar`, `tar.bz2`, `tar.gz`, or `zip` |\n" +
+		"| `stripComponents` | int    | `0`           | Number of leading path components to strip           |\n" +
+		"| `refreshPeriod`   | string | `0`           | How long to cache the contents, e.g. `168h`          |\n" +
+		"\n" +
+		"A `file` target is a file with the contents of the URL. An `archive` target is a\n" +
+		"directory with the contents of the archive at the URL. A `git-repo` target is a\n" +
+		"directory with the contents of the git repo at the URL, without its `.git`\n" +
+		"directory. The entries of externals are treated like any other entries in the\n" +
+		"source state, so they are applied, diffed, verified, and archived in the same\n" +
+		"way. However, their source state is the `.chezmoiexternal.<format>` file, so\n" +
+		"commands that modify the source state, such as `add`, `chattr`, `edit`,\n" +
+		"`forget`, and `remove`, refuse to modify externals.\n" +
+		"\n" +
+		"Contents are only downloaded when they are needed, and ignored externals are\n" +
+		"never downloaded. Downloaded contents are cached in the cache directory. If the\n" +
+		"refresh period is `0` then they are never downloaded again, unless the\n" +
+		"`--refresh-externals` flag is given. The cache is updated even in dry run mode.\n" +
+		"Downloads time out after five minutes.\n" +
+		"\n" +
+		"`.chezmoiexternal.<format>` is interpreted as a template.\n" +
+		"\n" +
+		"#### `.chezmoiexternal.<format>` examples\n" +
+		"\n" +
+		"    [\".oh-my-zsh\"]\n" +
+		"        type = \"archive\"\n" +
+		"        url = \"https://github.com/robbyrussell/oh-my-zsh/archive/master.tar.gz\"\n" +
+		"        exact = true\n" +
+		"        stripComponents = 1\n" +
+		"        refreshPeriod = \"168h\"\n" +
+		"    [\".vim/autoload/plug.vim\"]\n" +
+		"        type = \"file\"\n" +
+		"        url = \"https://raw.githubusercontent.com/junegunn/vim-plug/master/plug.vim\"\n" +
+		"        refreshPeriod = \"168h\"\n" +
+		"\n" +
+		"### `.chezmoiignore`\n" +
+		"\n" +
+		"If a file called `.chezmoiignore` exists in the source state then it is\n" +
+		"interpreted as a set of patterns to ignore. Patterns follow the same rules as\n" +
+		"`.gitignore` files, are matched using\n" +
+		"[`doublestar.Match`](https://pkg.go.dev/github.com/bmatcuk/doublestar/v2?tab=doc#Match),\n" +
+		"and match against the target path, not the source path:\n" +
+		"\n" +
+		"* A pattern without a slash matches at any depth, for example `*.txt` matches\n" +
+		"  `foo.txt` and `dir/foo.txt`.\n" +
+		"* A leading slash, or a slash in the middle of a pattern, anchors the pattern\n" +
+		"  to the directory containing the `.chezmoiignore` file, for example `/*.txt`\n" +
+		"  only matches `foo.txt`.\n" +
+		"* A trailing slash makes the pattern match only directories.\n" +
+		"* A pattern that matches a directory also matches everything inside it.\n" +
+		"* Patterns can be excluded by prefixing them with a `!` character.\n" +
+		"* The last pattern that matches a target determines whether it is ignored.\n" +
+		"\n" +
+		"Comments are introduced with a `#` character at the start of a line or after\n" +
+		"whitespace, and run until the end of the line. A leading `#` or `!` that is part\n" +
+		"of a pattern can be escaped with a backslash, for example `\\#file#`.\n" +
+		"\n" +
+		"Invalid patterns are reported with the file name and line number of the\n" +
+		"pattern.\n" +
 		"\n" +
 		"`.chezmoiignore` is interpreted as a template. This allows different files to be\n" +
 		"ignored on different machines.\n" +
@@ -2165,9 +2725,10 @@ func init() {
 		"\n" +
 		"    README.md\n" +
 		"\n" +
-		"    *.txt   # ignore *.txt in the target directory\n" +
-		"    */*.txt # ignore *.txt in subdirectories of the target directory\n" +
-		"    backups/** # ignore backups folder in chezmoi directory and all its contents\n" +
+		"    /*.txt     # ignore *.txt in the target directory\n" +
+		"    */*.txt    # ignore *.txt in subdirectories of the target directory\n" +
+		"    backups/   # ignore backups directories and all their contents\n" +
+		"    !backups/keep # this has no effect, because backups/ is ignored\n" +
 		"\n" +
 		"    {{- if ne .email \"john.smith@company.com\" }}\n" +
 		"    # Ignore .company-directory unless configured with a company email\n" +
@@ -2182,7 +2743,9 @@ func init() {
 		"\n" +
 		"If a file called `.chezmoiremove` exists in the source state then it is\n" +
 		"interpreted as a list of targets to remove. `.chezmoiremove` is interpreted as a\n" +
-		"template.\n" +
+		"template. It uses the same syntax as `.chezmoiignore`, except that all patterns\n" +
+		"are anchored to the directory containing the `.chezmoiremove` file, so\n" +
+		"`.hushlogin` only removes `~/.hushlogin` and not `~/dir/.hushlogin`.\n" +
 		"\n" +
 		"### `.chezmoitemplates`\n" +
 		"\n" +
@@ -2230,6 +2793,10 @@ func init() {
 		"the `data` section of the config file. Longer substitutions occur before shorter\n" +
 		"ones. This implies the `--template` option.\n" +
 		"\n" +
+		"#### `--create`\n" +
+		"\n" +
+		"Set the `create` attribute on added files.\n" +
+		"\n" +
 		"#### `-e`, `--empty`\n" +
 		"\n" +
 		"Set the `empty` attribute on added files.\n" +
@@ -2266,6 +2833,26 @@ func init() {
 		"Ensure that *targets* are in the target state, updating them if necessary. If no\n" +
 		"targets are specified, the state of all targets are ensured.\n" +
 		"\n" +
+		"chezmoi records the state of every file, directory, and symlink that it writes\n" +
+		"in the `entryState` bucket of its persistent state. If a target has been\n" +
+		"modified since chezmoi last wrote it, and it differs from the target state, then\n" +
+		"`apply` and `edit --apply` handle it according to the `modifiedPolicy`\n" +
+		"configuration variable:\n" +
+		"\n" +
+		"| Policy      | Effect                                                |\n" +
+		"| ----------- | ----------------------------------------------------- |\n" +
+		"| `overwrite` | Overwrite the target without warning                  |\n" +
+		"| `prompt`    | Prompt whether to overwrite the target                |\n" +
+		"| `skip`      | Print a warning and leave the target unchanged        |\n" +
+		"| `warn`      | Print a warning and overwrite the target, the default |\n" +
+		"\n" +
+		"The policy is not applied in dry run mode or by `diff`, `status`, and `verify`,\n" +
+		"which always show the difference between the target and the target state.\n" +
+		"\n" +
+		"The state of `block_`, `merge_`, and `modify_` targets is not recorded, as\n" +
+		"chezmoi only manages part of them, so changes to them by other programs are\n" +
+		"never treated as modifications.\n" +
+		"\n" +
 		"#### `apply` examples\n" +
 		"\n" +
 		"    chezmoi apply\n" +
@@ -2275,7 +2862,7 @@ func init() {
 		"### `archive`\n" +
 		"\n" +
 		"Generate a tar archive of the target state. This can be piped into `tar` to\n" +
-		"inspect the target state.\n" +
+		"inspect the target state. Encrypted scripts are not included.\n" +
 		"\n" +
 		"#### `--output`, `-o` *filename*\n" +
 		"\n" +
@@ -2288,8 +2875,10 @@ func init() {
 		"\n" +
 		"### `cat` *targets*\n" +
 		"\n" +
-		"Write the target state of *targets*  to stdout. *targets* must be files or\n" +
-		"symlinks. For files, the target file contents are written. For symlinks, the\n" +
+		"Write the target state of *targets*  to stdout. *targets* must be files,\n" +
+		"scripts, or symlinks. For files, the target file contents are written,\n" +
+		"including the result of running modify scripts. For scripts, the script\n" +
+		"contents are written, decrypted if the script is encrypted. For symlinks, the\n" +
 		"target target is written.\n" +
 		"\n" +
 		"#### `cat` examples\n" +
@@ -2317,6 +2906,7 @@ func init() {
 		"\n" +
 		"| Attribute    | Abbreviation |\n" +
 		"| ------------ | ------------ |\n" +
+		"| `create`     | *none*       |\n" +
 		"| `empty`      | `e`          |\n" +
 		"| `encrypted`  | *none*       |\n" +
 		"| `exact`      | *none*       |\n" +
@@ -2325,7 +2915,8 @@ func init() {
 		"| `template`   | `t`          |\n" +
 		"\n" +
 		"Multiple attributes modifications may be specified by separating them with a\n" +
-		"comma (`,`).\n" +
+		"comma (`,`). The `create` attribute cannot be added to `block_`, `merge_`, or\n" +
+		"`modify_` files.\n" +
 		"\n" +
 		"#### `chattr` examples\n" +
 		"\n" +
@@ -2354,8 +2945,9 @@ func init() {
 		"\n" +
 		"#### `-f`, `--format` *format*\n" +
 		"\n" +
-		"Print the computed template data in the given format. The accepted formats are\n" +
-		"`json` (JSON), `toml` (TOML), and `yaml` (YAML).\n" +
+		"Print the computed template data in the given format, as for the global\n" +
+		"`--format` flag. The accepted formats are `json` (JSON, the default), `toml`\n" +
+		"(TOML), and `yaml` (YAML).\n" +
 		"\n" +
 		"#### `data` examples\n" +
 		"\n" +
@@ -2372,8 +2964,10 @@ func init() {
 		"\n" +
 		"#### `-f`, `--format` *format*\n" +
 		"\n" +
-		"Print the diff in *format*. The format can be set with the `diff.format`\n" +
-		"variable in the configuration file. Valid formats are:\n" +
+		"Print the diff in *format*. This flag overrides the global `--format` flag, so\n" +
+		"`diff` does not support structured output and rejects `json`, `toml`, and\n" +
+		"`yaml`. The format can be set with the `diff.format` variable in the\n" +
+		"configuration file. Valid formats are:\n" +
 		"\n" +
 		"##### `chezmoi`\n" +
 		"\n" +
@@ -2409,21 +3003,30 @@ func init() {
 		"\n" +
 		"### `doctor`\n" +
 		"\n" +
-		"Check for potential problems.\n" +
+		"Check for potential problems. With the global `--format` flag, print a record\n" +
+		"for each check containing its name (`name`), its status (`ok`, `warning`, or\n" +
+		"`error`), and its result (`result`).\n" +
+		"\n" +
+		"If the source directory is a git repository with an upstream branch, `doctor`\n" +
+		"warns if the current branch is ahead of or behind its upstream, for example\n" +
+		"because local commits have not been pushed.\n" +
 		"\n" +
 		"#### `doctor` examples\n" +
 		"\n" +
 		"    chezmoi doctor\n" +
+		"    chezmoi doctor --format=json\n" +
 		"\n" +
 		"### `dump` [*targets*]\n" +
 		"\n" +
 		"Dump the target state in JSON format. If no targets are specified, then the\n" +
-		"entire target state. The `dump` command accepts additional arguments:\n" +
+		"entire target state. The contents of encrypted scripts are only included when\n" +
+		"the scripts are explicitly given as *targets*. The `dump` command accepts\n" +
+		"additional arguments:\n" +
 		"\n" +
 		"#### `-f`, `--format` *format*\n" +
 		"\n" +
-		"Print the target state in the given format. The accepted formats are `json`\n" +
-		"(JSON) and `yaml` (YAML).\n" +
+		"Print the target state in the given format, as for the global `--format` flag.\n" +
+		"The accepted formats are `json` (JSON, the default) and `yaml` (YAML).\n" +
 		"\n" +
 		"#### `dump` examples\n" +
 		"\n" +
@@ -2601,6 +3204,7 @@ func init() {
 		"    chezmoi managed --include=files,symlinks\n" +
 		"    chezmoi managed -i d\n" +
 		"    chezmoi managed -i d,f\n" +
+		"    chezmoi managed --format=yaml\n" +
 		"\n" +
 		"### `merge` *targets*\n" +
 		"\n" +
@@ -2629,6 +3233,19 @@ func init() {
 		"    chezmoi purge\n" +
 		"    chezmoi purge --force\n" +
 		"\n" +
+		"### `re-add` [*targets*]\n" +
+		"\n" +
+		"Re-add each of *targets* whose contents in the destination directory differ\n" +
+		"from its target contents to the source state. If no targets are specified, all\n" +
+		"managed files are re-added. The attributes of the files in the source state are\n" +
+		"kept and encrypted files are re-encrypted. Templates are not re-added, as this\n" +
+		"would replace the template with its output, and a warning is printed instead.\n" +
+		"\n" +
+		"#### `re-add` examples\n" +
+		"\n" +
+		"    chezmoi re-add\n" +
+		"    chezmoi re-add ~/.bashrc\n" +
+		"\n" +
 		"### `remove` *targets*\n" +
 		"\n" +
 		"Remove *targets* from both the source state and the destination directory.\n" +
@@ -2669,13 +3286,26 @@ func init() {
 		"\n" +
 		"Execute the source version control system in the source directory with *args*.\n" +
 		"Note that any flags for the source version control system must be separated with\n" +
-		"a `--` to stop chezmoi from reading them.\n" +
+		"a `--` to stop chezmoi from reading them. The `source` command has the following\n" +
+		"subcommand:\n" +
+		"\n" +
+		"#### `source status`\n" +
+		"\n" +
+		"Print the current branch of the source directory, its upstream, and how many\n" +
+		"commits it is ahead of and behind its upstream, followed by the status of each\n" +
+		"changed file in the source directory. Files are shown by their target names\n" +
+		"where possible. With the global `--format` flag, print a record containing the\n" +
+		"branch (`head`), its upstream (`upstream`), the `ahead` and `behind` counts, and\n" +
+		"a list of `changes`. To run the source version control system's own `status`\n" +
+		"command, use `chezmoi source -- status`.\n" +
 		"\n" +
 		"#### `source` examples\n" +
 		"\n" +
 		"    chezmoi source init\n" +
 		"    chezmoi source add .\n" +
 		"    chezmoi source commit -- -m \"Initial commit\"\n" +
+		"    chezmoi source status\n" +
+		"    chezmoi source -- status --short\n" +
 		"\n" +
 		"### `source-path` [*targets*]\n" +
 		"\n" +
@@ -2687,6 +3317,101 @@ func init() {
 		"    chezmoi source-path\n" +
 		"    chezmoi source-path ~/.bashrc\n" +
 		"\n" +
+		"### `state`\n" +
+		"\n" +
+		"Manipulate the persistent state, which chezmoi uses to remember things such as\n" +
+		"which `run_once_` and `run_onchange_` scripts have been run. The persistent\n" +
+		"state is organized into buckets, each containing keys and values. The state of\n" +
+		"scripts is stored in the `script` bucket.\n" +
+		"\n" +
+		"The persistent state is stored using the backend set by the `persistentState`\n" +
+		"configuration variable:\n" +
+		"\n" +
+		"| Backend  | Storage                                                                        |\n" +
+		"| -------- | ------------------------------------------------------------------------------ |\n" +
+		"| `bolt`   | `chezmoistate.boltdb` in the config directory, locked while chezmoi runs       |\n" +
+		"| `json`   | `chezmoistate.json` in the config directory, locked only while being updated   |\n" +
+		"| `memory` | Memory only, discarded when chezmoi exits                                      |\n" +
+		"\n" +
+		"Use the `json` backend if you need to run several instances of chezmoi at the\n" +
+		"same time. Both the `bolt` and `json` backends give up if they cannot take\n" +
+		"their lock within two seconds. In dry run mode, chezmoi uses an in-memory snapshot of the\n" +
+		"persistent state so that it is never modified.\n" +
+		"\n" +
+		"The `state` command has the following subcommands:\n" +
+		"\n" +
+		"| Subcommand | Effect                                                                    |\n" +
+		"| ---------- | ------------------------------------------------------------------------- |\n" +
+		"| `dump`     | Write all buckets, or the bucket given with `--bucket`, to stdout         |\n" +
+		"| `get`      | Print the value of the key given with `--key` in the bucket `--bucket`    |\n" +
+		"| `set`      | Set the key given with `--key` in the bucket `--bucket` to `--value`      |\n" +
+		"| `delete`   | Delete the key given with `--key` from the bucket `--bucket`              |\n" +
+		"| `reset`    | Delete the bucket given with `--bucket`, or all buckets if not given      |\n" +
+		"| `migrate`  | Copy the persistent state from the `--from` backend to the `--to` backend |\n" +
+		"\n" +
+		"#### `--bucket` *bucket*\n" +
+		"\n" +
+		"Operate on *bucket*.\n" +
+		"\n" +
+		"#### `--from` *backend*, `--to` *backend*\n" +
+		"\n" +
+		"For `migrate`, the backends to copy the persistent state from and to.\n" +
+		"\n" +
+		"#### `-f`, `--format` *format*\n" +
+		"\n" +
+		"For `dump`, print the persistent state in the given format, as for the global\n" +
+		"`--format` flag. The accepted formats are `json` (JSON, the default), `toml`\n" +
+		"(TOML), and `yaml` (YAML).\n" +
+		"\n" +
+		"#### `-f`, `--force`\n" +
+		"\n" +
+		"For `reset`, reset without prompting.\n" +
+		"\n" +
+		"#### `state` examples\n" +
+		"\n" +
+		"    chezmoi state dump\n" +
+		"    chezmoi state dump --bucket=script --format=yaml\n" +
+		"    chezmoi state get --bucket=script --key=install-packages.sh\n" +
+		"    chezmoi state delete --bucket=script --key=install-packages.sh\n" +
+		"    chezmoi state reset --bucket=script\n" +
+		"    chezmoi state migrate --from=bolt --to=json\n" +
+		"\n" +
+		"### `status` [*targets*]\n" +
+		"\n" +
+		"Print a summary of the changes that `chezmoi apply` would make to each target,\n" +
+		"without making them. If no targets are specified, the status of all targets is\n" +
+		"printed. Each changed target is printed on a line with a two-letter status code\n" +
+		"followed by its path relative to the destination directory, similar to `git\n" +
+		"status --short`.\n" +
+		"\n" +
+		"The first column is the difference between the target's actual state and the\n" +
+		"state that chezmoi last wrote. The second column is the change that `chezmoi\n" +
+		"apply` would make. The status codes are:\n" +
+		"\n" +
+		"| Code | First column                             | Second column       |\n" +
+		"| ---- | ---------------------------------------- | ------------------- |\n" +
+		"| ` `  | No change, or chezmoi has not written it | No change           |\n" +
+		"| `A`  |                                          | Added               |\n" +
+		"| `D`  | Deleted since chezmoi last wrote it      | Deleted             |\n" +
+		"| `M`  | Modified since chezmoi last wrote it     | Modified            |\n" +
+		"| `P`  |                                          | Permissions changed |\n" +
+		"| `R`  |                                          | Script will be run  |\n" +
+		"\n" +
+		"Targets listed in `.chezmoiremove` are only shown as deleted if the `--remove`\n" +
+		"option is given, as with `apply`.\n" +
+		"\n" +
+		"#### `-f`, `--format` *format*\n" +
+		"\n" +
+		"Print the status in the given format, as for the global `--format` flag. The\n" +
+		"accepted formats are `json` (JSON) and `yaml` (YAML). Each entry has a `path`\n" +
+		"and a `status`.\n" +
+		"\n" +
+		"#### `status` examples\n" +
+		"\n" +
+		"    chezmoi status\n" +
+		"    chezmoi status ~/.bashrc\n" +
+		"    chezmoi status --format=json\n" +
+		"\n" +
 		"### `unmanage` *targets*\n" +
 		"\n" +
 		"`unmanage` is an alias for `forget` for symmetry with `manage`.\n" +
@@ -2698,14 +3423,29 @@ func init() {
 		"#### `unmanaged` examples\n" +
 		"\n" +
 		"    chezmoi unmanaged\n" +
+		"    chezmoi unmanaged --format=json\n" +
 		"\n" +
 		"### `update`\n" +
 		"\n" +
-		"Pull changes from the source VCS and apply any changes.\n" +
+		"Pull changes from the source VCS and apply any changes. If the source VCS is\n" +
+		"git, then `update` refuses to pull if the source directory has uncommitted\n" +
+		"changes to tracked files or if its branch has diverged from its upstream. The\n" +
+		"`update` command accepts additional arguments:\n" +
+		"\n" +
+		"#### `-a`, `--apply`\n" +
+		"\n" +
+		"Apply changes after pulling. This is the default. Use `--apply=false` to only\n" +
+		"pull.\n" +
+		"\n" +
+		"#### `-f`, `--force`\n" +
+		"\n" +
+		"Pull even if the source directory has uncommitted changes or has diverged from\n" +
+		"its upstream.\n" +
 		"\n" +
 		"#### `update` examples\n" +
 		"\n" +
 		"    chezmoi update\n" +
+		"    chezmoi update --force\n" +
 		"\n" +
 		"### `upgrade`\n" +
 		"\n" +
@@ -2735,9 +3475,20 @@ func init() {
 		"(success) if all targets match their target state, or 1 (failure) otherwise. If\n" +
 		"no targets are specified then all targets are checked.\n" +
 		"\n" +
+		"With the global `--format` flag, print a record for each target that does not\n" +
+		"match its target state, with a `reason` that is one of:\n" +
+		"\n" +
+		"| Reason        | Meaning                                                 |\n" +
+		"| ------------- | ------------------------------------------------------- |\n" +
+		"| `missing`     | The target does not exist                               |\n" +
+		"| `modified`    | The target's contents or type differ                    |\n" +
+		"| `permissions` | The target's permissions differ                         |\n" +
+		"| `unexpected`  | The target exists but should not, e.g. in an exact dir  |\n" +
+		"\n" +
 		"#### `verify` examples\n" +
 		"\n" +
 		"    chezmoi verify\n" +
+		"    chezmoi verify --format=json\n" +
 		"    chezmoi verify ~/.bashrc\n" +
 		"\n" +
 		"## Editor configuration\n" +
@@ -2802,7 +3553,8 @@ func init() {
 		"| `.chezmoi.sourceDir`    | The source directory.                                                                                                           |\n" +
 		"| `.chezmoi.username`     | The username of the user running chezmoi.                                                                                       |\n" +
 		"\n" +
-		"Additional variables can be defined in the config file in the `data` section.\n" +
+		"Additional variables can be defined in the config file in the `data` section\n" +
+		"and in `.chezmoidata.<format>` files in the source state.\n" +
 		"Variable names must consist of a letter and be followed by zero or more letters\n" +
 		"and/or digits.\n" +
 		"\n" +
@@ -12,6 +12,8 @@ import (
 	"github.com/coreos/go-semver/semver"
 	"github.com/spf13/cobra"
 	shell "github.com/twpayne/go-shell"
+
+	"github.com/twpayne/chezmoi/internal/git"
 )
 
 var doctorCmd = &cobra.Command{
@@ -33,6 +35,7 @@ type doctorCheck interface {
 	Check() (bool, error)
 	Enabled() bool
 	MustSucceed() bool
+	Name() string
 	Result() string
 	Skip() bool
 }
@@ -43,6 +46,12 @@ type doctorCheckResult struct {
 	result string
 }
 
+type doctorCheckRecord struct {
+	Name   string `json:"name" yaml:"name"`
+	Status string `json:"status" yaml:"status"`
+	Result string `json:"result" yaml:"result"`
+}
+
 type doctorBinaryCheck struct {
 	name          string
 	binaryName    string
@@ -54,6 +63,13 @@ type doctorBinaryCheck struct {
 	version       *semver.Version
 }
 
+// A doctorBuiltinCheck reports that a builtin implementation is used instead
+// of a binary.
+type doctorBuiltinCheck struct {
+	name       string
+	binaryName string
+}
+
 type doctorDirectoryCheck struct {
 	name         string
 	path         string
@@ -72,6 +88,12 @@ type doctorFileCheck struct {
 
 type doctorRuntimeCheck struct{}
 
+// A doctorSourceVCSBranchCheck reports whether the source directory's branch
+// has commits that have not been pushed to or pulled from its upstream.
+type doctorSourceVCSBranchCheck struct {
+	branch *git.BranchStatus
+}
+
 type doctorSuspiciousFilesCheck struct {
 	path      string
 	filenames map[string]bool
@@ -92,20 +114,45 @@ func init() {
 }
 
 func (c *Config) runDoctorCmd(cmd *cobra.Command, args []string) error {
+	format, err := c.getFormat()
+	if err != nil {
+		return err
+	}
+
 	shell, _ := shell.CurrentUserShell()
 
 	var vcsCommandCheck doctorCheck
-	if vcs, err := c.getVCS(); err == nil {
+	if vcs, err := c.getVCS(); err != nil {
+		vcsCommandCheck = &doctorBinaryCheck{
+			name:       "source VCS command",
+			binaryName: c.SourceVCS.Command,
+		}
+	} else if _, ok := vcs.(builtinVCS); ok {
+		vcsCommandCheck = &doctorBuiltinCheck{
+			name:       "source VCS command",
+			binaryName: c.SourceVCS.Command,
+		}
+	} else {
+		versionArgs, err := vcs.VersionArgs()
+		if err != nil {
+			return err
+		}
 		vcsCommandCheck = &doctorBinaryCheck{
 			name:          "source VCS command",
 			binaryName:    c.SourceVCS.Command,
-			versionArgs:   vcs.VersionArgs(),
+			versionArgs:   versionArgs,
 			versionRegexp: vcs.VersionRegexp(),
 		}
-	} else {
-		vcsCommandCheck = &doctorBinaryCheck{
-			name:       "source VCS command",
-			binaryName: c.SourceVCS.Command,
+	}
+
+	sourceVCSBranchCheck := &doctorSourceVCSBranchCheck{}
+	if vcs, err := c.getVCS(); err == nil {
+		if initialized, err := vcs.Initialized(c.SourceDir); err == nil && initialized {
+			if status, err := c.getSourceVCSStatus(vcs); err == nil {
+				if status, ok := status.(*git.Status); ok {
+					sourceVCSBranchCheck.branch = status.Branch
+				}
+			}
 		}
 	}
 
@@ -117,6 +164,7 @@ func (c *Config) runDoctorCmd(cmd *cobra.Command, args []string) error {
 	}
 
 	allOK := true
+	records := []*doctorCheckRecord{}
 	for _, dc := range []doctorCheck{
 		&doctorVersionCheck{},
 		&doctorRuntimeCheck{},
@@ -155,6 +203,7 @@ func (c *Config) runDoctorCmd(cmd *cobra.Command, args []string) error {
 			binaryName: c.Merge.Command,
 		},
 		vcsCommandCheck,
+		sourceVCSBranchCheck,
 		gpgBinaryCheck,
 		&doctorBinaryCheck{
 			name:          "1Password CLI",
@@ -211,8 +260,26 @@ func (c *Config) runDoctorCmd(cmd *cobra.Command, args []string) error {
 		if !dcr.ok {
 			allOK = false
 		}
-		if dcr.result != "" {
-			fmt.Printf("%7s: %s\n", dcr.prefix, dcr.result)
+		if dcr.result == "" {
+			continue
+		}
+		if format != nil {
+			status := strings.ToLower(dcr.prefix)
+			if status == "" {
+				status = strings.ToLower(errorPrefix)
+			}
+			records = append(records, &doctorCheckRecord{
+				Name:   dc.Name(),
+				Status: status,
+				Result: dcr.result,
+			})
+		} else {
+			fmt.Fprintf(c.Stdout, "%7s: %s\n", dcr.prefix, dcr.result)
+		}
+	}
+	if format != nil {
+		if err := format(c.Stdout, records); err != nil {
+			return err
 		}
 	}
 	if !allOK {
@@ -279,6 +346,10 @@ func (c *doctorBinaryCheck) MustSucceed() bool {
 	return c.mustSucceed
 }
 
+func (c *doctorBinaryCheck) Name() string {
+	return c.name
+}
+
 func (c *doctorBinaryCheck) Result() string {
 	if c.path == "" {
 		return fmt.Sprintf("%s (%s, not found)", c.binaryName, c.name)
@@ -306,6 +377,30 @@ func (c *doctorBinaryCheck) getVersionFromOutput(output []byte) (*semver.Version
 	return semver.NewVersion(string(m[1]))
 }
 
+func (c *doctorBuiltinCheck) Check() (bool, error) {
+	return true, nil
+}
+
+func (c *doctorBuiltinCheck) Enabled() bool {
+	return true
+}
+
+func (c *doctorBuiltinCheck) MustSucceed() bool {
+	return false
+}
+
+func (c *doctorBuiltinCheck) Name() string {
+	return c.name
+}
+
+func (c *doctorBuiltinCheck) Result() string {
+	return fmt.Sprintf("%s (%s, builtin)", c.binaryName, c.name)
+}
+
+func (c *doctorBuiltinCheck) Skip() bool {
+	return false
+}
+
 func (c *doctorDirectoryCheck) Check() (bool, error) {
 	c.info, c.err = os.Stat(c.path)
 	if c.err != nil && os.IsNotExist(c.err) {
@@ -327,6 +422,10 @@ func (c *doctorDirectoryCheck) MustSucceed() bool {
 	return true
 }
 
+func (c *doctorDirectoryCheck) Name() string {
+	return c.name
+}
+
 func (c *doctorDirectoryCheck) Result() string {
 	switch {
 	case os.IsNotExist(c.err):
@@ -364,6 +463,10 @@ func (c *doctorFileCheck) MustSucceed() bool {
 	return c.mustSucceed
 }
 
+func (c *doctorFileCheck) Name() string {
+	return c.name
+}
+
 func (c *doctorFileCheck) Result() string {
 	if c.path == "" {
 		return fmt.Sprintf("not set (%s)", c.name)
@@ -387,6 +490,10 @@ func (doctorRuntimeCheck) MustSucceed() bool {
 	return true
 }
 
+func (doctorRuntimeCheck) Name() string {
+	return "runtime"
+}
+
 func (doctorRuntimeCheck) Result() string {
 	return fmt.Sprintf("runtime.GOOS %s, runtime.GOARCH %s", runtime.GOOS, runtime.GOARCH)
 }
@@ -395,6 +502,33 @@ func (doctorRuntimeCheck) Skip() bool {
 	return false
 }
 
+func (c *doctorSourceVCSBranchCheck) Check() (bool, error) {
+	return c.branch.Ahead == 0 && c.branch.Behind == 0, nil
+}
+
+func (c *doctorSourceVCSBranchCheck) Enabled() bool {
+	return true
+}
+
+func (c *doctorSourceVCSBranchCheck) MustSucceed() bool {
+	return false
+}
+
+func (c *doctorSourceVCSBranchCheck) Name() string {
+	return "source VCS branch"
+}
+
+func (c *doctorSourceVCSBranchCheck) Result() string {
+	if c.branch.Upstream == "" {
+		return fmt.Sprintf("%s (source VCS branch, no upstream)", c.branch.Head)
+	}
+	return fmt.Sprintf("%s (source VCS branch, upstream %s, ahead %d, behind %d)", c.branch.Head, c.branch.Upstream, c.branch.Ahead, c.branch.Behind)
+}
+
+func (c *doctorSourceVCSBranchCheck) Skip() bool {
+	return c.branch == nil
+}
+
 func (c *doctorSuspiciousFilesCheck) Check() (bool, error) {
 	if err := filepath.Walk(c.path, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -418,6 +552,10 @@ func (c *doctorSuspiciousFilesCheck) MustSucceed() bool {
 	return false
 }
 
+func (c *doctorSuspiciousFilesCheck) Name() string {
+	return "suspicious files"
+}
+
 func (c *doctorSuspiciousFilesCheck) Result() string {
 	if len(c.found) == 0 {
 		return ""
@@ -444,6 +582,10 @@ func (doctorVersionCheck) MustSucceed() bool {
 	return false
 }
 
+func (doctorVersionCheck) Name() string {
+	return "version"
+}
+
 func (doctorVersionCheck) Result() string {
 	return "version " + rootCmd.Version
 }
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/twpayne/chezmoi/internal/git"
+)
+
+// A vcsDriverConfig defines a VCS in the config file. Each operation is a list
+// of arguments to sourceVCS.command, each of which is a template.
+type vcsDriverConfig struct {
+	Add           []string
+	Clone         []string
+	Commit        []string
+	Dir           string
+	Init          []string
+	Pull          []string
+	Push          []string
+	Status        []string
+	StatusFormat  string
+	Version       []string
+	VersionRegexp string
+}
+
+// vcsDriverTemplateData is the data passed to the templates of a
+// vcsDriverConfig.
+type vcsDriverTemplateData struct {
+	Dir     string
+	Message string
+	Path    string
+	Repo    string
+}
+
+// A driverVCS is a VCS defined by a vcsDriverConfig.
+type driverVCS struct {
+	addTmpls      []*template.Template
+	cloneTmpls    []*template.Template
+	commitTmpls   []*template.Template
+	dir           string
+	initTmpls     []*template.Template
+	pullTmpls     []*template.Template
+	pushTmpls     []*template.Template
+	statusTmpls   []*template.Template
+	statusFormat  string
+	versionTmpls  []*template.Template
+	versionRegexp *regexp.Regexp
+}
+
+// newDriverVCS returns a new driverVCS defined by config.
+func newDriverVCS(config *vcsDriverConfig) (*driverVCS, error) {
+	switch config.StatusFormat {
+	case "", "git-porcelain-v2":
+	default:
+		return nil, fmt.Errorf("sourceVCS.driver.statusFormat: %s: unknown format", config.StatusFormat)
+	}
+	d := &driverVCS{
+		dir:          config.Dir,
+		statusFormat: config.StatusFormat,
+	}
+	for _, op := range []struct {
+		name  string
+		args  []string
+		tmpls *[]*template.Template
+	}{
+		{name: "add", args: config.Add, tmpls: &d.addTmpls},
+		{name: "clone", args: config.Clone, tmpls: &d.cloneTmpls},
+		{name: "commit", args: config.Commit, tmpls: &d.commitTmpls},
+		{name: "init", args: config.Init, tmpls: &d.initTmpls},
+		{name: "pull", args: config.Pull, tmpls: &d.pullTmpls},
+		{name: "push", args: config.Push, tmpls: &d.pushTmpls},
+		{name: "status", args: config.Status, tmpls: &d.statusTmpls},
+		{name: "version", args: config.Version, tmpls: &d.versionTmpls},
+	} {
+		for i, arg := range op.args {
+			name := fmt.Sprintf("sourceVCS.driver.%s[%d]", op.name, i)
+			tmpl, err := template.New(name).Option("missingkey=error").Parse(arg)
+			if err != nil {
+				return nil, err
+			}
+			// Execute the template once so that references to unknown fields
+			// are reported now rather than when the VCS is used.
+			if err := tmpl.Execute(&bytes.Buffer{}, vcsDriverTemplateData{}); err != nil {
+				return nil, err
+			}
+			*op.tmpls = append(*op.tmpls, tmpl)
+		}
+	}
+	if config.VersionRegexp != "" {
+		var err error
+		d.versionRegexp, err = regexp.Compile(config.VersionRegexp)
+		if err != nil {
+			return nil, fmt.Errorf("sourceVCS.driver.versionRegexp: %w", err)
+		}
+	}
+	return d, nil
+}
+
+func (d *driverVCS) AddArgs(path string) ([]string, error) {
+	return executeDriverTemplates(d.addTmpls, vcsDriverTemplateData{Path: path})
+}
+
+func (d *driverVCS) CloneArgs(repo, dir string) ([]string, error) {
+	return executeDriverTemplates(d.cloneTmpls, vcsDriverTemplateData{Dir: dir, Repo: repo})
+}
+
+func (d *driverVCS) CommitArgs(message string) ([]string, error) {
+	return executeDriverTemplates(d.commitTmpls, vcsDriverTemplateData{Message: message})
+}
+
+func (d *driverVCS) InitArgs() ([]string, error) {
+	return executeDriverTemplates(d.initTmpls, vcsDriverTemplateData{})
+}
+
+// Initialized returns if dir contains the driver's metadata file or directory
+// or, if the driver does not define one, if dir is not empty.
+func (d *driverVCS) Initialized(dir string) (bool, error) {
+	if d.dir == "" {
+		infos, err := ioutil.ReadDir(dir)
+		switch {
+		case err == nil:
+			return len(infos) != 0, nil
+		case os.IsNotExist(err):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+	_, err := os.Stat(filepath.Join(dir, d.dir))
+	switch {
+	case err == nil:
+		return true, nil
+	case os.IsNotExist(err):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// ParseStatusOutput parses output according to the driver's status format. If
+// the driver does not define a status format then it returns nil if output is
+// empty, or output as a string otherwise.
+func (d *driverVCS) ParseStatusOutput(output []byte) (interface{}, error) {
+	switch d.statusFormat {
+	case "git-porcelain-v2":
+		return git.ParseStatusPorcelainV2(output)
+	default:
+		if len(bytes.TrimSpace(output)) == 0 {
+			return nil, nil
+		}
+		return string(output), nil
+	}
+}
+
+func (d *driverVCS) PullArgs() ([]string, error) {
+	return executeDriverTemplates(d.pullTmpls, vcsDriverTemplateData{})
+}
+
+func (d *driverVCS) PushArgs() ([]string, error) {
+	return executeDriverTemplates(d.pushTmpls, vcsDriverTemplateData{})
+}
+
+func (d *driverVCS) StatusArgs() ([]string, error) {
+	return executeDriverTemplates(d.statusTmpls, vcsDriverTemplateData{})
+}
+
+func (d *driverVCS) VersionArgs() ([]string, error) {
+	return executeDriverTemplates(d.versionTmpls, vcsDriverTemplateData{})
+}
+
+func (d *driverVCS) VersionRegexp() *regexp.Regexp {
+	return d.versionRegexp
+}
+
+// executeDriverTemplates executes tmpls with data and returns the results, or
+// nil if tmpls is empty. Templates are parsed when the driver is created, but
+// can still fail when executed, for example when slicing a short .Message.
+func executeDriverTemplates(tmpls []*template.Template, data vcsDriverTemplateData) ([]string, error) {
+	if len(tmpls) == 0 {
+		return nil, nil
+	}
+	args := make([]string, 0, len(tmpls))
+	for _, tmpl := range tmpls {
+		sb := &strings.Builder{}
+		if err := tmpl.Execute(sb, data); err != nil {
+			return nil, err
+		}
+		args = append(args, sb.String())
+	}
+	return args, nil
+}
@@ -1,15 +1,13 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-	"strings"
-
 	"github.com/spf13/cobra"
+	vfs "github.com/twpayne/go-vfs"
+
+	"github.com/twpayne/chezmoi/internal/chezmoi"
 )
 
 type dumpCmdConfig struct {
-	format    string
 	recursive bool
 }
 
@@ -26,24 +24,29 @@ func init() {
 	rootCmd.AddCommand(dumpCmd)
 
 	persistentFlags := dumpCmd.PersistentFlags()
-	persistentFlags.StringVarP(&config.dump.format, "format", "f", "json", "format (JSON, TOML, or YAML)")
+	persistentFlags.StringVarP(&config.format, "format", "f", "", "format (JSON, TOML, or YAML, default JSON)")
 	persistentFlags.BoolVarP(&config.dump.recursive, "recursive", "r", true, "recursive")
 
 	markRemainingZshCompPositionalArgumentsAsFiles(dumpCmd, 1)
 }
 
 func (c *Config) runDumpCmd(cmd *cobra.Command, args []string) error {
-	format, ok := formatMap[strings.ToLower(c.dump.format)]
-	if !ok {
-		return fmt.Errorf("%s: unknown format", c.dump.format)
+	format, err := c.getDataFormat()
+	if err != nil {
+		return err
 	}
 	ts, err := c.getTargetState(nil)
 	if err != nil {
 		return err
 	}
+	applyOptions, err := c.getApplyOptions(ts, nil)
+	if err != nil {
+		return err
+	}
+	fs := vfs.NewReadOnlyFS(c.fs)
 	var concreteValue interface{}
 	if len(args) == 0 {
-		concreteValue, err = ts.ConcreteValue(c.dump.recursive)
+		concreteValue, err = ts.ConcreteValue(fs, applyOptions, c.dump.recursive)
 		if err != nil {
 			return err
 		}
@@ -54,7 +57,14 @@ func (c *Config) runDumpCmd(cmd *cobra.Command, args []string) error {
 		}
 		var concreteValues []interface{}
 		for _, entry := range entries {
-			entryConcreteValue, err := entry.ConcreteValue(ts.TargetIgnore.Match, ts.SourceDir, os.FileMode(c.Umask), c.dump.recursive)
+			var entryConcreteValue interface{}
+			// Only include the decrypted contents of encrypted scripts when
+			// they are explicitly requested.
+			if script, ok := entry.(*chezmoi.Script); ok {
+				entryConcreteValue, err = script.DecryptedConcreteValue(ts.TargetIgnore.Match, ts.SourceDir)
+			} else {
+				entryConcreteValue, err = entry.ConcreteValue(fs, applyOptions, ts.SourceDir, c.dump.recursive)
+			}
 			if err != nil {
 				return err
 			}
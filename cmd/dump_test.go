@@ -22,7 +22,6 @@ func TestDumpCmd(t *testing.T) {
 	c := newTestConfig(
 		fs,
 		withDumpCmdConfig(dumpCmdConfig{
-			format:    "json",
 			recursive: true,
 		}),
 		withStdout(stdout),
@@ -42,8 +41,12 @@ func TestDumpCmd(t *testing.T) {
 					"type":       "file",
 					"sourcePath": filepath.Join("/", "home", "user", ".local", "share", "chezmoi", "dir", "file"),
 					"targetPath": filepath.Join("dir", "file"),
+					"block":      false,
+					"create":     false,
 					"empty":      false,
 					"encrypted":  false,
+					"merge":      false,
+					"modify":     false,
 					"perm":       float64(0o644),
 					"template":   false,
 					"contents":   "contents",
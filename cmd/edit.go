@@ -72,7 +72,7 @@ func (c *Config) runEditCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	entries, err := c.getEntries(ts, args)
+	entries, err := c.getSourceEntries(ts, args)
 	if err != nil {
 		return err
 	}
@@ -109,7 +109,7 @@ func (c *Config) runEditCmd(cmd *cobra.Command, args []string) error {
 		defer os.RemoveAll(tempDir)
 		for i := range encryptedFiles {
 			ef := &encryptedFiles[i]
-			plaintext, err := ef.file.Contents()
+			ciphertext, err := c.fs.ReadFile(ef.ciphertextPath)
 			if err != nil {
 				return err
 			}
@@ -117,7 +117,7 @@ func (c *Config) runEditCmd(cmd *cobra.Command, args []string) error {
 			if err := os.MkdirAll(filepath.Dir(ef.plaintextPath), 0o700&^os.FileMode(c.Umask)); err != nil {
 				return err
 			}
-			if err := renameio.WriteFile(ef.plaintextPath, plaintext, 0o600&^os.FileMode(c.Umask)); err != nil {
+			if err := ts.Encryption.DecryptToFile(ef.plaintextPath, ciphertext); err != nil {
 				return err
 			}
 			argv[ef.index] = ef.plaintextPath
@@ -130,11 +130,7 @@ func (c *Config) runEditCmd(cmd *cobra.Command, args []string) error {
 
 	// Re-encrypt any encrypted files.
 	for _, ef := range encryptedFiles {
-		plaintext, err := ioutil.ReadFile(ef.plaintextPath)
-		if err != nil {
-			return err
-		}
-		ciphertext, err := ts.GPG.Encrypt(ef.plaintextPath, plaintext)
+		ciphertext, err := ts.Encryption.EncryptFile(ef.plaintextPath)
 		if err != nil {
 			return err
 		}
@@ -154,23 +150,33 @@ func (c *Config) runEditCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var persistentState chezmoi.PersistentState
+	if c.edit.apply {
+		persistentState, err = c.getPersistentState(nil)
+		if err != nil {
+			return err
+		}
+		defer persistentState.Close()
+	}
+
 	readOnlyFS := vfs.NewReadOnlyFS(c.fs)
-	applyOptions := chezmoi.ApplyOptions{
-		DestDir:           ts.DestDir,
-		DryRun:            c.DryRun,
-		Ignore:            ts.TargetIgnore.Match,
-		ScriptStateBucket: c.scriptStateBucket,
-		Stdout:            c.Stdout,
-		Umask:             ts.Umask,
-		Verbose:           c.Verbose,
+	applyOptions, err := c.getApplyOptions(ts, persistentState)
+	if err != nil {
+		return err
 	}
+	// Preview the changes without recording entry state or checking for
+	// modifications, as nothing is written.
+	previewApplyOptions := *applyOptions
+	previewApplyOptions.DryRun = true
+	previewApplyOptions.EntryStateBucket = nil
+	previewApplyOptions.Modified = nil
 	for i, entry := range entries {
 		anyMutator := chezmoi.NewAnyMutator(chezmoi.NullMutator{})
 		var mutator chezmoi.Mutator = anyMutator
 		if c.edit.diff {
 			mutator = chezmoi.NewVerboseMutator(c.Stdout, mutator, c.colored, c.maxDiffDataSize)
 		}
-		if err := entry.Apply(readOnlyFS, mutator, c.Follow, &applyOptions); err != nil {
+		if err := entry.Apply(readOnlyFS, mutator, c.Follow, &previewApplyOptions); err != nil {
 			return err
 		}
 		if c.edit.apply && anyMutator.Mutated() {
@@ -189,7 +195,7 @@ func (c *Config) runEditCmd(cmd *cobra.Command, args []string) error {
 					c.edit.prompt = false
 				}
 			}
-			if err := entry.Apply(readOnlyFS, c.mutator, c.Follow, &applyOptions); err != nil {
+			if err := entry.Apply(readOnlyFS, c.mutator, c.Follow, applyOptions); err != nil {
 				return err
 			}
 		}
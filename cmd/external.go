@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	vfs "github.com/twpayne/go-vfs"
+
+	"github.com/twpayne/chezmoi/internal/chezmoi"
+)
+
+// externalHTTPClient is the HTTP client used to read externals. Its timeout
+// covers the whole request, including reading the body, so that an
+// unresponsive server does not block chezmoi forever.
+var externalHTTPClient = &http.Client{
+	Timeout: 5 * time.Minute,
+}
+
+// readExternal returns the contents of external. Contents are cached in the
+// cache directory and are only read again if they are older than external's
+// refresh period, or if --refresh-externals is set. The cache is updated even
+// in dry run mode, as it is not part of the destination state.
+func (c *Config) readExternal(external *chezmoi.External) ([]byte, error) {
+	cacheKeyArr := sha256.Sum256([]byte(external.Type + "\x00" + external.URL))
+	cachePath := filepath.Join(c.CacheDir, "external", hex.EncodeToString(cacheKeyArr[:]))
+	if !c.refreshExternals {
+		info, err := c.fs.Stat(cachePath)
+		switch {
+		case err == nil:
+			if external.RefreshPeriod == 0 || time.Since(info.ModTime()) < external.RefreshPeriod {
+				return c.fs.ReadFile(cachePath)
+			}
+		case !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+
+	var data []byte
+	var err error
+	switch external.Type {
+	case chezmoi.ExternalTypeGitRepo:
+		data, err = c.readExternalGitRepo(external.URL)
+	default:
+		data, err = c.readExternalURL(external.URL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := vfs.MkdirAll(c.fs, filepath.Dir(cachePath), 0o700); err != nil {
+		return nil, err
+	}
+	if err := c.fs.WriteFile(cachePath, data, 0o600); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readExternalGitRepo returns a tar archive of the contents of the git repo at
+// url.
+func (c *Config) readExternalGitRepo(url string) ([]byte, error) {
+	tempDir, err := ioutil.TempDir("", "chezmoi-external")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(tempDir)
+	}()
+
+	cloneCmd := exec.Command("git", "clone", "--depth=1", "--quiet", url, tempDir)
+	cloneCmd.Stderr = c.Stderr
+	if err := cloneCmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+
+	archiveCmd := exec.Command("git", "archive", "--format=tar", "HEAD")
+	archiveCmd.Dir = tempDir
+	archiveCmd.Stderr = c.Stderr
+	data, err := archiveCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+	return data, nil
+}
+
+// readExternalURL returns the contents of url.
+func (c *Config) readExternalURL(url string) ([]byte, error) {
+	resp, err := externalHTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
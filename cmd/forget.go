@@ -29,7 +29,7 @@ func (c *Config) runForgetCmd(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	entries, err := c.getEntries(ts, args)
+	entries, err := c.getSourceEntries(ts, args)
 	if err != nil {
 		return err
 	}
@@ -12,20 +12,20 @@ var gitVersionRegexp = regexp.MustCompile(`^git version (\d+\.\d+\.\d+)`)
 
 type gitVCS struct{}
 
-func (gitVCS) AddArgs(path string) []string {
-	return []string{"add", path}
+func (gitVCS) AddArgs(path string) ([]string, error) {
+	return []string{"add", path}, nil
 }
 
-func (gitVCS) CloneArgs(repo, dir string) []string {
-	return []string{"clone", repo, dir}
+func (gitVCS) CloneArgs(repo, dir string) ([]string, error) {
+	return []string{"clone", repo, dir}, nil
 }
 
-func (gitVCS) CommitArgs(message string) []string {
-	return []string{"commit", "--message", message}
+func (gitVCS) CommitArgs(message string) ([]string, error) {
+	return []string{"commit", "--message", message}, nil
 }
 
-func (gitVCS) InitArgs() []string {
-	return []string{"init"}
+func (gitVCS) InitArgs() ([]string, error) {
+	return []string{"init"}, nil
 }
 
 func (gitVCS) Initialized(dir string) (bool, error) {
@@ -44,20 +44,20 @@ func (gitVCS) ParseStatusOutput(output []byte) (interface{}, error) {
 	return git.ParseStatusPorcelainV2(output)
 }
 
-func (gitVCS) PullArgs() []string {
-	return []string{"pull", "--rebase"}
+func (gitVCS) PullArgs() ([]string, error) {
+	return []string{"pull", "--rebase"}, nil
 }
 
-func (gitVCS) PushArgs() []string {
-	return []string{"push"}
+func (gitVCS) PushArgs() ([]string, error) {
+	return []string{"push"}, nil
 }
 
-func (gitVCS) StatusArgs() []string {
-	return []string{"status", "--porcelain=v2"}
+func (gitVCS) StatusArgs() ([]string, error) {
+	return []string{"status", "--branch", "--porcelain=v2"}, nil
 }
 
-func (gitVCS) VersionArgs() []string {
-	return []string{"version"}
+func (gitVCS) VersionArgs() ([]string, error) {
+	return []string{"version"}, nil
 }
 
 func (gitVCS) VersionRegexp() *regexp.Regexp {
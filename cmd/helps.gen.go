@@ -21,6 +21,10 @@ var helps = map[string]help{
 			"  from the `data` section of the config file. Longer substitutions occur\n" +
 			"  before shorter ones. This implies the `--template` option.\n" +
 			"\n" +
+			"  `--create`\n" +
+			"\n" +
+			"  Set the `create` attribute on added files.\n" +
+			"\n" +
 			"  `-e`, `--empty`\n" +
 			"\n" +
 			"  Set the `empty` attribute on added files.\n" +
@@ -55,7 +59,32 @@ var helps = map[string]help{
 		long: "" +
 			"Description:\n" +
 			"  Ensure that *targets* are in the target state, updating them if necessary.\n" +
-			"  If no targets are specified, the state of all targets are ensured.",
+			"  If no targets are specified, the state of all targets are ensured.\n" +
+			"\n" +
+			"  chezmoi records the state of every file, directory, and symlink that it\n" +
+			"  writes in the `entryState` bucket of its persistent state. If a target has\n" +
+			"  been modified since chezmoi last wrote it, and it differs from the target\n" +
+			"  state, then `apply` and `edit --apply` handle it according to the\n" +
+			"  `modifiedPolicy` configuration variable:\n" +
+			"\n" +
+			"     POLICY   |             EFFECT\n" +
+			"  ------------+---------------------------------\n" +
+			"    overwrite | Overwrite the target without\n" +
+			"              | warning\n" +
+			"    prompt    | Prompt whether to overwrite\n" +
+			"              | the target\n" +
+			"    skip      | Print a warning and leave the\n" +
+			"              | target unchanged\n" +
+			"    warn      | Print a warning and overwrite\n" +
+			"              | the target, the default\n" +
+			"\n" +
+			"  The policy is not applied in dry run mode or by `diff`, `status`, and\n" +
+			"  `verify`, which always show the difference between the target and the target\n" +
+			"  state.\n" +
+			"\n" +
+			"  The state of `block_`, `merge_`, and `modify_` targets is not recorded, as\n" +
+			"  chezmoi only manages part of them, so changes to them by other programs are\n" +
+			"  never treated as modifications.",
 		example: "" +
 			"    chezmoi apply\n" +
 			"    chezmoi apply --dry-run --verbose\n" +
@@ -65,7 +94,7 @@ var helps = map[string]help{
 		long: "" +
 			"Description:\n" +
 			"  Generate a tar archive of the target state. This can be piped into `tar` to\n" +
-			"  inspect the target state.\n" +
+			"  inspect the target state. Encrypted scripts are not included.\n" +
 			"\n" +
 			"  `--output`, `-o` *filename*\n" +
 			"\n" +
@@ -77,9 +106,11 @@ var helps = map[string]help{
 	"cat": {
 		long: "" +
 			"Description:\n" +
-			"  Write the target state of *targets*  to stdout. *targets* must be files or\n" +
-			"  symlinks. For files, the target file contents are written. For symlinks, the\n" +
-			"  target target is written.",
+			"  Write the target state of *targets*  to stdout. *targets* must be files,\n" +
+			"  scripts, or symlinks. For files, the target file contents are written,\n" +
+			"  including the result of running modify scripts. For scripts, the script\n" +
+			"  contents are written, decrypted if the script is encrypted. For symlinks,\n" +
+			"  the target target is written.",
 		example: "" +
 			"    chezmoi cat ~/.bashrc",
 	},
@@ -104,6 +135,7 @@ var helps = map[string]help{
 			"\n" +
 			"    ATTRIBUTE  | ABBREVIATION\n" +
 			"  -------------+---------------\n" +
+			"    create     | none\n" +
 			"    empty      | e\n" +
 			"    encrypted  | none\n" +
 			"    exact      | none\n" +
@@ -112,7 +144,8 @@ var helps = map[string]help{
 			"    template   | t\n" +
 			"\n" +
 			"  Multiple attributes modifications may be specified by separating them with a\n" +
-			"  comma (`,`).",
+			"  comma (`,`). The `create` attribute cannot be added to `block_`, `merge_`,\n" +
+			"  or `modify_` files.",
 		example: "" +
 			"    chezmoi chattr template ~/.bashrc\n" +
 			"    chezmoi chattr noempty ~/.profile\n" +
@@ -139,8 +172,9 @@ var helps = map[string]help{
 			"\n" +
 			"  `-f`, `--format` *format*\n" +
 			"\n" +
-			"  Print the computed template data in the given format. The accepted formats\n" +
-			"  are `json` (JSON), `toml` (TOML), and `yaml` (YAML).",
+			"  Print the computed template data in the given format, as for the global `--\n" +
+			"  format` flag. The accepted formats are `json` (JSON, the default), `toml`\n" +
+			"  (TOML), and `yaml` (YAML).",
 		example: "" +
 			"    chezmoi data\n" +
 			"    chezmoi data --format=yaml",
@@ -157,8 +191,10 @@ var helps = map[string]help{
 			"\n" +
 			"  `-f`, `--format` *format*\n" +
 			"\n" +
-			"  Print the diff in *format*. The format can be set with the `diff.format`\n" +
-			"  variable in the configuration file. Valid formats are:\n" +
+			"  Print the diff in *format*. This flag overrides the global `--format` flag, so\n" +
+			"  `diff` does not support structured output and rejects `json`, `toml`, and\n" +
+			"  `yaml`. The format can be set with the `diff.format` variable in the\n" +
+			"  configuration file. Valid formats are:\n" +
 			"\n" +
 			"  ##### `chezmoi`\n" +
 			"\n" +
@@ -192,20 +228,29 @@ var helps = map[string]help{
 	"doctor": {
 		long: "" +
 			"Description:\n" +
-			"  Check for potential problems.",
+			"  Check for potential problems. With the global `--format` flag, print a record\n" +
+			"  for each check containing its name (`name`), its status (`ok`, `warning`, or\n" +
+			"  `error`), and its result (`result`).\n" +
+			"\n" +
+			"  If the source directory is a git repository with an upstream branch,\n" +
+			"  `doctor` warns if the current branch is ahead of or behind its upstream, for\n" +
+			"  example because local commits have not been pushed.",
 		example: "" +
-			"    chezmoi doctor",
+			"    chezmoi doctor\n" +
+			"    chezmoi doctor --format=json",
 	},
 	"dump": {
 		long: "" +
 			"Description:\n" +
 			"  Dump the target state in JSON format. If no targets are specified, then the\n" +
-			"  entire target state. The `dump` command accepts additional arguments:\n" +
+			"  entire target state. The contents of encrypted scripts are only included\n" +
+			"  when the scripts are explicitly given as *targets*. The `dump` command\n" +
+			"  accepts additional arguments:\n" +
 			"\n" +
 			"  `-f`, `--format` *format*\n" +
 			"\n" +
-			"  Print the target state in the given format. The accepted formats are `json`\n" +
-			"  (JSON) and `yaml` (YAML).",
+			"  Print the target state in the given format, as for the global `--format` flag.\n" +
+			"  The accepted formats are `json` (JSON, the default) and `yaml` (YAML).",
 		example: "" +
 			"    chezmoi dump ~/.bashrc\n" +
 			"    chezmoi dump --format=yaml",
@@ -382,7 +427,8 @@ var helps = map[string]help{
 			"    chezmoi managed --include=files\n" +
 			"    chezmoi managed --include=files,symlinks\n" +
 			"    chezmoi managed -i d\n" +
-			"    chezmoi managed -i d,f",
+			"    chezmoi managed -i d,f\n" +
+			"    chezmoi managed --format=yaml",
 	},
 	"merge": {
 		long: "" +
@@ -410,6 +456,21 @@ var helps = map[string]help{
 			"    chezmoi purge\n" +
 			"    chezmoi purge --force",
 	},
+	"re-add": {
+		long: "" +
+			"Description:\n" +
+			"  Re-add each of *targets* whose contents in the destination directory differ\n" +
+			"  from its target contents to the source state. If no targets are specified,\n" +
+			"  all managed files are re-added. The attributes of the files in the source\n" +
+			"  state are kept and encrypted files are re-encrypted. Templates are not re-\n" +
+			"  added, as this would replace the template with its output, and a warning is\n" +
+			"  printed instead.\n" +
+			"\n" +
+			"  `re-add` examples\n" +
+			"\n" +
+			"    chezmoi re-add\n" +
+			"    chezmoi re-add ~/.bashrc",
+	},
 	"remove": {
 		long: "" +
 			"Description:\n" +
@@ -453,11 +514,24 @@ var helps = map[string]help{
 			"Description:\n" +
 			"  Execute the source version control system in the source directory with\n" +
 			"  *args*. Note that any flags for the source version control system must be\n" +
-			"  separated with a `--` to stop chezmoi from reading them.",
+			"  separated with a `--` to stop chezmoi from reading them. The `source` command\n" +
+			"  has the following subcommand:\n" +
+			"\n" +
+			"  `source status`\n" +
+			"\n" +
+			"  Print the current branch of the source directory, its upstream, and how many\n" +
+			"  commits it is ahead of and behind its upstream, followed by the status of\n" +
+			"  each changed file in the source directory. Files are shown by their target\n" +
+			"  names where possible. With the global `--format` flag, print a record\n" +
+			"  containing the branch (`head`), its upstream (`upstream`), the `ahead` and\n" +
+			"  `behind` counts, and a list of `changes`. To run the source version control\n" +
+			"  system's own `status` command, use `chezmoi source -- status`.",
 		example: "" +
 			"    chezmoi source init\n" +
 			"    chezmoi source add .\n" +
-			"    chezmoi source commit -- -m \"Initial commit\"",
+			"    chezmoi source commit -- -m \"Initial commit\"\n" +
+			"    chezmoi source status\n" +
+			"    chezmoi source -- status --short",
 	},
 	"source-path": {
 		long: "" +
@@ -470,6 +544,118 @@ var helps = map[string]help{
 			"    chezmoi source-path\n" +
 			"    chezmoi source-path ~/.bashrc",
 	},
+	"state": {
+		long: "" +
+			"Description:\n" +
+			"  Manipulate the persistent state, which chezmoi uses to remember things such\n" +
+			"  as which `run_once_` and `run_onchange_` scripts have been run. The\n" +
+			"  persistent state is organized into buckets, each containing keys and values.\n" +
+			"  The state of scripts is stored in the `script` bucket.\n" +
+			"\n" +
+			"  The persistent state is stored using the backend set by the\n" +
+			"  `persistentState` configuration variable:\n" +
+			"\n" +
+			"    BACKEND |            STORAGE\n" +
+			"  ----------+---------------------------------\n" +
+			"    bolt    | chezmoistate.boltdb in the\n" +
+			"            | config directory, locked while\n" +
+			"            | chezmoi runs\n" +
+			"    json    | chezmoistate.json in the\n" +
+			"            | config directory, locked only\n" +
+			"            | while being updated\n" +
+			"    memory  | Memory only, discarded when\n" +
+			"            | chezmoi exits\n" +
+			"\n" +
+			"  Use the `json` backend if you need to run several instances of chezmoi at\n" +
+			"  the same time. Both the `bolt` and `json` backends give up if they cannot\n" +
+			"  take their lock within two seconds. In dry run mode, chezmoi uses an in-\n" +
+			"  memory snapshot of the persistent state so that it is never modified.\n" +
+			"\n" +
+			"  The `state` command has the following subcommands:\n" +
+			"\n" +
+			"    SUBCOMMAND |             EFFECT\n" +
+			"  -------------+---------------------------------\n" +
+			"    dump       | Write all buckets, or the\n" +
+			"               | bucket given with --bucket, to\n" +
+			"               | stdout\n" +
+			"    get        | Print the value of the key\n" +
+			"               | given with --key in the bucket\n" +
+			"               | --bucket\n" +
+			"    set        | Set the key given with --key\n" +
+			"               | in the bucket --bucket to\n" +
+			"               | --value\n" +
+			"    delete     | Delete the key given with\n" +
+			"               | --key from the bucket --bucket\n" +
+			"    reset      | Delete the bucket given with\n" +
+			"               | --bucket, or all buckets if\n" +
+			"               | not given\n" +
+			"    migrate    | Copy the persistent state from\n" +
+			"               | the --from backend to the --to\n" +
+			"               | backend\n" +
+			"\n" +
+			"  `--bucket` *bucket*\n" +
+			"\n" +
+			"  Operate on *bucket*.\n" +
+			"\n" +
+			"  `--from` *backend*, `--to` *backend*\n" +
+			"\n" +
+			"  For `migrate`, the backends to copy the persistent state from and to.\n" +
+			"\n" +
+			"  `-f`, `--format` *format*\n" +
+			"\n" +
+			"  For `dump`, print the persistent state in the given format, as for the\n" +
+			"  global `--format` flag. The accepted formats are `json` (JSON, the default),\n" +
+			"  `toml` (TOML), and `yaml` (YAML).\n" +
+			"\n" +
+			"  `-f`, `--force`\n" +
+			"\n" +
+			"  For `reset`, reset without prompting.",
+		example: "" +
+			"    chezmoi state dump\n" +
+			"    chezmoi state dump --bucket=script --format=yaml\n" +
+			"    chezmoi state get --bucket=script --key=install-packages.sh\n" +
+			"    chezmoi state delete --bucket=script --key=install-packages.sh\n" +
+			"    chezmoi state reset --bucket=script\n" +
+			"    chezmoi state migrate --from=bolt --to=json",
+	},
+	"status": {
+		long: "" +
+			"Description:\n" +
+			"  Print a summary of the changes that `chezmoi apply` would make to each\n" +
+			"  target, without making them. If no targets are specified, the status of all\n" +
+			"  targets is printed. Each changed target is printed on a line with a two-\n" +
+			"  letter status code followed by its path relative to the destination\n" +
+			"  directory, similar to `git status --short`.\n" +
+			"\n" +
+			"  The first column is the difference between the target's actual state and the\n" +
+			"  state that chezmoi last wrote. The second column is the change that `chezmoi\n" +
+			"  apply` would make. The status codes are:\n" +
+			"\n" +
+			"    CODE |          FIRST COLUMN          |    SECOND COLUMN\n" +
+			"  -------+--------------------------------+----------------------\n" +
+			"         | No change, or chezmoi has not  | No change\n" +
+			"         | written it                     |\n" +
+			"    A    |                                | Added\n" +
+			"    D    | Deleted since chezmoi last     | Deleted\n" +
+			"         | wrote it                       |\n" +
+			"    M    | Modified since chezmoi last    | Modified\n" +
+			"         | wrote it                       |\n" +
+			"    P    |                                | Permissions changed\n" +
+			"    R    |                                | Script will be run\n" +
+			"\n" +
+			"  Targets listed in `.chezmoiremove` are only shown as deleted if the `--remove`\n" +
+			"  option is given, as with `apply`.\n" +
+			"\n" +
+			"  `-f`, `--format` *format*\n" +
+			"\n" +
+			"  Print the status in the given format, as for the global `--format` flag. The\n" +
+			"  accepted formats are `json` (JSON) and `yaml` (YAML). Each entry has a\n" +
+			"  `path` and a `status`.",
+		example: "" +
+			"    chezmoi status\n" +
+			"    chezmoi status ~/.bashrc\n" +
+			"    chezmoi status --format=json",
+	},
 	"unmanage": {
 		long: "" +
 			"Description:\n" +
@@ -480,14 +666,29 @@ var helps = map[string]help{
 			"Description:\n" +
 			"  List all unmanaged files in the destination directory.",
 		example: "" +
-			"    chezmoi unmanaged",
+			"    chezmoi unmanaged\n" +
+			"    chezmoi unmanaged --format=json",
 	},
 	"update": {
 		long: "" +
 			"Description:\n" +
-			"  Pull changes from the source VCS and apply any changes.",
+			"  Pull changes from the source VCS and apply any changes. If the source VCS is\n" +
+			"  git, then `update` refuses to pull if the source directory has uncommitted\n" +
+			"  changes to tracked files or if its branch has diverged from its upstream.\n" +
+			"  The `update` command accepts additional arguments:\n" +
+			"\n" +
+			"  `-a`, `--apply`\n" +
+			"\n" +
+			"  Apply changes after pulling. This is the default. Use `--apply=false` to only\n" +
+			"  pull.\n" +
+			"\n" +
+			"  `-f`, `--force`\n" +
+			"\n" +
+			"  Pull even if the source directory has uncommitted changes or has diverged\n" +
+			"  from its upstream.",
 		example: "" +
-			"    chezmoi update",
+			"    chezmoi update\n" +
+			"    chezmoi update --force",
 	},
 	"upgrade": {
 		long: "" +
@@ -515,9 +716,23 @@ var helps = map[string]help{
 			"Description:\n" +
 			"  Verify that all *targets* match their target state. chezmoi exits with code\n" +
 			"  0 (success) if all targets match their target state, or 1 (failure)\n" +
-			"  otherwise. If no targets are specified then all targets are checked.",
+			"  otherwise. If no targets are specified then all targets are checked.\n" +
+			"\n" +
+			"  With the global `--format` flag, print a record for each target that does not\n" +
+			"  match its target state, with a `reason` that is one of:\n" +
+			"\n" +
+			"      REASON    |            MEANING\n" +
+			"  --------------+---------------------------------\n" +
+			"    missing     | The target does not exist\n" +
+			"    modified    | The target's contents or type\n" +
+			"                | differ\n" +
+			"    permissions | The target's permissions\n" +
+			"                | differ\n" +
+			"    unexpected  | The target exists but should\n" +
+			"                | not, e.g. in an exact dir",
 		example: "" +
 			"    chezmoi verify\n" +
+			"    chezmoi verify --format=json\n" +
 			"    chezmoi verify ~/.bashrc",
 	},
 }
@@ -4,26 +4,28 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+
+	"github.com/twpayne/chezmoi/internal/hg"
 )
 
 var hgVersionRegexp = regexp.MustCompile(`^Mercurial Distributed SCM \(version (\d+\.\d+(\.\d+)?\))`)
 
 type hgVCS struct{}
 
-func (hgVCS) AddArgs(path string) []string {
-	return nil
+func (hgVCS) AddArgs(path string) ([]string, error) {
+	return []string{"addremove", path}, nil
 }
 
-func (hgVCS) CloneArgs(repo, dir string) []string {
-	return []string{"clone", repo, dir}
+func (hgVCS) CloneArgs(repo, dir string) ([]string, error) {
+	return []string{"clone", repo, dir}, nil
 }
 
-func (hgVCS) CommitArgs(message string) []string {
-	return nil
+func (hgVCS) CommitArgs(message string) ([]string, error) {
+	return []string{"commit", "--message", message}, nil
 }
 
-func (hgVCS) InitArgs() []string {
-	return []string{"init"}
+func (hgVCS) InitArgs() ([]string, error) {
+	return []string{"init"}, nil
 }
 
 func (hgVCS) Initialized(dir string) (bool, error) {
@@ -39,23 +41,23 @@ func (hgVCS) Initialized(dir string) (bool, error) {
 }
 
 func (hgVCS) ParseStatusOutput(output []byte) (interface{}, error) {
-	return nil, nil
+	return hg.ParseStatus(output)
 }
 
-func (hgVCS) PullArgs() []string {
-	return []string{"pull", "--update"}
+func (hgVCS) PullArgs() ([]string, error) {
+	return []string{"pull", "--update"}, nil
 }
 
-func (hgVCS) PushArgs() []string {
-	return nil
+func (hgVCS) PushArgs() ([]string, error) {
+	return []string{"push"}, nil
 }
 
-func (hgVCS) StatusArgs() []string {
-	return nil
+func (hgVCS) StatusArgs() ([]string, error) {
+	return []string{"status", "--copies"}, nil
 }
 
-func (hgVCS) VersionArgs() []string {
-	return []string{"version"}
+func (hgVCS) VersionArgs() ([]string, error) {
+	return []string{"version"}, nil
 }
 
 func (hgVCS) VersionRegexp() *regexp.Regexp {
@@ -74,6 +74,8 @@ func (c *Config) runImportCmd(cmd *cobra.Command, args []string) error {
 	if c._import.removeDestination {
 		entry, err := ts.Get(c.fs, c._import.importTAROptions.DestinationDir)
 		switch {
+		case err == nil && ts.IsExternal(entry.TargetName()):
+			return fmt.Errorf("%s: part of an external declared in %s", c._import.importTAROptions.DestinationDir, filepath.Join(ts.SourceDir, entry.SourceName()))
 		case err == nil:
 			if err := c.mutator.RemoveAll(filepath.Join(c.SourceDir, entry.SourceName())); err != nil {
 				return err
@@ -56,7 +56,20 @@ func (c *Config) runInitCmd(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	if !initialized {
+	builtinVCS, builtin := vcs.(builtinVCS)
+	if !initialized && builtin && (len(args) == 1 || c.SourceVCS.Init == nil) {
+		if !c.DryRun {
+			switch len(args) {
+			case 0: // init
+				err = builtinVCS.Init(rawSourceDir)
+			case 1: // clone
+				err = builtinVCS.Clone(args[0], rawSourceDir)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	} else if !initialized {
 		switch len(args) {
 		case 0: // init
 			var initArgs []string
@@ -70,13 +83,22 @@ func (c *Config) runInitCmd(cmd *cobra.Command, args []string) error {
 					return fmt.Errorf("sourceVCS.init: cannot parse value")
 				}
 			} else {
-				initArgs = vcs.InitArgs()
+				initArgs, err = vcs.InitArgs()
+				if err != nil {
+					return err
+				}
+				if initArgs == nil {
+					return fmt.Errorf("%s: init not supported", c.SourceVCS.Command)
+				}
 			}
 			if err := c.run(c.SourceDir, c.SourceVCS.Command, initArgs...); err != nil {
 				return err
 			}
 		case 1: // clone
-			cloneArgs := vcs.CloneArgs(args[0], rawSourceDir)
+			cloneArgs, err := vcs.CloneArgs(args[0], rawSourceDir)
+			if err != nil {
+				return err
+			}
 			if cloneArgs == nil {
 				return fmt.Errorf("%s: cloning not supported", c.SourceVCS.Command)
 			}
@@ -32,6 +32,11 @@ func init() {
 }
 
 func (c *Config) runManagedCmd(cmd *cobra.Command, args []string) error {
+	format, err := c.getFormat()
+	if err != nil {
+		return err
+	}
+
 	ts, err := c.getTargetState(nil)
 	if err != nil {
 		return err
@@ -55,9 +60,12 @@ func (c *Config) runManagedCmd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	allEntries := ts.AllEntries()
+	allEntries, err := ts.AllEntries()
+	if err != nil {
+		return err
+	}
 
-	targetNames := make([]string, 0, len(allEntries))
+	entries := make([]chezmoi.Entry, 0, len(allEntries))
 	for _, entry := range allEntries {
 		if _, ok := entry.(*chezmoi.Dir); ok && !includeDirs {
 			continue
@@ -68,15 +76,28 @@ func (c *Config) runManagedCmd(cmd *cobra.Command, args []string) error {
 		if _, ok := entry.(*chezmoi.Symlink); ok && !includeSymlinks {
 			continue
 		}
-		targetNames = append(targetNames, entry.TargetName())
+		ignoreName := entry.TargetName()
+		if _, ok := entry.(*chezmoi.Dir); ok {
+			ignoreName += "/"
+		}
+		if ts.TargetIgnore.Match(ignoreName) {
+			continue
+		}
+		entries = append(entries, entry)
 	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].TargetName() < entries[j].TargetName()
+	})
 
-	sort.Strings(targetNames)
-	for _, targetName := range targetNames {
-		if ts.TargetIgnore.Match(targetName) {
-			continue
+	if format != nil {
+		records := make([]*entryRecord, 0, len(entries))
+		for _, entry := range entries {
+			records = append(records, newEntryRecord(ts, entry))
 		}
-		fmt.Fprintln(c.Stdout, filepath.Join(ts.DestDir, targetName))
+		return format(c.Stdout, records)
+	}
+	for _, entry := range entries {
+		fmt.Fprintln(c.Stdout, filepath.Join(ts.DestDir, entry.TargetName()))
 	}
 
 	return nil
@@ -40,8 +40,10 @@ func (c *Config) runPurgeCmd(cmd *cobra.Command, args []string) error {
 		}
 	}
 	paths = append(paths,
+		c.CacheDir,
 		c.configFile,
-		c.getPersistentStateFile(),
+		c.getPersistentStateFile("bolt"),
+		c.getPersistentStateFile("json"),
 		c.SourceDir,
 	)
 
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/twpayne/chezmoi/internal/chezmoi"
+)
+
+var reAddCmd = &cobra.Command{
+	Use:      "re-add [targets...]",
+	Short:    "Re-add modified files to the source state",
+	Long:     mustGetLongHelp("re-add"),
+	Example:  getExample("re-add"),
+	PreRunE:  config.ensureNoError,
+	RunE:     config.runReAddCmd,
+	PostRunE: config.autoCommitAndAutoPush,
+}
+
+func init() {
+	rootCmd.AddCommand(reAddCmd)
+
+	markRemainingZshCompPositionalArgumentsAsFiles(reAddCmd, 1)
+}
+
+func (c *Config) runReAddCmd(cmd *cobra.Command, args []string) error {
+	ts, err := c.getTargetState(nil)
+	if err != nil {
+		return err
+	}
+
+	var entries []chezmoi.Entry
+	if len(args) == 0 {
+		entries, err = ts.AllEntries()
+		if err != nil {
+			return err
+		}
+	} else {
+		entries, err = c.getEntries(ts, args)
+		if err != nil {
+			return err
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].TargetName() < entries[j].TargetName()
+	})
+
+	persistentState, err := c.getPersistentState(nil)
+	if err != nil {
+		return err
+	}
+	defer persistentState.Close()
+
+	for _, entry := range entries {
+		file, ok := entry.(*chezmoi.File)
+		// The source of a block_, merge_, or modify_ file is not its contents,
+		// and create_ files are expected to diverge from their source.
+		if !ok || file.Block || file.Create || file.Merge || file.Modify || ts.TargetIgnore.Match(file.TargetName()) || ts.IsExternal(file.TargetName()) {
+			continue
+		}
+		targetPath := filepath.Join(ts.DestDir, file.TargetName())
+		info, err := c.fs.Lstat(targetPath)
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return err
+		case !info.Mode().IsRegular():
+			continue
+		}
+		contents, err := c.fs.ReadFile(targetPath)
+		if err != nil {
+			return err
+		}
+		targetContents, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(contents, targetContents) {
+			continue
+		}
+
+		// Re-adding a template would replace it with its output, so leave it
+		// for the user to update by hand.
+		if file.Template {
+			if _, err := fmt.Fprintf(c.Stderr, "warning: %s: is a template, skipping\n", targetPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		sourcePath := filepath.Join(ts.SourceDir, file.SourceName())
+		oldSourceContents, err := c.fs.ReadFile(sourcePath)
+		if err != nil {
+			return err
+		}
+		sourceContents := contents
+		if file.Encrypted {
+			sourceContents, err = ts.Encryption.Encrypt(targetPath, contents)
+			if err != nil {
+				return err
+			}
+		}
+		if err := c.mutator.WriteFile(sourcePath, sourceContents, 0o666&^ts.Umask, oldSourceContents); err != nil {
+			return err
+		}
+
+		// The target now matches the source state, so record its state so
+		// that it is not reported as modified when it is next applied.
+		entryState, err := chezmoi.NewEntryState(c.fs, targetPath, false)
+		if err != nil {
+			return err
+		}
+		entryStateData, err := json.Marshal(entryState)
+		if err != nil {
+			return err
+		}
+		if err := persistentState.Set(c.entryStateBucket, []byte(targetPath), entryStateData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
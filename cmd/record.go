@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/twpayne/chezmoi/internal/chezmoi"
+)
+
+// An entryRecord is a machine-readable description of a target, printed by
+// commands when the global --format flag is set.
+type entryRecord struct {
+	TargetPath string   `json:"targetPath" yaml:"targetPath"`
+	SourcePath string   `json:"sourcePath,omitempty" yaml:"sourcePath,omitempty"`
+	Type       string   `json:"type" yaml:"type"`
+	Attributes []string `json:"attributes" yaml:"attributes"`
+	Reason     string   `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// newEntryRecord returns a new entryRecord describing entry in ts.
+func newEntryRecord(ts *chezmoi.TargetState, entry chezmoi.Entry) *entryRecord {
+	var entryType string
+	attributes := []string{}
+	addAttribute := func(attribute string, ok bool) {
+		if ok {
+			attributes = append(attributes, attribute)
+		}
+	}
+	switch entry := entry.(type) {
+	case *chezmoi.Dir:
+		entryType = "dir"
+		addAttribute("exact", entry.Exact)
+		addAttribute("private", entry.Private())
+	case *chezmoi.File:
+		entryType = "file"
+		addAttribute("block", entry.Block)
+		addAttribute("create", entry.Create)
+		addAttribute("empty", entry.Empty)
+		addAttribute("encrypted", entry.Encrypted)
+		addAttribute("executable", entry.Executable())
+		addAttribute("merge", entry.Merge)
+		addAttribute("modify", entry.Modify)
+		addAttribute("private", entry.Private())
+		addAttribute("template", entry.Template)
+	case *chezmoi.Script:
+		entryType = "script"
+		addAttribute("after", entry.After)
+		addAttribute("before", entry.Before)
+		addAttribute("encrypted", entry.Encrypted)
+		addAttribute("once", entry.Once)
+		addAttribute("onchange", entry.OnChange)
+		addAttribute("template", entry.Template)
+	case *chezmoi.Symlink:
+		entryType = "symlink"
+		addAttribute("template", entry.Template)
+	}
+	return &entryRecord{
+		TargetPath: filepath.Join(ts.DestDir, entry.TargetName()),
+		SourcePath: filepath.Join(ts.SourceDir, entry.SourceName()),
+		Type:       entryType,
+		Attributes: attributes,
+	}
+}
+
+// newUnmanagedEntryRecord returns a new entryRecord describing the unmanaged
+// target at path with info.
+func newUnmanagedEntryRecord(path string, info os.FileInfo) *entryRecord {
+	var entryType string
+	switch {
+	case info.IsDir():
+		entryType = "dir"
+	case info.Mode().IsRegular():
+		entryType = "file"
+	case info.Mode()&os.ModeType == os.ModeSymlink:
+		entryType = "symlink"
+	default:
+		entryType = "other"
+	}
+	return &entryRecord{
+		TargetPath: path,
+		Type:       entryType,
+		Attributes: []string{},
+	}
+}
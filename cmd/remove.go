@@ -38,9 +38,9 @@ func (c *Config) runRemoveCmd(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	entries, err := c.getEntries(ts, args)
+	entries, err := c.getSourceEntries(ts, args)
 	if err != nil {
-		return nil
+		return err
 	}
 	for _, entry := range entries {
 		destDirPath := filepath.Join(c.DestDir, entry.TargetName())
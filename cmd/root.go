@@ -58,18 +58,26 @@ func init() {
 
 	persistentFlags := rootCmd.PersistentFlags()
 
+	persistentFlags.StringVar(&config.CacheDir, "cache", filepath.Join(config.bds.CacheHome, "chezmoi"), "cache directory")
+	panicOnError(viper.BindPFlag("cache", persistentFlags.Lookup("cache")))
+	panicOnError(rootCmd.MarkPersistentFlagDirname("cache"))
+
 	persistentFlags.StringVarP(&config.configFile, "config", "c", getDefaultConfigFile(config.bds), "config file")
 	panicOnError(rootCmd.MarkPersistentFlagFilename("config"))
 
 	persistentFlags.BoolVarP(&config.DryRun, "dry-run", "n", false, "dry run")
 	panicOnError(viper.BindPFlag("dry-run", persistentFlags.Lookup("dry-run")))
 
+	persistentFlags.StringVar(&config.format, "format", "", "output format (JSON or YAML)")
+
 	persistentFlags.BoolVar(&config.Follow, "follow", false, "follow symlinks")
 	panicOnError(viper.BindPFlag("follow", persistentFlags.Lookup("follow")))
 
 	persistentFlags.BoolVar(&config.Remove, "remove", false, "remove targets")
 	panicOnError(viper.BindPFlag("remove", persistentFlags.Lookup("remove")))
 
+	persistentFlags.BoolVarP(&config.refreshExternals, "refresh-externals", "R", false, "refresh external archives and files")
+
 	persistentFlags.StringVarP(&config.SourceDir, "source", "S", getDefaultSourceDir(config.bds), "source directory")
 	panicOnError(viper.BindPFlag("source", persistentFlags.Lookup("source")))
 	panicOnError(rootCmd.MarkPersistentFlagDirname("source"))
@@ -99,6 +107,9 @@ func init() {
 			if config.err == nil {
 				config.err = config.validateData()
 			}
+			if config.err == nil {
+				config.err = config.validateSourceVCS()
+			}
 			if config.err != nil {
 				rootCmd.Printf("warning: %s: %v\n", config.configFile, config.err)
 			}
@@ -108,7 +119,7 @@ func init() {
 					"warning: to disable this warning, set gpg.recipient in your config file instead\n",
 				)
 			}
-			if config.SourceVCS.Command != "" && !config.SourceVCS.NotGit && !strings.Contains(filepath.Base(config.SourceVCS.Command), "git") {
+			if config.SourceVCS.Command != "" && !config.SourceVCS.NotGit && config.SourceVCS.Driver == nil && !strings.Contains(filepath.Base(config.SourceVCS.Command), "git") {
 				rootCmd.Printf("" +
 					"warning: it looks like you are using a version control system that is not git which will be deprecated in v2\n" +
 					"warning: please report this at https://github.com/twpayne/chezmoi/issues/459\n" +
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/twpayne/chezmoi/internal/git"
+)
+
+var sourceStatusCmd = &cobra.Command{
+	Use:     "status",
+	Args:    cobra.NoArgs,
+	Short:   "Show the branch and changes of the source directory",
+	PreRunE: config.ensureNoError,
+	RunE:    config.runSourceStatusCmd,
+}
+
+// A sourceStatusRecord is a machine-readable description of the status of the
+// source directory.
+type sourceStatusRecord struct {
+	Head     string                      `json:"head,omitempty" yaml:"head,omitempty"`
+	Upstream string                      `json:"upstream,omitempty" yaml:"upstream,omitempty"`
+	Ahead    int                         `json:"ahead" yaml:"ahead"`
+	Behind   int                         `json:"behind" yaml:"behind"`
+	Changes  []*sourceStatusChangeRecord `json:"changes" yaml:"changes"`
+}
+
+// A sourceStatusChangeRecord is a machine-readable description of a change in
+// the source directory.
+type sourceStatusChangeRecord struct {
+	Status         string `json:"status" yaml:"status"`
+	SourcePath     string `json:"sourcePath" yaml:"sourcePath"`
+	OrigSourcePath string `json:"origSourcePath,omitempty" yaml:"origSourcePath,omitempty"`
+	TargetName     string `json:"targetName,omitempty" yaml:"targetName,omitempty"`
+}
+
+func init() {
+	sourceCmd.AddCommand(sourceStatusCmd)
+}
+
+func (c *Config) runSourceStatusCmd(cmd *cobra.Command, args []string) error {
+	format, err := c.getFormat()
+	if err != nil {
+		return err
+	}
+
+	vcs, err := c.getVCS()
+	if err != nil {
+		return err
+	}
+	status, err := c.getSourceVCSStatus(vcs)
+	if err != nil {
+		return err
+	}
+
+	var record *sourceStatusRecord
+	switch status := status.(type) {
+	case nil:
+		record = newSourceStatusRecord(&git.Status{})
+	case string:
+		if format != nil {
+			return fmt.Errorf("%s: status cannot be formatted", c.SourceVCS.Command)
+		}
+		_, err := fmt.Fprint(c.Stdout, status)
+		return err
+	case *git.Status:
+		record = newSourceStatusRecord(status)
+	default:
+		return fmt.Errorf("%s: unsupported status %T", c.SourceVCS.Command, status)
+	}
+
+	if format != nil {
+		return format(c.Stdout, record)
+	}
+
+	if record.Head != "" {
+		branch := record.Head
+		if record.Upstream != "" {
+			branch += "..." + record.Upstream
+		}
+		var counts []string
+		if record.Ahead != 0 {
+			counts = append(counts, fmt.Sprintf("ahead %d", record.Ahead))
+		}
+		if record.Behind != 0 {
+			counts = append(counts, fmt.Sprintf("behind %d", record.Behind))
+		}
+		if len(counts) != 0 {
+			branch += " [" + strings.Join(counts, ", ") + "]"
+		}
+		fmt.Fprintf(c.Stdout, "## %s\n", branch)
+	}
+	for _, change := range record.Changes {
+		name := change.TargetName
+		if name == "" {
+			name = change.SourcePath
+		}
+		fmt.Fprintf(c.Stdout, "%s %s\n", change.Status, name)
+	}
+	return nil
+}
+
+// newSourceStatusRecord returns a new sourceStatusRecord describing status.
+// Each change's status is in git's short format, and its target name is empty
+// if its source path is not the source of a target.
+func newSourceStatusRecord(status *git.Status) *sourceStatusRecord {
+	record := &sourceStatusRecord{
+		Changes: []*sourceStatusChangeRecord{},
+	}
+	if status.Branch != nil {
+		record.Head = status.Branch.Head
+		record.Upstream = status.Branch.Upstream
+		record.Ahead = status.Branch.Ahead
+		record.Behind = status.Branch.Behind
+	}
+	shortStatus := func(x, y byte) string {
+		return strings.ReplaceAll(string([]byte{x, y}), ".", " ")
+	}
+	for _, s := range status.Ordinary {
+		record.Changes = append(record.Changes, &sourceStatusChangeRecord{
+			Status:     shortStatus(s.X, s.Y),
+			SourcePath: s.Path,
+			TargetName: newCommitMessageTarget(s.Path).TargetName,
+		})
+	}
+	for _, s := range status.RenamedOrCopied {
+		record.Changes = append(record.Changes, &sourceStatusChangeRecord{
+			Status:         shortStatus(s.X, s.Y),
+			SourcePath:     s.Path,
+			OrigSourcePath: s.OrigPath,
+			TargetName:     newCommitMessageTarget(s.Path).TargetName,
+		})
+	}
+	for _, s := range status.Unmerged {
+		record.Changes = append(record.Changes, &sourceStatusChangeRecord{
+			Status:     shortStatus(s.X, s.Y),
+			SourcePath: s.Path,
+			TargetName: newCommitMessageTarget(s.Path).TargetName,
+		})
+	}
+	for _, s := range status.Untracked {
+		record.Changes = append(record.Changes, &sourceStatusChangeRecord{
+			Status:     "??",
+			SourcePath: s.Path,
+			TargetName: newCommitMessageTarget(s.Path).TargetName,
+		})
+	}
+	for _, s := range status.Ignored {
+		record.Changes = append(record.Changes, &sourceStatusChangeRecord{
+			Status:     "!!",
+			SourcePath: s.Path,
+			TargetName: newCommitMessageTarget(s.Path).TargetName,
+		})
+	}
+	return record
+}
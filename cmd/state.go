@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+var stateCmd = &cobra.Command{
+	Use:     "state",
+	Args:    cobra.NoArgs,
+	Short:   "Manipulate the persistent state",
+	Long:    mustGetLongHelp("state"),
+	Example: getExample("state"),
+}
+
+type stateCmdConfig struct {
+	bucket string
+	key    string
+	value  string
+	force  bool
+	from   string
+	to     string
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+
+	persistentFlags := stateCmd.PersistentFlags()
+	persistentFlags.StringVar(&config.state.bucket, "bucket", "", "bucket")
+}
+
+// ensureBucket returns an error if no bucket is set.
+func (sc *stateCmdConfig) ensureBucket() error {
+	if sc.bucket == "" {
+		return errors.New("--bucket not set")
+	}
+	return nil
+}
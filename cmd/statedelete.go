@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var stateDeleteCmd = &cobra.Command{
+	Use:     "delete",
+	Args:    cobra.NoArgs,
+	Short:   "Delete a value from the persistent state",
+	PreRunE: config.ensureNoError,
+	RunE:    config.runStateDeleteCmd,
+}
+
+func init() {
+	stateCmd.AddCommand(stateDeleteCmd)
+
+	persistentFlags := stateDeleteCmd.PersistentFlags()
+	persistentFlags.StringVar(&config.state.key, "key", "", "key")
+	panicOnError(stateDeleteCmd.MarkPersistentFlagRequired("key"))
+}
+
+func (c *Config) runStateDeleteCmd(cmd *cobra.Command, args []string) error {
+	if err := c.state.ensureBucket(); err != nil {
+		return err
+	}
+	persistentState, err := c.getPersistentState(nil)
+	if err != nil {
+		return err
+	}
+	defer persistentState.Close()
+
+	return persistentState.Delete([]byte(c.state.bucket), []byte(c.state.key))
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+var stateDumpCmd = &cobra.Command{
+	Use:     "dump",
+	Args:    cobra.NoArgs,
+	Short:   "Write a dump of the persistent state to stdout",
+	PreRunE: config.ensureNoError,
+	RunE:    config.runStateDumpCmd,
+}
+
+func init() {
+	stateCmd.AddCommand(stateDumpCmd)
+
+	persistentFlags := stateDumpCmd.PersistentFlags()
+	persistentFlags.StringVarP(&config.format, "format", "f", "", "format (JSON, TOML, or YAML, default JSON)")
+}
+
+func (c *Config) runStateDumpCmd(cmd *cobra.Command, args []string) error {
+	format, err := c.getDataFormat()
+	if err != nil {
+		return err
+	}
+	persistentState, err := c.getPersistentState(&bolt.Options{
+		ReadOnly: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer persistentState.Close()
+
+	data, err := persistentState.Data()
+	if err != nil {
+		return err
+	}
+
+	// Decode values that are JSON so that they are dumped as structured data,
+	// and dump all other values as strings.
+	dump := make(map[string]map[string]interface{})
+	for bucket, bucketData := range data {
+		if c.state.bucket != "" && bucket != c.state.bucket {
+			continue
+		}
+		bucketDump := make(map[string]interface{})
+		for key, value := range bucketData {
+			var jsonValue interface{}
+			if err := json.Unmarshal(value, &jsonValue); err == nil {
+				bucketDump[key] = jsonValue
+			} else {
+				bucketDump[key] = string(value)
+			}
+		}
+		dump[bucket] = bucketDump
+	}
+	return format(c.Stdout, dump)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/twpayne/chezmoi/internal/chezmoi"
+)
+
+var stateMigrateCmd = &cobra.Command{
+	Use:     "migrate",
+	Args:    cobra.NoArgs,
+	Short:   "Copy the persistent state from one backend to another",
+	PreRunE: config.ensureNoError,
+	RunE:    config.runStateMigrateCmd,
+}
+
+func init() {
+	stateCmd.AddCommand(stateMigrateCmd)
+
+	persistentFlags := stateMigrateCmd.PersistentFlags()
+	persistentFlags.StringVar(&config.state.from, "from", "", "source backend")
+	panicOnError(stateMigrateCmd.MarkPersistentFlagRequired("from"))
+	persistentFlags.StringVar(&config.state.to, "to", "", "destination backend")
+	panicOnError(stateMigrateCmd.MarkPersistentFlagRequired("to"))
+}
+
+func (c *Config) runStateMigrateCmd(cmd *cobra.Command, args []string) error {
+	if c.state.from == c.state.to {
+		return fmt.Errorf("cannot migrate from %s to itself", c.state.from)
+	}
+	src, err := c.openPersistentState(c.state.from, &bolt.Options{
+		ReadOnly: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if c.DryRun {
+		return nil
+	}
+	dst, err := c.openPersistentState(c.state.to, nil)
+	if err != nil {
+		return err
+	}
+	if err := chezmoi.CopyPersistentState(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
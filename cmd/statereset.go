@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+var stateResetCmd = &cobra.Command{
+	Use:     "reset",
+	Args:    cobra.NoArgs,
+	Short:   "Reset the persistent state",
+	PreRunE: config.ensureNoError,
+	RunE:    config.runStateResetCmd,
+}
+
+func init() {
+	stateCmd.AddCommand(stateResetCmd)
+
+	persistentFlags := stateResetCmd.PersistentFlags()
+	persistentFlags.BoolVarP(&config.state.force, "force", "f", false, "reset without prompting")
+}
+
+func (c *Config) runStateResetCmd(cmd *cobra.Command, args []string) error {
+	persistentState, err := c.getPersistentState(nil)
+	if err != nil {
+		return err
+	}
+	defer persistentState.Close()
+
+	// Build a list of buckets to delete.
+	var buckets []string
+	if c.state.bucket != "" {
+		buckets = []string{c.state.bucket}
+	} else {
+		data, err := persistentState.Data()
+		if err != nil {
+			return err
+		}
+		for bucket := range data {
+			buckets = append(buckets, bucket)
+		}
+		sort.Strings(buckets)
+	}
+
+	for _, bucket := range buckets {
+		if !c.state.force {
+			choice, err := c.prompt(fmt.Sprintf("Reset bucket %s", bucket), "ynqa")
+			if err != nil {
+				return err
+			}
+			switch choice {
+			case 'a':
+				c.state.force = true
+			case 'n':
+				continue
+			case 'q':
+				return nil
+			}
+		}
+		if err := persistentState.DeleteBucket([]byte(bucket)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var stateSetCmd = &cobra.Command{
+	Use:     "set",
+	Args:    cobra.NoArgs,
+	Short:   "Set a value in the persistent state",
+	PreRunE: config.ensureNoError,
+	RunE:    config.runStateSetCmd,
+}
+
+func init() {
+	stateCmd.AddCommand(stateSetCmd)
+
+	persistentFlags := stateSetCmd.PersistentFlags()
+	persistentFlags.StringVar(&config.state.key, "key", "", "key")
+	panicOnError(stateSetCmd.MarkPersistentFlagRequired("key"))
+	persistentFlags.StringVar(&config.state.value, "value", "", "value")
+	panicOnError(stateSetCmd.MarkPersistentFlagRequired("value"))
+}
+
+func (c *Config) runStateSetCmd(cmd *cobra.Command, args []string) error {
+	if err := c.state.ensureBucket(); err != nil {
+		return err
+	}
+	persistentState, err := c.getPersistentState(nil)
+	if err != nil {
+		return err
+	}
+	defer persistentState.Close()
+
+	return persistentState.Set([]byte(c.state.bucket), []byte(c.state.key), []byte(c.state.value))
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/twpayne/chezmoi/internal/chezmoi"
+)
+
+var statusCmd = &cobra.Command{
+	Use:     "status [targets...]",
+	Short:   "Show the status of targets",
+	Long:    mustGetLongHelp("status"),
+	Example: getExample("status"),
+	PreRunE: config.ensureNoError,
+	RunE:    config.runStatusCmd,
+}
+
+// A statusEntry is the status of a single target.
+type statusEntry struct {
+	Path   string `json:"path" yaml:"path"`
+	Status string `json:"status" yaml:"status"`
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	persistentFlags := statusCmd.PersistentFlags()
+	persistentFlags.StringVarP(&config.format, "format", "f", "", "format (JSON or YAML)")
+
+	markRemainingZshCompPositionalArgumentsAsFiles(statusCmd, 1)
+}
+
+func (c *Config) runStatusCmd(cmd *cobra.Command, args []string) error {
+	format, err := c.getFormat()
+	if err != nil {
+		return err
+	}
+
+	destDir, err := filepath.Abs(c.DestDir)
+	if err != nil {
+		return err
+	}
+
+	// Record the changes that apply would make without making them, and
+	// without stopping at targets that have been modified since chezmoi last
+	// wrote them.
+	c.DryRun = true
+	c.ModifiedPolicy = "overwrite"
+	statusMutator := chezmoi.NewStatusMutator(c.fs)
+	c.mutator = statusMutator
+	c.dryRunScript = statusMutator.RecordScript
+
+	persistentState, err := c.getPersistentState(&bolt.Options{
+		ReadOnly: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer persistentState.Close()
+
+	// Take a copy of the entry states that chezmoi last wrote before applying,
+	// as applying records new entry states.
+	persistentStateData, err := persistentState.Data()
+	if err != nil {
+		return err
+	}
+	entryStateData := persistentStateData[string(c.entryStateBucket)]
+
+	if err := c.applyArgs(args, persistentState); err != nil {
+		return err
+	}
+
+	var statusEntries []statusEntry
+	for path, targetStatus := range statusMutator.Statuses() {
+		relPath, err := filepath.Rel(destDir, path)
+		if err != nil {
+			return err
+		}
+		actualStatus, err := c.getActualStatus(path, entryStateData[path])
+		if err != nil {
+			return err
+		}
+		statusEntries = append(statusEntries, statusEntry{
+			Path:   relPath,
+			Status: string([]byte{actualStatus, targetStatus}),
+		})
+	}
+	sort.Slice(statusEntries, func(i, j int) bool {
+		return statusEntries[i].Path < statusEntries[j].Path
+	})
+
+	if format != nil {
+		if statusEntries == nil {
+			statusEntries = []statusEntry{}
+		}
+		return format(c.Stdout, statusEntries)
+	}
+	for _, statusEntry := range statusEntries {
+		if _, err := fmt.Fprintf(c.Stdout, "%s %s\n", statusEntry.Status, statusEntry.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getActualStatus returns the status code of the entry at path compared to the
+// state that chezmoi last wrote, given by entryStateData. It returns a space if
+// chezmoi has no record of writing the entry or if it is unchanged.
+func (c *Config) getActualStatus(path string, entryStateData []byte) (byte, error) {
+	if entryStateData == nil {
+		return ' ', nil
+	}
+	var lastWrittenEntryState chezmoi.EntryState
+	if err := json.Unmarshal(entryStateData, &lastWrittenEntryState); err != nil {
+		return 0, err
+	}
+	actualEntryState, err := chezmoi.NewEntryState(c.fs, path, c.Follow)
+	switch {
+	case err != nil:
+		return 0, err
+	case actualEntryState == nil:
+		return chezmoi.StatusDeleted, nil
+	case *actualEntryState != lastWrittenEntryState:
+		return chezmoi.StatusModified, nil
+	default:
+		return ' ', nil
+	}
+}
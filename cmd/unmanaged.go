@@ -25,11 +25,17 @@ func init() {
 }
 
 func (c *Config) runUnmanagedCmd(cmd *cobra.Command, args []string) error {
+	format, err := c.getFormat()
+	if err != nil {
+		return err
+	}
+
 	ts, err := c.getTargetState(nil)
 	if err != nil {
 		return err
 	}
-	return vfs.Walk(c.fs, c.DestDir, func(path string, info os.FileInfo, err error) error {
+	records := []*entryRecord{}
+	if err := vfs.Walk(c.fs, c.DestDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -38,13 +44,28 @@ func (c *Config) runUnmanagedCmd(cmd *cobra.Command, args []string) error {
 		}
 		entry, _ := ts.Get(c.fs, path)
 		managed := entry != nil
-		ignored := ts.TargetIgnore.Match(strings.TrimPrefix(path, c.DestDir+"/"))
+		ignoreName := strings.TrimPrefix(path, c.DestDir+"/")
+		if info.IsDir() {
+			ignoreName += "/"
+		}
+		ignored := ts.TargetIgnore.Match(ignoreName)
 		if !managed && !ignored {
-			fmt.Println(path)
+			if format != nil {
+				records = append(records, newUnmanagedEntryRecord(path, info))
+			} else {
+				fmt.Fprintln(c.Stdout, path)
+			}
 		}
 		if info.IsDir() && (!managed || ignored) {
 			return filepath.SkipDir
 		}
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+
+	if format != nil {
+		return format(c.Stdout, records)
+	}
+	return nil
 }
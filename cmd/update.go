@@ -5,10 +5,13 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/twpayne/chezmoi/internal/git"
 )
 
 type updateCmdConfig struct {
 	apply bool
+	force bool
 }
 
 var updateCmd = &cobra.Command{
@@ -26,6 +29,7 @@ func init() {
 
 	persistentFlags := updateCmd.PersistentFlags()
 	persistentFlags.BoolVarP(&config.update.apply, "apply", "a", true, "apply after pulling")
+	persistentFlags.BoolVarP(&config.update.force, "force", "f", false, "pull even if the source directory is dirty or has diverged")
 }
 
 func (c *Config) runUpdateCmd(cmd *cobra.Command, args []string) error {
@@ -33,24 +37,12 @@ func (c *Config) runUpdateCmd(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	var pullArgs []string
-	if c.SourceVCS.Pull != nil {
-		switch v := c.SourceVCS.Pull.(type) {
-		case string:
-			pullArgs = strings.Split(v, " ")
-		case []string:
-			pullArgs = v
-		default:
-			return fmt.Errorf("sourceVCS.pull: cannot parse value")
+	if !c.update.force {
+		if err := c.checkSourceVCSStatus(vcs); err != nil {
+			return err
 		}
-	} else {
-		pullArgs = vcs.PullArgs()
-	}
-	if pullArgs == nil {
-		return fmt.Errorf("%s: pull not supported", c.SourceVCS.Command)
 	}
-
-	if err := c.run(c.SourceDir, c.SourceVCS.Command, pullArgs...); err != nil {
+	if err := c.pullSourceVCS(vcs); err != nil {
 		return err
 	}
 
@@ -67,3 +59,71 @@ func (c *Config) runUpdateCmd(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// checkSourceVCSStatus returns an error if the source directory has
+// uncommitted changes to tracked files or has diverged from its upstream. VCSs
+// that cannot report a parsed status are not checked.
+func (c *Config) checkSourceVCSStatus(vcs VCS) error {
+	if _, ok := vcs.(builtinVCS); !ok {
+		statusArgs, err := vcs.StatusArgs()
+		if err != nil {
+			return err
+		}
+		if statusArgs == nil {
+			return nil
+		}
+	}
+	status, err := c.getSourceVCSStatus(vcs)
+	if err != nil {
+		return err
+	}
+	gitStatus, ok := status.(*git.Status)
+	if !ok {
+		return nil
+	}
+	switch {
+	case gitStatus.Dirty():
+		return fmt.Errorf("%s: source directory has uncommitted changes, use --force to pull anyway", c.SourceDir)
+	case gitStatus.Branch.Diverged():
+		return fmt.Errorf("%s: source directory has diverged from %s (ahead %d, behind %d), use --force to pull anyway", c.SourceDir, gitStatus.Branch.Upstream, gitStatus.Branch.Ahead, gitStatus.Branch.Behind)
+	default:
+		return nil
+	}
+}
+
+// pullSourceVCS pulls changes into the source directory with vcs.
+func (c *Config) pullSourceVCS(vcs VCS) error {
+	if builtinVCS, ok := vcs.(builtinVCS); ok && c.SourceVCS.Pull == nil {
+		if c.DryRun {
+			return nil
+		}
+		rawSourceDir, err := c.fs.RawPath(c.SourceDir)
+		if err != nil {
+			return err
+		}
+		return builtinVCS.Pull(rawSourceDir)
+	}
+
+	var pullArgs []string
+	if c.SourceVCS.Pull != nil {
+		switch v := c.SourceVCS.Pull.(type) {
+		case string:
+			pullArgs = strings.Split(v, " ")
+		case []string:
+			pullArgs = v
+		default:
+			return fmt.Errorf("sourceVCS.pull: cannot parse value")
+		}
+	} else {
+		var err error
+		pullArgs, err = vcs.PullArgs()
+		if err != nil {
+			return err
+		}
+	}
+	if pullArgs == nil {
+		return fmt.Errorf("%s: pull not supported", c.SourceVCS.Command)
+	}
+
+	return c.run(c.SourceDir, c.SourceVCS.Command, pullArgs...)
+}
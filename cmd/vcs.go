@@ -1,22 +1,39 @@
 package cmd
 
-import "regexp"
+import (
+	"regexp"
+
+	"github.com/twpayne/chezmoi/internal/git"
+)
 
 // A VCS is a version control system.
 type VCS interface {
-	AddArgs(string) []string
-	CloneArgs(string, string) []string
-	CommitArgs(string) []string
-	InitArgs() []string
+	AddArgs(string) ([]string, error)
+	CloneArgs(string, string) ([]string, error)
+	CommitArgs(string) ([]string, error)
+	InitArgs() ([]string, error)
 	Initialized(string) (bool, error)
 	ParseStatusOutput([]byte) (interface{}, error)
-	PullArgs() []string
-	PushArgs() []string
-	StatusArgs() []string
-	VersionArgs() []string
+	PullArgs() ([]string, error)
+	PushArgs() ([]string, error)
+	StatusArgs() ([]string, error)
+	VersionArgs() ([]string, error)
 	VersionRegexp() *regexp.Regexp
 }
 
+// A builtinVCS is a VCS whose operations run in-process rather than by running
+// the source VCS command. dir is the raw path of the repository.
+type builtinVCS interface {
+	VCS
+	Add(dir, path string) error
+	Clone(repo, dir string) error
+	Commit(dir, message string) error
+	Init(dir string) error
+	Pull(dir string) error
+	Push(dir string) error
+	Status(dir string) (*git.Status, error)
+}
+
 var vcses = map[string]VCS{
 	"git": gitVCS{},
 	"hg":  hgVCS{},
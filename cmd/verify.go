@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"sort"
+
 	"github.com/spf13/cobra"
 	bolt "go.etcd.io/bbolt"
 
@@ -16,6 +18,14 @@ var verifyCmd = &cobra.Command{
 	RunE:    config.runVerifyCmd,
 }
 
+// verifyReasons maps status codes to the reasons that verify reports.
+var verifyReasons = map[byte]string{
+	chezmoi.StatusAdded:      "missing",
+	chezmoi.StatusDeleted:    "unexpected",
+	chezmoi.StatusModified:   "modified",
+	chezmoi.StatusPermission: "permissions",
+}
+
 func init() {
 	rootCmd.AddCommand(verifyCmd)
 
@@ -23,8 +33,14 @@ func init() {
 }
 
 func (c *Config) runVerifyCmd(cmd *cobra.Command, args []string) error {
-	mutator := chezmoi.NewAnyMutator(chezmoi.NullMutator{})
-	c.mutator = mutator
+	format, err := c.getFormat()
+	if err != nil {
+		return err
+	}
+
+	c.DryRun = true // Prevent scripts from running and the persistent state from being modified.
+	statusMutator := chezmoi.NewStatusMutator(c.fs)
+	c.mutator = statusMutator
 
 	persistentState, err := c.getPersistentState(&bolt.Options{
 		ReadOnly: true,
@@ -34,10 +50,45 @@ func (c *Config) runVerifyCmd(cmd *cobra.Command, args []string) error {
 	}
 	defer persistentState.Close()
 
-	if err := c.applyArgs(args, persistentState); err != nil {
+	ts, err := c.getTargetState(nil)
+	if err != nil {
 		return err
 	}
-	if mutator.Mutated() {
+	if err := c.applyTargetStateArgs(ts, args, persistentState); err != nil {
+		return err
+	}
+
+	records := []*entryRecord{}
+	for path, status := range statusMutator.Statuses() {
+		// Scripts that would run do not make the destination state differ.
+		reason, ok := verifyReasons[status]
+		if !ok {
+			continue
+		}
+		var record *entryRecord
+		if entry, _ := ts.Get(c.fs, path); entry != nil {
+			record = newEntryRecord(ts, entry)
+		} else if info, err := c.fs.Lstat(path); err == nil {
+			record = newUnmanagedEntryRecord(path, info)
+		} else {
+			record = &entryRecord{
+				TargetPath: path,
+				Attributes: []string{},
+			}
+		}
+		record.Reason = reason
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].TargetPath < records[j].TargetPath
+	})
+
+	if format != nil {
+		if err := format(c.Stdout, records); err != nil {
+			return err
+		}
+	}
+	if len(records) != 0 {
 		return errExitFailure
 	}
 	return nil
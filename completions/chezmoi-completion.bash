@@ -369,6 +369,7 @@ _chezmoi_add()
 
     flags+=("--autotemplate")
     flags+=("-a")
+    flags+=("--create")
     flags+=("--empty")
     flags+=("-e")
     flags+=("--encrypt")
@@ -382,6 +383,10 @@ _chezmoi_add()
     flags+=("-r")
     flags+=("--template")
     flags+=("-T")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -402,6 +407,10 @@ _chezmoi_add()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -432,6 +441,10 @@ _chezmoi_apply()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -452,6 +465,10 @@ _chezmoi_apply()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -489,6 +506,10 @@ _chezmoi_archive()
     two_word_flags+=("-o")
     flags_with_completion+=("-o")
     flags_completion+=("_filedir")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -509,6 +530,10 @@ _chezmoi_archive()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -539,6 +564,10 @@ _chezmoi_cat()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -559,6 +588,10 @@ _chezmoi_cat()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -589,6 +622,10 @@ _chezmoi_cd()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -609,6 +646,10 @@ _chezmoi_cd()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -639,6 +680,10 @@ _chezmoi_chattr()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -659,6 +704,10 @@ _chezmoi_chattr()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -700,6 +749,10 @@ _chezmoi_completion()
     two_word_flags+=("-o")
     flags_with_completion+=("-o")
     flags_completion+=("_filedir")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -720,6 +773,10 @@ _chezmoi_completion()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -753,9 +810,10 @@ _chezmoi_data()
     flags_with_completion=()
     flags_completion=()
 
-    flags+=("--format=")
-    two_word_flags+=("--format")
-    two_word_flags+=("-f")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -776,6 +834,10 @@ _chezmoi_data()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -806,10 +868,11 @@ _chezmoi_diff()
     flags_with_completion=()
     flags_completion=()
 
-    flags+=("--format=")
-    two_word_flags+=("--format")
-    two_word_flags+=("-f")
     flags+=("--no-pager")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -830,6 +893,10 @@ _chezmoi_diff()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -860,6 +927,10 @@ _chezmoi_docs()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -880,6 +951,10 @@ _chezmoi_docs()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -910,6 +985,10 @@ _chezmoi_doctor()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -930,6 +1009,10 @@ _chezmoi_doctor()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -960,11 +1043,12 @@ _chezmoi_dump()
     flags_with_completion=()
     flags_completion=()
 
-    flags+=("--format=")
-    two_word_flags+=("--format")
-    two_word_flags+=("-f")
     flags+=("--recursive")
     flags+=("-r")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -985,6 +1069,10 @@ _chezmoi_dump()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1021,6 +1109,10 @@ _chezmoi_edit()
     flags+=("-d")
     flags+=("--prompt")
     flags+=("-p")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1041,6 +1133,10 @@ _chezmoi_edit()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1071,6 +1167,10 @@ _chezmoi_edit-config()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1091,6 +1191,10 @@ _chezmoi_edit-config()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1129,6 +1233,10 @@ _chezmoi_execute-template()
     flags+=("--promptString=")
     two_word_flags+=("--promptString")
     two_word_flags+=("-p")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1149,6 +1257,10 @@ _chezmoi_execute-template()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1179,6 +1291,10 @@ _chezmoi_forget()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1199,6 +1315,10 @@ _chezmoi_forget()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1229,6 +1349,10 @@ _chezmoi_git()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1249,6 +1373,10 @@ _chezmoi_git()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1279,6 +1407,10 @@ _chezmoi_help()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1299,6 +1431,10 @@ _chezmoi_help()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1329,6 +1465,10 @@ _chezmoi_hg()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1349,6 +1489,10 @@ _chezmoi_hg()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1385,6 +1529,10 @@ _chezmoi_import()
     flags+=("-r")
     flags+=("--strip-components=")
     two_word_flags+=("--strip-components")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1405,6 +1553,10 @@ _chezmoi_import()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1436,6 +1588,10 @@ _chezmoi_init()
     flags_completion=()
 
     flags+=("--apply")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1456,6 +1612,10 @@ _chezmoi_init()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1489,6 +1649,10 @@ _chezmoi_managed()
     flags+=("--include=")
     two_word_flags+=("--include")
     two_word_flags+=("-i")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1509,6 +1673,10 @@ _chezmoi_managed()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1539,6 +1707,10 @@ _chezmoi_merge()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1559,6 +1731,10 @@ _chezmoi_merge()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1591,6 +1767,68 @@ _chezmoi_purge()
 
     flags+=("--force")
     flags+=("-f")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
+    flags+=("--color=")
+    two_word_flags+=("--color")
+    flags+=("--config=")
+    two_word_flags+=("--config")
+    flags_with_completion+=("--config")
+    flags_completion+=("_filedir")
+    two_word_flags+=("-c")
+    flags_with_completion+=("-c")
+    flags_completion+=("_filedir")
+    flags+=("--debug")
+    flags+=("--destination=")
+    two_word_flags+=("--destination")
+    flags_with_completion+=("--destination")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-D")
+    flags_with_completion+=("-D")
+    flags_completion+=("_filedir -d")
+    flags+=("--dry-run")
+    flags+=("-n")
+    flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
+    flags+=("--remove")
+    flags+=("--source=")
+    two_word_flags+=("--source")
+    flags_with_completion+=("--source")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-S")
+    flags_with_completion+=("-S")
+    flags_completion+=("_filedir -d")
+    flags+=("--verbose")
+    flags+=("-v")
+
+    must_have_one_flag=()
+    must_have_one_noun=()
+    noun_aliases=()
+}
+
+_chezmoi_re-add()
+{
+    last_command="chezmoi_re-add"
+
+    command_aliases=()
+
+    commands=()
+
+    flags=()
+    two_word_flags=()
+    local_nonpersistent_flags=()
+    flags_with_completion=()
+    flags_completion=()
+
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1611,6 +1849,10 @@ _chezmoi_purge()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1643,6 +1885,10 @@ _chezmoi_remove()
 
     flags+=("--force")
     flags+=("-f")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1663,6 +1909,10 @@ _chezmoi_remove()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1693,6 +1943,10 @@ _chezmoi_secret_bitwarden()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1713,6 +1967,10 @@ _chezmoi_secret_bitwarden()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1743,6 +2001,10 @@ _chezmoi_secret_generic()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1763,6 +2025,10 @@ _chezmoi_secret_generic()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1793,6 +2059,10 @@ _chezmoi_secret_gopass()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1813,6 +2083,10 @@ _chezmoi_secret_gopass()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1843,6 +2117,10 @@ _chezmoi_secret_keepassxc()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1863,6 +2141,10 @@ _chezmoi_secret_keepassxc()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -1893,6 +2175,10 @@ _chezmoi_secret_keyring_get()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1913,6 +2199,10 @@ _chezmoi_secret_keyring_get()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--service=")
     two_word_flags+=("--service")
@@ -1949,6 +2239,10 @@ _chezmoi_secret_keyring_set()
 
     flags+=("--password=")
     two_word_flags+=("--password")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -1969,6 +2263,10 @@ _chezmoi_secret_keyring_set()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--service=")
     two_word_flags+=("--service")
@@ -2009,6 +2307,10 @@ _chezmoi_secret_keyring()
     two_word_flags+=("--service")
     flags+=("--user=")
     two_word_flags+=("--user")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2029,6 +2331,10 @@ _chezmoi_secret_keyring()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2061,6 +2367,10 @@ _chezmoi_secret_lastpass()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2081,6 +2391,10 @@ _chezmoi_secret_lastpass()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2111,6 +2425,10 @@ _chezmoi_secret_onepassword()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2131,6 +2449,10 @@ _chezmoi_secret_onepassword()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2161,6 +2483,10 @@ _chezmoi_secret_pass()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2181,6 +2507,10 @@ _chezmoi_secret_pass()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2211,6 +2541,10 @@ _chezmoi_secret_vault()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2231,6 +2565,10 @@ _chezmoi_secret_vault()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2270,6 +2608,10 @@ _chezmoi_secret()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2290,6 +2632,10 @@ _chezmoi_secret()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2306,9 +2652,9 @@ _chezmoi_secret()
     noun_aliases=()
 }
 
-_chezmoi_source()
+_chezmoi_source_status()
 {
-    last_command="chezmoi_source"
+    last_command="chezmoi_source_status"
 
     command_aliases=()
 
@@ -2320,6 +2666,10 @@ _chezmoi_source()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2340,6 +2690,10 @@ _chezmoi_source()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2356,13 +2710,14 @@ _chezmoi_source()
     noun_aliases=()
 }
 
-_chezmoi_source-path()
+_chezmoi_source()
 {
-    last_command="chezmoi_source-path"
+    last_command="chezmoi_source"
 
     command_aliases=()
 
     commands=()
+    commands+=("status")
 
     flags=()
     two_word_flags=()
@@ -2370,6 +2725,10 @@ _chezmoi_source-path()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2390,6 +2749,10 @@ _chezmoi_source-path()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2406,9 +2769,9 @@ _chezmoi_source-path()
     noun_aliases=()
 }
 
-_chezmoi_unmanaged()
+_chezmoi_source-path()
 {
-    last_command="chezmoi_unmanaged"
+    last_command="chezmoi_source-path"
 
     command_aliases=()
 
@@ -2420,6 +2783,10 @@ _chezmoi_unmanaged()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2440,6 +2807,10 @@ _chezmoi_unmanaged()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2456,9 +2827,9 @@ _chezmoi_unmanaged()
     noun_aliases=()
 }
 
-_chezmoi_update()
+_chezmoi_state_delete()
 {
-    last_command="chezmoi_update"
+    last_command="chezmoi_state_delete"
 
     command_aliases=()
 
@@ -2470,8 +2841,14 @@ _chezmoi_update()
     flags_with_completion=()
     flags_completion=()
 
-    flags+=("--apply")
-    flags+=("-a")
+    flags+=("--key=")
+    two_word_flags+=("--key")
+    flags+=("--bucket=")
+    two_word_flags+=("--bucket")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2492,6 +2869,10 @@ _chezmoi_update()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2504,13 +2885,14 @@ _chezmoi_update()
     flags+=("-v")
 
     must_have_one_flag=()
+    must_have_one_flag+=("--key=")
     must_have_one_noun=()
     noun_aliases=()
 }
 
-_chezmoi_upgrade()
+_chezmoi_state_dump()
 {
-    last_command="chezmoi_upgrade"
+    last_command="chezmoi_state_dump"
 
     command_aliases=()
 
@@ -2522,17 +2904,12 @@ _chezmoi_upgrade()
     flags_with_completion=()
     flags_completion=()
 
-    flags+=("--force")
-    flags+=("-f")
-    flags+=("--method=")
-    two_word_flags+=("--method")
-    two_word_flags+=("-m")
-    flags+=("--owner=")
-    two_word_flags+=("--owner")
-    two_word_flags+=("-o")
-    flags+=("--repo=")
-    two_word_flags+=("--repo")
-    two_word_flags+=("-r")
+    flags+=("--bucket=")
+    two_word_flags+=("--bucket")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2553,6 +2930,10 @@ _chezmoi_upgrade()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2569,9 +2950,9 @@ _chezmoi_upgrade()
     noun_aliases=()
 }
 
-_chezmoi_verify()
+_chezmoi_state_get()
 {
-    last_command="chezmoi_verify"
+    last_command="chezmoi_state_get"
 
     command_aliases=()
 
@@ -2583,6 +2964,14 @@ _chezmoi_verify()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--key=")
+    two_word_flags+=("--key")
+    flags+=("--bucket=")
+    two_word_flags+=("--bucket")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2603,6 +2992,10 @@ _chezmoi_verify()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -2615,57 +3008,626 @@ _chezmoi_verify()
     flags+=("-v")
 
     must_have_one_flag=()
+    must_have_one_flag+=("--key=")
     must_have_one_noun=()
     noun_aliases=()
 }
 
-_chezmoi_root_command()
+_chezmoi_state_migrate()
 {
-    last_command="chezmoi"
+    last_command="chezmoi_state_migrate"
 
     command_aliases=()
 
     commands=()
-    commands+=("add")
-    if [[ -z "${BASH_VERSION}" || "${BASH_VERSINFO[0]}" -gt 3 ]]; then
-        command_aliases+=("manage")
-        aliashash["manage"]="add"
-    fi
-    commands+=("apply")
-    commands+=("archive")
-    commands+=("cat")
-    commands+=("cd")
-    commands+=("chattr")
-    commands+=("completion")
-    commands+=("data")
-    commands+=("diff")
-    commands+=("docs")
-    commands+=("doctor")
-    commands+=("dump")
-    commands+=("edit")
-    commands+=("edit-config")
-    commands+=("execute-template")
-    commands+=("forget")
-    if [[ -z "${BASH_VERSION}" || "${BASH_VERSINFO[0]}" -gt 3 ]]; then
-        command_aliases+=("unmanage")
-        aliashash["unmanage"]="forget"
-    fi
-    commands+=("git")
-    commands+=("help")
-    commands+=("hg")
-    commands+=("import")
-    commands+=("init")
-    commands+=("managed")
-    commands+=("merge")
-    commands+=("purge")
-    commands+=("remove")
-    if [[ -z "${BASH_VERSION}" || "${BASH_VERSINFO[0]}" -gt 3 ]]; then
-        command_aliases+=("rm")
-        aliashash["rm"]="remove"
-    fi
-    commands+=("secret")
-    commands+=("source")
+
+    flags=()
+    two_word_flags=()
+    local_nonpersistent_flags=()
+    flags_with_completion=()
+    flags_completion=()
+
+    flags+=("--from=")
+    two_word_flags+=("--from")
+    flags+=("--to=")
+    two_word_flags+=("--to")
+    flags+=("--bucket=")
+    two_word_flags+=("--bucket")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
+    flags+=("--color=")
+    two_word_flags+=("--color")
+    flags+=("--config=")
+    two_word_flags+=("--config")
+    flags_with_completion+=("--config")
+    flags_completion+=("_filedir")
+    two_word_flags+=("-c")
+    flags_with_completion+=("-c")
+    flags_completion+=("_filedir")
+    flags+=("--debug")
+    flags+=("--destination=")
+    two_word_flags+=("--destination")
+    flags_with_completion+=("--destination")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-D")
+    flags_with_completion+=("-D")
+    flags_completion+=("_filedir -d")
+    flags+=("--dry-run")
+    flags+=("-n")
+    flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
+    flags+=("--remove")
+    flags+=("--source=")
+    two_word_flags+=("--source")
+    flags_with_completion+=("--source")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-S")
+    flags_with_completion+=("-S")
+    flags_completion+=("_filedir -d")
+    flags+=("--verbose")
+    flags+=("-v")
+
+    must_have_one_flag=()
+    must_have_one_flag+=("--from=")
+    must_have_one_flag+=("--to=")
+    must_have_one_noun=()
+    noun_aliases=()
+}
+
+_chezmoi_state_reset()
+{
+    last_command="chezmoi_state_reset"
+
+    command_aliases=()
+
+    commands=()
+
+    flags=()
+    two_word_flags=()
+    local_nonpersistent_flags=()
+    flags_with_completion=()
+    flags_completion=()
+
+    flags+=("--force")
+    flags+=("-f")
+    flags+=("--bucket=")
+    two_word_flags+=("--bucket")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
+    flags+=("--color=")
+    two_word_flags+=("--color")
+    flags+=("--config=")
+    two_word_flags+=("--config")
+    flags_with_completion+=("--config")
+    flags_completion+=("_filedir")
+    two_word_flags+=("-c")
+    flags_with_completion+=("-c")
+    flags_completion+=("_filedir")
+    flags+=("--debug")
+    flags+=("--destination=")
+    two_word_flags+=("--destination")
+    flags_with_completion+=("--destination")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-D")
+    flags_with_completion+=("-D")
+    flags_completion+=("_filedir -d")
+    flags+=("--dry-run")
+    flags+=("-n")
+    flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
+    flags+=("--remove")
+    flags+=("--source=")
+    two_word_flags+=("--source")
+    flags_with_completion+=("--source")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-S")
+    flags_with_completion+=("-S")
+    flags_completion+=("_filedir -d")
+    flags+=("--verbose")
+    flags+=("-v")
+
+    must_have_one_flag=()
+    must_have_one_noun=()
+    noun_aliases=()
+}
+
+_chezmoi_state_set()
+{
+    last_command="chezmoi_state_set"
+
+    command_aliases=()
+
+    commands=()
+
+    flags=()
+    two_word_flags=()
+    local_nonpersistent_flags=()
+    flags_with_completion=()
+    flags_completion=()
+
+    flags+=("--key=")
+    two_word_flags+=("--key")
+    flags+=("--value=")
+    two_word_flags+=("--value")
+    flags+=("--bucket=")
+    two_word_flags+=("--bucket")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
+    flags+=("--color=")
+    two_word_flags+=("--color")
+    flags+=("--config=")
+    two_word_flags+=("--config")
+    flags_with_completion+=("--config")
+    flags_completion+=("_filedir")
+    two_word_flags+=("-c")
+    flags_with_completion+=("-c")
+    flags_completion+=("_filedir")
+    flags+=("--debug")
+    flags+=("--destination=")
+    two_word_flags+=("--destination")
+    flags_with_completion+=("--destination")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-D")
+    flags_with_completion+=("-D")
+    flags_completion+=("_filedir -d")
+    flags+=("--dry-run")
+    flags+=("-n")
+    flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
+    flags+=("--remove")
+    flags+=("--source=")
+    two_word_flags+=("--source")
+    flags_with_completion+=("--source")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-S")
+    flags_with_completion+=("-S")
+    flags_completion+=("_filedir -d")
+    flags+=("--verbose")
+    flags+=("-v")
+
+    must_have_one_flag=()
+    must_have_one_flag+=("--key=")
+    must_have_one_flag+=("--value=")
+    must_have_one_noun=()
+    noun_aliases=()
+}
+
+_chezmoi_state()
+{
+    last_command="chezmoi_state"
+
+    command_aliases=()
+
+    commands=()
+    commands+=("delete")
+    commands+=("dump")
+    commands+=("get")
+    commands+=("migrate")
+    commands+=("reset")
+    commands+=("set")
+
+    flags=()
+    two_word_flags=()
+    local_nonpersistent_flags=()
+    flags_with_completion=()
+    flags_completion=()
+
+    flags+=("--bucket=")
+    two_word_flags+=("--bucket")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
+    flags+=("--color=")
+    two_word_flags+=("--color")
+    flags+=("--config=")
+    two_word_flags+=("--config")
+    flags_with_completion+=("--config")
+    flags_completion+=("_filedir")
+    two_word_flags+=("-c")
+    flags_with_completion+=("-c")
+    flags_completion+=("_filedir")
+    flags+=("--debug")
+    flags+=("--destination=")
+    two_word_flags+=("--destination")
+    flags_with_completion+=("--destination")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-D")
+    flags_with_completion+=("-D")
+    flags_completion+=("_filedir -d")
+    flags+=("--dry-run")
+    flags+=("-n")
+    flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
+    flags+=("--remove")
+    flags+=("--source=")
+    two_word_flags+=("--source")
+    flags_with_completion+=("--source")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-S")
+    flags_with_completion+=("-S")
+    flags_completion+=("_filedir -d")
+    flags+=("--verbose")
+    flags+=("-v")
+
+    must_have_one_flag=()
+    must_have_one_noun=()
+    noun_aliases=()
+}
+
+_chezmoi_status()
+{
+    last_command="chezmoi_status"
+
+    command_aliases=()
+
+    commands=()
+
+    flags=()
+    two_word_flags=()
+    local_nonpersistent_flags=()
+    flags_with_completion=()
+    flags_completion=()
+
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
+    flags+=("--color=")
+    two_word_flags+=("--color")
+    flags+=("--config=")
+    two_word_flags+=("--config")
+    flags_with_completion+=("--config")
+    flags_completion+=("_filedir")
+    two_word_flags+=("-c")
+    flags_with_completion+=("-c")
+    flags_completion+=("_filedir")
+    flags+=("--debug")
+    flags+=("--destination=")
+    two_word_flags+=("--destination")
+    flags_with_completion+=("--destination")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-D")
+    flags_with_completion+=("-D")
+    flags_completion+=("_filedir -d")
+    flags+=("--dry-run")
+    flags+=("-n")
+    flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
+    flags+=("--remove")
+    flags+=("--source=")
+    two_word_flags+=("--source")
+    flags_with_completion+=("--source")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-S")
+    flags_with_completion+=("-S")
+    flags_completion+=("_filedir -d")
+    flags+=("--verbose")
+    flags+=("-v")
+
+    must_have_one_flag=()
+    must_have_one_noun=()
+    noun_aliases=()
+}
+
+_chezmoi_unmanaged()
+{
+    last_command="chezmoi_unmanaged"
+
+    command_aliases=()
+
+    commands=()
+
+    flags=()
+    two_word_flags=()
+    local_nonpersistent_flags=()
+    flags_with_completion=()
+    flags_completion=()
+
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
+    flags+=("--color=")
+    two_word_flags+=("--color")
+    flags+=("--config=")
+    two_word_flags+=("--config")
+    flags_with_completion+=("--config")
+    flags_completion+=("_filedir")
+    two_word_flags+=("-c")
+    flags_with_completion+=("-c")
+    flags_completion+=("_filedir")
+    flags+=("--debug")
+    flags+=("--destination=")
+    two_word_flags+=("--destination")
+    flags_with_completion+=("--destination")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-D")
+    flags_with_completion+=("-D")
+    flags_completion+=("_filedir -d")
+    flags+=("--dry-run")
+    flags+=("-n")
+    flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
+    flags+=("--remove")
+    flags+=("--source=")
+    two_word_flags+=("--source")
+    flags_with_completion+=("--source")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-S")
+    flags_with_completion+=("-S")
+    flags_completion+=("_filedir -d")
+    flags+=("--verbose")
+    flags+=("-v")
+
+    must_have_one_flag=()
+    must_have_one_noun=()
+    noun_aliases=()
+}
+
+_chezmoi_update()
+{
+    last_command="chezmoi_update"
+
+    command_aliases=()
+
+    commands=()
+
+    flags=()
+    two_word_flags=()
+    local_nonpersistent_flags=()
+    flags_with_completion=()
+    flags_completion=()
+
+    flags+=("--apply")
+    flags+=("-a")
+    flags+=("--force")
+    flags+=("-f")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
+    flags+=("--color=")
+    two_word_flags+=("--color")
+    flags+=("--config=")
+    two_word_flags+=("--config")
+    flags_with_completion+=("--config")
+    flags_completion+=("_filedir")
+    two_word_flags+=("-c")
+    flags_with_completion+=("-c")
+    flags_completion+=("_filedir")
+    flags+=("--debug")
+    flags+=("--destination=")
+    two_word_flags+=("--destination")
+    flags_with_completion+=("--destination")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-D")
+    flags_with_completion+=("-D")
+    flags_completion+=("_filedir -d")
+    flags+=("--dry-run")
+    flags+=("-n")
+    flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
+    flags+=("--remove")
+    flags+=("--source=")
+    two_word_flags+=("--source")
+    flags_with_completion+=("--source")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-S")
+    flags_with_completion+=("-S")
+    flags_completion+=("_filedir -d")
+    flags+=("--verbose")
+    flags+=("-v")
+
+    must_have_one_flag=()
+    must_have_one_noun=()
+    noun_aliases=()
+}
+
+_chezmoi_upgrade()
+{
+    last_command="chezmoi_upgrade"
+
+    command_aliases=()
+
+    commands=()
+
+    flags=()
+    two_word_flags=()
+    local_nonpersistent_flags=()
+    flags_with_completion=()
+    flags_completion=()
+
+    flags+=("--force")
+    flags+=("-f")
+    flags+=("--method=")
+    two_word_flags+=("--method")
+    two_word_flags+=("-m")
+    flags+=("--owner=")
+    two_word_flags+=("--owner")
+    two_word_flags+=("-o")
+    flags+=("--repo=")
+    two_word_flags+=("--repo")
+    two_word_flags+=("-r")
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
+    flags+=("--color=")
+    two_word_flags+=("--color")
+    flags+=("--config=")
+    two_word_flags+=("--config")
+    flags_with_completion+=("--config")
+    flags_completion+=("_filedir")
+    two_word_flags+=("-c")
+    flags_with_completion+=("-c")
+    flags_completion+=("_filedir")
+    flags+=("--debug")
+    flags+=("--destination=")
+    two_word_flags+=("--destination")
+    flags_with_completion+=("--destination")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-D")
+    flags_with_completion+=("-D")
+    flags_completion+=("_filedir -d")
+    flags+=("--dry-run")
+    flags+=("-n")
+    flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
+    flags+=("--remove")
+    flags+=("--source=")
+    two_word_flags+=("--source")
+    flags_with_completion+=("--source")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-S")
+    flags_with_completion+=("-S")
+    flags_completion+=("_filedir -d")
+    flags+=("--verbose")
+    flags+=("-v")
+
+    must_have_one_flag=()
+    must_have_one_noun=()
+    noun_aliases=()
+}
+
+_chezmoi_verify()
+{
+    last_command="chezmoi_verify"
+
+    command_aliases=()
+
+    commands=()
+
+    flags=()
+    two_word_flags=()
+    local_nonpersistent_flags=()
+    flags_with_completion=()
+    flags_completion=()
+
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
+    flags+=("--color=")
+    two_word_flags+=("--color")
+    flags+=("--config=")
+    two_word_flags+=("--config")
+    flags_with_completion+=("--config")
+    flags_completion+=("_filedir")
+    two_word_flags+=("-c")
+    flags_with_completion+=("-c")
+    flags_completion+=("_filedir")
+    flags+=("--debug")
+    flags+=("--destination=")
+    two_word_flags+=("--destination")
+    flags_with_completion+=("--destination")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-D")
+    flags_with_completion+=("-D")
+    flags_completion+=("_filedir -d")
+    flags+=("--dry-run")
+    flags+=("-n")
+    flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
+    flags+=("--remove")
+    flags+=("--source=")
+    two_word_flags+=("--source")
+    flags_with_completion+=("--source")
+    flags_completion+=("_filedir -d")
+    two_word_flags+=("-S")
+    flags_with_completion+=("-S")
+    flags_completion+=("_filedir -d")
+    flags+=("--verbose")
+    flags+=("-v")
+
+    must_have_one_flag=()
+    must_have_one_noun=()
+    noun_aliases=()
+}
+
+_chezmoi_root_command()
+{
+    last_command="chezmoi"
+
+    command_aliases=()
+
+    commands=()
+    commands+=("add")
+    if [[ -z "${BASH_VERSION}" || "${BASH_VERSINFO[0]}" -gt 3 ]]; then
+        command_aliases+=("manage")
+        aliashash["manage"]="add"
+    fi
+    commands+=("apply")
+    commands+=("archive")
+    commands+=("cat")
+    commands+=("cd")
+    commands+=("chattr")
+    commands+=("completion")
+    commands+=("data")
+    commands+=("diff")
+    commands+=("docs")
+    commands+=("doctor")
+    commands+=("dump")
+    commands+=("edit")
+    commands+=("edit-config")
+    commands+=("execute-template")
+    commands+=("forget")
+    if [[ -z "${BASH_VERSION}" || "${BASH_VERSINFO[0]}" -gt 3 ]]; then
+        command_aliases+=("unmanage")
+        aliashash["unmanage"]="forget"
+    fi
+    commands+=("git")
+    commands+=("help")
+    commands+=("hg")
+    commands+=("import")
+    commands+=("init")
+    commands+=("managed")
+    commands+=("merge")
+    commands+=("purge")
+    commands+=("re-add")
+    commands+=("remove")
+    if [[ -z "${BASH_VERSION}" || "${BASH_VERSINFO[0]}" -gt 3 ]]; then
+        command_aliases+=("rm")
+        aliashash["rm"]="remove"
+    fi
+    commands+=("secret")
+    commands+=("source")
     commands+=("source-path")
+    commands+=("state")
+    commands+=("status")
     commands+=("unmanaged")
     commands+=("update")
     commands+=("upgrade")
@@ -2677,6 +3639,10 @@ _chezmoi_root_command()
     flags_with_completion=()
     flags_completion=()
 
+    flags+=("--cache=")
+    two_word_flags+=("--cache")
+    flags_with_completion+=("--cache")
+    flags_completion+=("_filedir -d")
     flags+=("--color=")
     two_word_flags+=("--color")
     flags+=("--config=")
@@ -2697,6 +3663,10 @@ _chezmoi_root_command()
     flags+=("--dry-run")
     flags+=("-n")
     flags+=("--follow")
+    flags+=("--format=")
+    two_word_flags+=("--format")
+    flags+=("--refresh-externals")
+    flags+=("-R")
     flags+=("--remove")
     flags+=("--source=")
     two_word_flags+=("--source")
@@ -0,0 +1,177 @@
+package chezmoi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// An AgeEncryption uses age for encryption and decryption. See
+// https://age-encryption.org.
+type AgeEncryption struct {
+	Identity       string
+	Identities     []string
+	Passphrase     bool
+	Recipient      string
+	Recipients     []string
+	PassphraseFunc func() (string, error)
+}
+
+// Decrypt implements Encryption.Decrypt.
+func (e *AgeEncryption) Decrypt(filename string, ciphertext []byte) ([]byte, error) {
+	identities, err := e.identities()
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader = bytes.NewReader(ciphertext)
+	if bytes.HasPrefix(ciphertext, []byte(armor.Header)) {
+		r = armor.NewReader(r)
+	}
+	plaintextReader, err := age.Decrypt(r, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return ioutil.ReadAll(plaintextReader)
+}
+
+// DecryptToFile implements Encryption.DecryptToFile.
+func (e *AgeEncryption) DecryptToFile(filename string, ciphertext []byte) error {
+	plaintext, err := e.Decrypt(filename, ciphertext)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, plaintext, 0o600)
+}
+
+// Encrypt implements Encryption.Encrypt. The ciphertext is ASCII armored so
+// that it can be stored in version control.
+func (e *AgeEncryption) Encrypt(filename string, plaintext []byte) ([]byte, error) {
+	recipients, err := e.recipients()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := &bytes.Buffer{}
+	armorWriter := armor.NewWriter(ciphertext)
+	plaintextWriter, err := age.Encrypt(armorWriter, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if _, err := plaintextWriter.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := plaintextWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := armorWriter.Close(); err != nil {
+		return nil, err
+	}
+	return ciphertext.Bytes(), nil
+}
+
+// EncryptFile implements Encryption.EncryptFile.
+func (e *AgeEncryption) EncryptFile(filename string) ([]byte, error) {
+	plaintext, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return e.Encrypt(filename, plaintext)
+}
+
+// identities returns e's identities, reading them from e's identity files or
+// deriving them from a passphrase.
+func (e *AgeEncryption) identities() ([]age.Identity, error) {
+	if e.Passphrase {
+		passphrase, err := e.passphrase()
+		if err != nil {
+			return nil, err
+		}
+		identity, err := age.NewScryptIdentity(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Identity{identity}, nil
+	}
+	var identities []age.Identity
+	for _, identityFile := range e.identityFiles() {
+		data, err := ioutil.ReadFile(identityFile)
+		if err != nil {
+			return nil, err
+		}
+		fileIdentities, err := age.ParseIdentities(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", identityFile, err)
+		}
+		identities = append(identities, fileIdentities...)
+	}
+	if len(identities) == 0 {
+		return nil, errors.New("age: no identities")
+	}
+	return identities, nil
+}
+
+// identityFiles returns all of e's identity files.
+func (e *AgeEncryption) identityFiles() []string {
+	var identityFiles []string
+	if e.Identity != "" {
+		identityFiles = append(identityFiles, e.Identity)
+	}
+	return append(identityFiles, e.Identities...)
+}
+
+// passphrase returns the passphrase returned by e.PassphraseFunc.
+func (e *AgeEncryption) passphrase() (string, error) {
+	if e.PassphraseFunc == nil {
+		return "", errors.New("age: no passphrase")
+	}
+	return e.PassphraseFunc()
+}
+
+// recipients returns e's recipients. If no recipients are configured then the
+// recipients are derived from e's X25519 identities.
+func (e *AgeEncryption) recipients() ([]age.Recipient, error) {
+	if e.Passphrase {
+		passphrase, err := e.passphrase()
+		if err != nil {
+			return nil, err
+		}
+		recipient, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{recipient}, nil
+	}
+	var recipientStrs []string
+	if e.Recipient != "" {
+		recipientStrs = append(recipientStrs, e.Recipient)
+	}
+	recipientStrs = append(recipientStrs, e.Recipients...)
+	recipients := make([]age.Recipient, 0, len(recipientStrs))
+	for _, recipientStr := range recipientStrs {
+		recipient, err := age.ParseX25519Recipient(recipientStr)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	if len(recipients) != 0 {
+		return recipients, nil
+	}
+	identities, err := e.identities()
+	if err != nil {
+		return nil, fmt.Errorf("age: no recipients: %w", err)
+	}
+	for _, identity := range identities {
+		if x25519Identity, ok := identity.(*age.X25519Identity); ok {
+			recipients = append(recipients, x25519Identity.Recipient())
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("age: no recipients")
+	}
+	return recipients, nil
+}
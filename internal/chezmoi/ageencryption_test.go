@@ -0,0 +1,82 @@
+package chezmoi
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ Encryption = &AgeEncryption{}
+
+func TestAgeEncryption(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	tempDir, err := ioutil.TempDir("", "chezmoi-test-age")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+	identityFile := filepath.Join(tempDir, "key.txt")
+	require.NoError(t, ioutil.WriteFile(identityFile, []byte(identity.String()+"\n"), 0o600))
+
+	for _, tc := range []struct {
+		name          string
+		ageEncryption *AgeEncryption
+	}{
+		{
+			name: "identity",
+			ageEncryption: &AgeEncryption{
+				Identity: identityFile,
+			},
+		},
+		{
+			name: "identity_and_recipient",
+			ageEncryption: &AgeEncryption{
+				Identities: []string{identityFile},
+				Recipients: []string{identity.Recipient().String()},
+			},
+		},
+		{
+			name: "passphrase",
+			ageEncryption: &AgeEncryption{
+				Passphrase: true,
+				PassphraseFunc: func() (string, error) {
+					return "correct horse battery staple", nil
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			plaintext := []byte("# contents of .netrc\n")
+			ciphertext, err := tc.ageEncryption.Encrypt(".netrc", plaintext)
+			require.NoError(t, err)
+			assert.Contains(t, string(ciphertext), armor.Header)
+			assert.NotContains(t, string(ciphertext), string(plaintext))
+			actualPlaintext, err := tc.ageEncryption.Decrypt(".netrc", ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, actualPlaintext)
+		})
+	}
+}
+
+func TestAgeEncryptionNoRecipients(t *testing.T) {
+	_, err := (&AgeEncryption{}).Encrypt(".netrc", []byte("# contents of .netrc\n"))
+	assert.Error(t, err)
+}
+
+func TestAgeEncryptionMissingIdentity(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "chezmoi-test-age")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	_, err = (&AgeEncryption{
+		Identity: filepath.Join(tempDir, "key.txt"),
+	}).Encrypt(".netrc", []byte("# contents of .netrc\n"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
@@ -0,0 +1,66 @@
+package chezmoi
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Markers delimiting blocks managed by chezmoi in otherwise unmanaged files.
+const (
+	blockBeginMarker = "# BEGIN chezmoi"
+	blockEndMarker   = "# END chezmoi"
+)
+
+// replaceBlock returns contents with the block named name replaced by block.
+// If contents does not contain the block then it is appended. If block is
+// empty then the block, including its markers, is removed.
+func replaceBlock(contents []byte, name string, block []byte) ([]byte, error) {
+	beginMarker := []byte(blockBeginMarker + " " + name)
+	endMarker := []byte(blockEndMarker)
+
+	lines := bytes.SplitAfter(contents, []byte("\n"))
+	begin, end := -1, -1
+	for i, line := range lines {
+		trimmedLine := bytes.TrimRight(line, " \t\r\n")
+		switch {
+		case begin == -1 && bytes.Equal(trimmedLine, beginMarker):
+			begin = i
+		case begin != -1 && bytes.Equal(trimmedLine, endMarker):
+			end = i
+		}
+		if end != -1 {
+			break
+		}
+	}
+	if begin != -1 && end == -1 {
+		return nil, fmt.Errorf("%s: unterminated block", name)
+	}
+
+	b := &bytes.Buffer{}
+	if begin == -1 {
+		b.Write(contents)
+		if len(contents) != 0 && !bytes.HasSuffix(contents, []byte("\n")) {
+			b.WriteByte('\n')
+		}
+	} else {
+		for _, line := range lines[:begin] {
+			b.Write(line)
+		}
+	}
+	if len(block) != 0 {
+		b.Write(beginMarker)
+		b.WriteByte('\n')
+		b.Write(block)
+		if !bytes.HasSuffix(block, []byte("\n")) {
+			b.WriteByte('\n')
+		}
+		b.Write(endMarker)
+		b.WriteByte('\n')
+	}
+	if end != -1 {
+		for _, line := range lines[end+1:] {
+			b.Write(line)
+		}
+	}
+	return b.Bytes(), nil
+}
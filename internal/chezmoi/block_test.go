@@ -0,0 +1,116 @@
+package chezmoi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceBlock(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		contents string
+		block    string
+		expected string
+	}{
+		{
+			name:  "empty",
+			block: "foo\n",
+			expected: joinLines(
+				"# BEGIN chezmoi name",
+				"foo",
+				"# END chezmoi",
+			),
+		},
+		{
+			name:     "append",
+			contents: "bar",
+			block:    "foo",
+			expected: joinLines(
+				"bar",
+				"# BEGIN chezmoi name",
+				"foo",
+				"# END chezmoi",
+			),
+		},
+		{
+			name: "replace",
+			contents: joinLines(
+				"before",
+				"# BEGIN chezmoi name",
+				"old",
+				"# END chezmoi",
+				"after",
+			),
+			block: "new\n",
+			expected: joinLines(
+				"before",
+				"# BEGIN chezmoi name",
+				"new",
+				"# END chezmoi",
+				"after",
+			),
+		},
+		{
+			name: "replace_crlf",
+			contents: "before\r\n" +
+				"# BEGIN chezmoi name\r\n" +
+				"old\r\n" +
+				"# END chezmoi\r\n" +
+				"after\r\n",
+			block: "new\n",
+			expected: "before\r\n" +
+				"# BEGIN chezmoi name\n" +
+				"new\n" +
+				"# END chezmoi\n" +
+				"after\r\n",
+		},
+		{
+			name: "other_block",
+			contents: joinLines(
+				"# BEGIN chezmoi other",
+				"other",
+				"# END chezmoi",
+			),
+			block: "foo\n",
+			expected: joinLines(
+				"# BEGIN chezmoi other",
+				"other",
+				"# END chezmoi",
+				"# BEGIN chezmoi name",
+				"foo",
+				"# END chezmoi",
+			),
+		},
+		{
+			name: "remove",
+			contents: joinLines(
+				"before",
+				"# BEGIN chezmoi name",
+				"old",
+				"# END chezmoi",
+				"after",
+			),
+			expected: joinLines(
+				"before",
+				"after",
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := replaceBlock([]byte(tc.contents), "name", []byte(tc.block))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(actual))
+		})
+	}
+}
+
+func TestReplaceBlockUnterminated(t *testing.T) {
+	contents := joinLines(
+		"# BEGIN chezmoi name",
+		"old",
+	)
+	_, err := replaceBlock([]byte(contents), "name", []byte("new\n"))
+	assert.Error(t, err)
+}
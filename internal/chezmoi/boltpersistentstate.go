@@ -48,6 +48,27 @@ func (b *BoltPersistentState) Close() error {
 	return nil
 }
 
+// Data returns all the data in b, keyed by bucket and then by key.
+func (b *BoltPersistentState) Data() (map[string]map[string][]byte, error) {
+	data := make(map[string]map[string][]byte)
+	if b.db == nil {
+		return data, nil
+	}
+	return data, b.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(bucket []byte, b *bolt.Bucket) error {
+			bucketData := make(map[string][]byte)
+			if err := b.ForEach(func(k, v []byte) error {
+				bucketData[string(k)] = copyBytes(v)
+				return nil
+			}); err != nil {
+				return err
+			}
+			data[string(bucket)] = bucketData
+			return nil
+		})
+	})
+}
+
 // Delete deletes the value associate with key in bucket. If bucket or key does
 // not exist then Delete does nothing.
 func (b *BoltPersistentState) Delete(bucket, key []byte) error {
@@ -63,6 +84,20 @@ func (b *BoltPersistentState) Delete(bucket, key []byte) error {
 	})
 }
 
+// DeleteBucket deletes bucket and all its contents. If bucket does not exist
+// then DeleteBucket does nothing.
+func (b *BoltPersistentState) DeleteBucket(bucket []byte) error {
+	if b.db == nil {
+		return nil
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucket) == nil {
+			return nil
+		}
+		return tx.DeleteBucket(bucket)
+	})
+}
+
 // Get returns the value associated with key in bucket.
 func (b *BoltPersistentState) Get(bucket, key []byte) ([]byte, error) {
 	var value []byte
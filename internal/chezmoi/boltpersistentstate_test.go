@@ -61,6 +61,14 @@ func TestBoltPersistentState(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, value, actualValue)
 
+	actualData, err := b.Data()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string][]byte{
+		string(bucket): {
+			string(key): value,
+		},
+	}, actualData)
+
 	require.NoError(t, b.Close())
 
 	b, err = NewBoltPersistentState(fs, path, nil)
@@ -71,6 +79,14 @@ func TestBoltPersistentState(t *testing.T) {
 	actualValue, err = b.Get(bucket, key)
 	require.NoError(t, err)
 	assert.Equal(t, []byte(nil), actualValue)
+
+	require.NoError(t, b.Set(bucket, key, value))
+	require.NoError(t, b.DeleteBucket(bucket))
+	require.NoError(t, b.DeleteBucket(bucket))
+
+	actualData, err = b.Data()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string][]byte{}, actualData)
 }
 
 func TestBoltPersistentStateReadOnly(t *testing.T) {
@@ -14,11 +14,18 @@ import (
 
 // Suffixes and prefixes.
 const (
+	afterPrefix      = "after_"
+	beforePrefix     = "before_"
+	blockPrefix      = "block_"
+	createPrefix     = "create_"
 	dotPrefix        = "dot_"
 	emptyPrefix      = "empty_"
 	encryptedPrefix  = "encrypted_"
 	exactPrefix      = "exact_"
 	executablePrefix = "executable_"
+	mergePrefix      = "merge_"
+	modifyPrefix     = "modify_"
+	onChangePrefix   = "onchange_"
 	oncePrefix       = "once_"
 	privatePrefix    = "private_"
 	runPrefix        = "run_"
@@ -29,18 +36,26 @@ const (
 // A PersistentState is an interface to a persistent state.
 type PersistentState interface {
 	Close() error
+	Data() (map[string]map[string][]byte, error)
 	Delete(bucket, key []byte) error
+	DeleteBucket(bucket []byte) error
 	Get(bucket, key []byte) ([]byte, error)
 	Set(bucket, key, value []byte) error
 }
 
 // An ApplyOptions is a big ball of mud for things that affect Entry.Apply.
 type ApplyOptions struct {
+	BlockName         string
 	DestDir           string
 	DryRun            bool
+	DryRunScript      func(targetPath string)
+	EntryStateBucket  []byte
 	Ignore            func(string) bool
+	Interpreters      map[string]*Interpreter
+	Modified          func(targetName string) (bool, error)
 	PersistentState   PersistentState
 	Remove            bool
+	ScriptEnv         []string
 	ScriptStateBucket []byte
 	Stdout            io.Writer
 	Umask             os.FileMode
@@ -51,11 +66,11 @@ type ApplyOptions struct {
 type Entry interface {
 	AppendAllEntries(allEntries []Entry) []Entry
 	Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *ApplyOptions) error
-	ConcreteValue(ignore func(string) bool, sourceDir string, umask os.FileMode, recursive bool) (interface{}, error)
+	ConcreteValue(fs vfs.FS, applyOptions *ApplyOptions, sourceDir string, recursive bool) (interface{}, error)
 	Evaluate(ignore func(string) bool) error
 	SourceName() string
 	TargetName() string
-	archive(w *tar.Writer, ignore func(string) bool, headerTemplate *tar.Header, umask os.FileMode) error
+	archive(w *tar.Writer, fs vfs.FS, applyOptions *ApplyOptions, headerTemplate *tar.Header) error
 }
 
 type parsedSourceFilePath struct {
@@ -64,6 +79,42 @@ type parsedSourceFilePath struct {
 	scriptAttributes *ScriptAttributes
 }
 
+// CopyPersistentState copies all the data in src to dst.
+func CopyPersistentState(dst, src PersistentState) error {
+	data, err := src.Data()
+	if err != nil {
+		return err
+	}
+	for bucket, bucketData := range data {
+		for key, value := range bucketData {
+			if err := dst.Set([]byte(bucket), []byte(key), value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyBytes returns a copy of b.
+func copyBytes(b []byte) []byte {
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
+
+// copyPersistentStateData returns a deep copy of data.
+func copyPersistentStateData(data map[string]map[string][]byte) map[string]map[string][]byte {
+	result := make(map[string]map[string][]byte, len(data))
+	for bucket, bucketData := range data {
+		resultBucketData := make(map[string][]byte, len(bucketData))
+		for key, value := range bucketData {
+			resultBucketData[key] = copyBytes(value)
+		}
+		result[bucket] = resultBucketData
+	}
+	return result
+}
+
 // dirNames returns the dir names from dirAttributes.
 func dirNames(dirAttributes []DirAttributes) []string {
 	dns := make([]string, len(dirAttributes))
@@ -88,6 +139,17 @@ func parseDirNameComponents(components []string) []DirAttributes {
 	return das
 }
 
+// ParseSourceFilePath parses path, which is relative to the source directory,
+// and returns its target name and either its file or its script attributes.
+func ParseSourceFilePath(path string) (string, *FileAttributes, *ScriptAttributes) {
+	psfp := parseSourceFilePath(path)
+	dns := dirNames(psfp.dirAttributes)
+	if psfp.scriptAttributes != nil {
+		return filepath.Join(append(dns, psfp.scriptAttributes.Name)...), nil, psfp.scriptAttributes
+	}
+	return filepath.Join(append(dns, psfp.fileAttributes.Name)...), psfp.fileAttributes, nil
+}
+
 // parseSourceFilePath parses a single source file path.
 func parseSourceFilePath(path string) parsedSourceFilePath {
 	components := splitPathList(path)
@@ -2,9 +2,12 @@ package chezmoi
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestReturnTemplateError(t *testing.T) {
@@ -30,3 +33,118 @@ func TestReturnTemplateError(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyPersistentState(t *testing.T) {
+	src := NewMemoryPersistentState()
+	require.NoError(t, src.Set([]byte("bucket1"), []byte("key1"), []byte("value1")))
+	require.NoError(t, src.Set([]byte("bucket2"), []byte("key2"), []byte("value2")))
+
+	dst := NewMemoryPersistentState()
+	require.NoError(t, dst.Set([]byte("bucket1"), []byte("key3"), []byte("value3")))
+	require.NoError(t, CopyPersistentState(dst, src))
+
+	actualData, err := dst.Data()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string][]byte{
+		"bucket1": {
+			"key1": []byte("value1"),
+			"key3": []byte("value3"),
+		},
+		"bucket2": {
+			"key2": []byte("value2"),
+		},
+	}, actualData)
+}
+
+func TestParseSourceFilePath(t *testing.T) {
+	for _, tc := range []struct {
+		path                     string
+		expectedTargetName       string
+		expectedFileAttributes   *FileAttributes
+		expectedScriptAttributes *ScriptAttributes
+	}{
+		{
+			path:               "dot_bashrc",
+			expectedTargetName: ".bashrc",
+			expectedFileAttributes: &FileAttributes{
+				Name: ".bashrc",
+				Mode: 0o666,
+			},
+		},
+		{
+			path:               filepath.Join("private_dot_ssh", "encrypted_private_config.tmpl"),
+			expectedTargetName: filepath.Join(".ssh", "config"),
+			expectedFileAttributes: &FileAttributes{
+				Name:      "config",
+				Mode:      0o600,
+				Encrypted: true,
+				Template:  true,
+			},
+		},
+		{
+			path:               filepath.Join("exact_dir", "symlink_dot_link"),
+			expectedTargetName: filepath.Join("dir", ".link"),
+			expectedFileAttributes: &FileAttributes{
+				Name: ".link",
+				Mode: os.ModeSymlink | 0o666,
+			},
+		},
+		{
+			path:               "run_once_install.sh",
+			expectedTargetName: "install.sh",
+			expectedScriptAttributes: &ScriptAttributes{
+				Name: "install.sh",
+				Once: true,
+			},
+		},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			actualTargetName, actualFileAttributes, actualScriptAttributes := ParseSourceFilePath(tc.path)
+			assert.Equal(t, tc.expectedTargetName, actualTargetName)
+			assert.Equal(t, tc.expectedFileAttributes, actualFileAttributes)
+			assert.Equal(t, tc.expectedScriptAttributes, actualScriptAttributes)
+		})
+	}
+}
+
+// testPersistentState tests the behavior common to all PersistentStates.
+func testPersistentState(t *testing.T, s PersistentState) {
+	var (
+		bucket = []byte("bucket")
+		key    = []byte("key")
+		value  = []byte("value")
+	)
+
+	require.NoError(t, s.Delete(bucket, key))
+	require.NoError(t, s.DeleteBucket(bucket))
+
+	actualValue, err := s.Get(bucket, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(nil), actualValue)
+
+	require.NoError(t, s.Set(bucket, key, value))
+	actualValue, err = s.Get(bucket, key)
+	require.NoError(t, err)
+	assert.Equal(t, value, actualValue)
+
+	actualData, err := s.Data()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string][]byte{
+		string(bucket): {
+			string(key): value,
+		},
+	}, actualData)
+
+	require.NoError(t, s.Delete(bucket, key))
+	actualValue, err = s.Get(bucket, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(nil), actualValue)
+
+	require.NoError(t, s.Set(bucket, key, value))
+	require.NoError(t, s.DeleteBucket(bucket))
+	actualData, err = s.Data()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string][]byte{}, actualData)
+
+	require.NoError(t, s.Close())
+}
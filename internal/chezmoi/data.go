@@ -0,0 +1,97 @@
+package chezmoi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pelletier/go-toml"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// dataFormats maps file extensions of .chezmoidata files to functions that
+// decode their contents.
+var dataFormats = map[string]func([]byte) (map[string]interface{}, error){
+	"json": func(data []byte) (map[string]interface{}, error) {
+		var result map[string]interface{}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	},
+	"toml": func(data []byte) (map[string]interface{}, error) {
+		tree, err := toml.LoadBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		return tree.ToMap(), nil
+	},
+	"yaml": func(data []byte) (map[string]interface{}, error) {
+		var result map[string]interface{}
+		if err := yaml.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		value, err := normalizeYAMLValue(result)
+		if err != nil {
+			return nil, err
+		}
+		return value.(map[string]interface{}), nil
+	},
+}
+
+// mergeData recursively merges src into dst. Values in src take precedence
+// over values in dst, except that maps are merged.
+func mergeData(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		srcMap, srcOK := srcValue.(map[string]interface{})
+		dstMap, dstOK := dst[key].(map[string]interface{})
+		if srcOK && dstOK {
+			mergeData(dstMap, srcMap)
+		} else {
+			dst[key] = srcValue
+		}
+	}
+}
+
+// normalizeYAMLValue converts the map[interface{}]interface{}s returned by
+// yaml.Unmarshal into map[string]interface{}s so that they can be merged and
+// encoded as JSON.
+func normalizeYAMLValue(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("%v: key is not a string", k)
+			}
+			normalizedValue, err := normalizeYAMLValue(v)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = normalizedValue
+		}
+		return result, nil
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, v := range value {
+			normalizedValue, err := normalizeYAMLValue(v)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = normalizedValue
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, 0, len(value))
+		for _, v := range value {
+			normalizedValue, err := normalizeYAMLValue(v)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, normalizedValue)
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
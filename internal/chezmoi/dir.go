@@ -96,7 +96,7 @@ func (d *Dir) AppendAllEntries(allEntries []Entry) []Entry {
 
 // Apply ensures that destDir in fs matches d.
 func (d *Dir) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *ApplyOptions) error {
-	if applyOptions.Ignore(d.targetName) {
+	if d.ignored(applyOptions.Ignore) {
 		return nil
 	}
 	targetPath := filepath.Join(applyOptions.DestDir, d.targetName)
@@ -107,14 +107,26 @@ func (d *Dir) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *Apply
 	} else {
 		info, err = fs.Lstat(targetPath)
 	}
+	// If the directory's permissions have been modified and should not be
+	// overwritten then leave them unchanged, but still apply its entries.
+	skipChmod := false
 	switch {
 	case err == nil && info.IsDir():
 		if info.Mode().Perm() != d.Perm&^applyOptions.Umask {
-			if err := mutator.Chmod(targetPath, d.Perm&^applyOptions.Umask); err != nil {
+			skipChmod, err = applyOptions.skipModified(fs, d.targetName, targetPath, follow)
+			if err != nil {
 				return err
 			}
+			if !skipChmod {
+				if err := mutator.Chmod(targetPath, d.Perm&^applyOptions.Umask); err != nil {
+					return err
+				}
+			}
 		}
 	case err == nil:
+		if skip, err := applyOptions.skipModified(fs, d.targetName, targetPath, follow); err != nil || skip {
+			return err
+		}
 		if err := mutator.RemoveAll(targetPath); err != nil {
 			return err
 		}
@@ -126,20 +138,39 @@ func (d *Dir) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *Apply
 	default:
 		return err
 	}
+	if !skipChmod {
+		if err := applyOptions.recordEntryState(fs, targetPath, follow); err != nil {
+			return err
+		}
+	}
 	for _, entryName := range sortedEntryNames(d.Entries) {
-		if err := d.Entries[entryName].Apply(fs, mutator, follow, applyOptions); err != nil {
+		entry := d.Entries[entryName]
+		// before_ and after_ scripts are run by TargetState.Apply and
+		// TargetState.ApplyEntries.
+		if isBeforeOrAfterScript(entry) {
+			continue
+		}
+		if err := entry.Apply(fs, mutator, follow, applyOptions); err != nil {
 			return err
 		}
 	}
 	if d.Exact {
 		infos, err := fs.ReadDir(targetPath)
-		if err != nil {
+		switch {
+		case os.IsNotExist(err) && applyOptions.DryRun:
+			// The directory was not created because this is a dry run.
+			return nil
+		case err != nil:
 			return err
 		}
 		for _, info := range infos {
 			name := info.Name()
 			if _, ok := d.Entries[name]; !ok {
-				if applyOptions.Ignore(filepath.Join(d.targetName, name)) {
+				ignoreName := filepath.Join(d.targetName, name)
+				if info.IsDir() {
+					ignoreName += "/"
+				}
+				if applyOptions.Ignore(ignoreName) {
 					continue
 				}
 				if err := mutator.RemoveAll(filepath.Join(targetPath, name)); err != nil {
@@ -152,14 +183,14 @@ func (d *Dir) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *Apply
 }
 
 // ConcreteValue implements Entry.ConcreteValue.
-func (d *Dir) ConcreteValue(ignore func(string) bool, sourceDir string, umask os.FileMode, recursive bool) (interface{}, error) {
-	if ignore(d.targetName) {
+func (d *Dir) ConcreteValue(fs vfs.FS, applyOptions *ApplyOptions, sourceDir string, recursive bool) (interface{}, error) {
+	if d.ignored(applyOptions.Ignore) {
 		return nil, nil
 	}
 	var entryConcreteValues []interface{}
 	if recursive {
 		for _, entryName := range sortedEntryNames(d.Entries) {
-			entryConcreteValue, err := d.Entries[entryName].ConcreteValue(ignore, sourceDir, umask, recursive)
+			entryConcreteValue, err := d.Entries[entryName].ConcreteValue(fs, applyOptions, sourceDir, recursive)
 			if err != nil {
 				return nil, err
 			}
@@ -173,14 +204,14 @@ func (d *Dir) ConcreteValue(ignore func(string) bool, sourceDir string, umask os
 		SourcePath: filepath.Join(sourceDir, d.SourceName()),
 		TargetPath: d.TargetName(),
 		Exact:      d.Exact,
-		Perm:       int(d.Perm &^ umask),
+		Perm:       int(d.Perm &^ applyOptions.Umask),
 		Entries:    entryConcreteValues,
 	}, nil
 }
 
 // Evaluate evaluates all entries in d.
 func (d *Dir) Evaluate(ignore func(string) bool) error {
-	if ignore(d.targetName) {
+	if d.ignored(ignore) {
 		return nil
 	}
 	for _, entryName := range sortedEntryNames(d.Entries) {
@@ -207,21 +238,27 @@ func (d *Dir) TargetName() string {
 }
 
 // archive writes d to w.
-func (d *Dir) archive(w *tar.Writer, ignore func(string) bool, headerTemplate *tar.Header, umask os.FileMode) error {
-	if ignore(d.targetName) {
+func (d *Dir) archive(w *tar.Writer, fs vfs.FS, applyOptions *ApplyOptions, headerTemplate *tar.Header) error {
+	if d.ignored(applyOptions.Ignore) {
 		return nil
 	}
 	header := *headerTemplate
 	header.Typeflag = tar.TypeDir
 	header.Name = d.targetName + "/"
-	header.Mode = int64(d.Perm &^ umask)
+	header.Mode = int64(d.Perm &^ applyOptions.Umask)
 	if err := w.WriteHeader(&header); err != nil {
 		return err
 	}
 	for _, entryName := range sortedEntryNames(d.Entries) {
-		if err := d.Entries[entryName].archive(w, ignore, headerTemplate, umask); err != nil {
+		if err := d.Entries[entryName].archive(w, fs, applyOptions, headerTemplate); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// ignored returns if d is ignored by ignore. A trailing slash is appended to
+// d's target name so that patterns that only match directories match d.
+func (d *Dir) ignored(ignore func(string) bool) bool {
+	return ignore(d.targetName + "/")
+}
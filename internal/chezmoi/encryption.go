@@ -0,0 +1,11 @@
+package chezmoi
+
+// An Encryption encrypts and decrypts the contents of files in the source
+// state. filename arguments to Decrypt and Encrypt are used as hints, for
+// example for naming temporary files.
+type Encryption interface {
+	Decrypt(filename string, ciphertext []byte) ([]byte, error)
+	DecryptToFile(filename string, ciphertext []byte) error
+	Encrypt(filename string, plaintext []byte) ([]byte, error)
+	EncryptFile(filename string) ([]byte, error)
+}
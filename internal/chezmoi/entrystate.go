@@ -0,0 +1,122 @@
+package chezmoi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+
+	vfs "github.com/twpayne/go-vfs"
+)
+
+// An EntryState represents the state of an entry in the destination directory
+// when chezmoi last wrote it.
+type EntryState struct {
+	Type           string      `json:"type"`
+	Mode           os.FileMode `json:"mode"`
+	ContentsSHA256 string      `json:"contentsSHA256,omitempty"`
+}
+
+// NewEntryState returns the state of the entry at path in fs, or nil if there
+// is no entry at path.
+func NewEntryState(fs vfs.FS, path string, follow bool) (*EntryState, error) {
+	var info os.FileInfo
+	var err error
+	if follow {
+		info, err = fs.Stat(path)
+	} else {
+		info, err = fs.Lstat(path)
+	}
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	switch {
+	case info.IsDir():
+		return &EntryState{
+			Type: "dir",
+			Mode: info.Mode().Perm(),
+		}, nil
+	case info.Mode().IsRegular():
+		contents, err := fs.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return &EntryState{
+			Type:           "file",
+			Mode:           info.Mode().Perm(),
+			ContentsSHA256: sha256Hex(contents),
+		}, nil
+	case info.Mode()&os.ModeType == os.ModeSymlink:
+		linkname, err := fs.Readlink(path)
+		if err != nil {
+			return nil, err
+		}
+		return &EntryState{
+			Type:           "symlink",
+			ContentsSHA256: sha256Hex([]byte(linkname)),
+		}, nil
+	default:
+		return &EntryState{
+			Type: "other",
+			Mode: info.Mode(),
+		}, nil
+	}
+}
+
+// recordEntryState records the current state of the entry at targetPath in
+// fs, so that later modifications can be detected.
+func (ao *ApplyOptions) recordEntryState(fs vfs.FS, targetPath string, follow bool) error {
+	if ao.EntryStateBucket == nil || ao.PersistentState == nil {
+		return nil
+	}
+	entryState, err := NewEntryState(fs, targetPath, follow)
+	if err != nil {
+		return err
+	}
+	if entryState == nil {
+		return ao.PersistentState.Delete(ao.EntryStateBucket, []byte(targetPath))
+	}
+	entryStateData, err := json.Marshal(entryState)
+	if err != nil {
+		return err
+	}
+	return ao.PersistentState.Set(ao.EntryStateBucket, []byte(targetPath), entryStateData)
+}
+
+// skipModified returns true if the entry at targetPath in fs has been modified
+// since chezmoi last wrote it and ao.Modified returns that it should not be
+// overwritten. ao.Modified is called with the entry's target name and returns
+// whether the entry should be overwritten. If ao.Modified is nil then modified
+// entries are always overwritten. skipModified should only be called before
+// modifying an existing entry.
+func (ao *ApplyOptions) skipModified(fs vfs.FS, targetName, targetPath string, follow bool) (bool, error) {
+	if ao.EntryStateBucket == nil || ao.PersistentState == nil || ao.Modified == nil {
+		return false, nil
+	}
+	entryStateData, err := ao.PersistentState.Get(ao.EntryStateBucket, []byte(targetPath))
+	if err != nil || entryStateData == nil {
+		return false, err
+	}
+	var recordedEntryState EntryState
+	if err := json.Unmarshal(entryStateData, &recordedEntryState); err != nil {
+		return false, err
+	}
+	entryState, err := NewEntryState(fs, targetPath, follow)
+	if err != nil {
+		return false, err
+	}
+	if entryState == nil || *entryState == recordedEntryState {
+		return false, nil
+	}
+	overwrite, err := ao.Modified(targetName)
+	return !overwrite, err
+}
+
+// sha256Hex returns the hex-encoded SHA256 sum of data.
+func sha256Hex(data []byte) string {
+	sha256SumArr := sha256.Sum256(data)
+	return hex.EncodeToString(sha256SumArr[:])
+}
@@ -0,0 +1,77 @@
+package chezmoi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-vfs/vfst"
+)
+
+func TestApplyModified(t *testing.T) {
+	fs, cleanup, err := vfst.NewTestFS(map[string]interface{}{
+		"/home/user": &vfst.Dir{Perm: 0o755},
+	})
+	require.NoError(t, err)
+	defer cleanup()
+
+	var modifiedTargetNames []string
+	overwrite := false
+	applyOptions := &ApplyOptions{
+		DestDir:          "/home/user",
+		EntryStateBucket: []byte("entryState"),
+		Ignore:           func(string) bool { return false },
+		Modified: func(targetName string) (bool, error) {
+			modifiedTargetNames = append(modifiedTargetNames, targetName)
+			return overwrite, nil
+		},
+		PersistentState: NewMemoryPersistentState(),
+		Umask:           0o22,
+	}
+	apply := func(contents string) {
+		f := &File{
+			sourceName: "dot_bashrc",
+			targetName: ".bashrc",
+			Perm:       0o666,
+			contents:   []byte(contents),
+		}
+		require.NoError(t, f.Apply(fs, NewFSMutator(fs), false, applyOptions))
+	}
+
+	// Test that a new file is written without calling Modified.
+	apply("# contents of .bashrc\n")
+	assert.Nil(t, modifiedTargetNames)
+	vfst.RunTests(t, fs, "",
+		vfst.TestPath("/home/user/.bashrc",
+			vfst.TestContentsString("# contents of .bashrc\n"),
+		),
+	)
+
+	// Test that an unmodified file is updated without calling Modified.
+	apply("# new contents of .bashrc\n")
+	assert.Nil(t, modifiedTargetNames)
+
+	// Test that a modified file is not overwritten if Modified returns false.
+	require.NoError(t, fs.WriteFile("/home/user/.bashrc", []byte("# local contents of .bashrc\n"), 0o644))
+	apply("# newer contents of .bashrc\n")
+	assert.Equal(t, []string{".bashrc"}, modifiedTargetNames)
+	vfst.RunTests(t, fs, "",
+		vfst.TestPath("/home/user/.bashrc",
+			vfst.TestContentsString("# local contents of .bashrc\n"),
+		),
+	)
+
+	// Test that a modified file is overwritten if Modified returns true.
+	overwrite = true
+	apply("# newer contents of .bashrc\n")
+	assert.Equal(t, []string{".bashrc", ".bashrc"}, modifiedTargetNames)
+	vfst.RunTests(t, fs, "",
+		vfst.TestPath("/home/user/.bashrc",
+			vfst.TestContentsString("# newer contents of .bashrc\n"),
+		),
+	)
+
+	// Test that Modified is not called once the file has been overwritten.
+	apply("# newest contents of .bashrc\n")
+	assert.Equal(t, []string{".bashrc", ".bashrc"}, modifiedTargetNames)
+}
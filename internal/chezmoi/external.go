@@ -0,0 +1,293 @@
+package chezmoi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// External types.
+const (
+	ExternalTypeArchive = "archive"
+	ExternalTypeFile    = "file"
+	ExternalTypeGitRepo = "git-repo"
+)
+
+// An External is a target whose contents are read from a URL, declared in a
+// .chezmoiexternal file.
+type External struct {
+	Type            string
+	URL             string
+	Exact           bool
+	Executable      bool
+	Format          string
+	StripComponents int
+	RefreshPeriod   time.Duration
+}
+
+// An externalConfig is the configuration of an External in a .chezmoiexternal
+// file.
+type externalConfig struct {
+	Type            string `json:"type"`
+	URL             string `json:"url"`
+	Exact           bool   `json:"exact"`
+	Executable      bool   `json:"executable"`
+	Format          string `json:"format"`
+	StripComponents int    `json:"stripComponents"`
+	RefreshPeriod   string `json:"refreshPeriod"`
+}
+
+// archiveFormats maps archive file extensions to formats.
+var archiveFormats = []struct {
+	extension string
+	format    string
+}{
+	{extension: ".tar", format: "tar"},
+	{extension: ".tar.bz2", format: "tar.bz2"},
+	{extension: ".tar.gz", format: "tar.gz"},
+	{extension: ".tbz2", format: "tar.bz2"},
+	{extension: ".tgz", format: "tar.gz"},
+	{extension: ".zip", format: "zip"},
+}
+
+// parseExternals parses the externals in data, which was decoded from a
+// .chezmoiexternal file.
+func parseExternals(data map[string]interface{}) (map[string]*External, error) {
+	// Round trip through JSON so that all formats are decoded in the same way.
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var externalConfigs map[string]externalConfig
+	if err := json.Unmarshal(jsonData, &externalConfigs); err != nil {
+		return nil, err
+	}
+	externals := make(map[string]*External, len(externalConfigs))
+	for name, ec := range externalConfigs {
+		external := &External{
+			Type:            ec.Type,
+			URL:             ec.URL,
+			Exact:           ec.Exact,
+			Executable:      ec.Executable,
+			Format:          ec.Format,
+			StripComponents: ec.StripComponents,
+		}
+		switch ec.Type {
+		case ExternalTypeArchive:
+			if external.Format == "" {
+				external.Format = guessArchiveFormat(ec.URL)
+			}
+		case ExternalTypeFile:
+		case ExternalTypeGitRepo:
+			// Git repos are read as tar archives of their contents.
+			external.Format = "tar"
+		default:
+			return nil, fmt.Errorf("%s: unknown type %q", name, ec.Type)
+		}
+		if ec.URL == "" {
+			return nil, fmt.Errorf("%s: missing url", name)
+		}
+		if ec.StripComponents < 0 {
+			return nil, fmt.Errorf("%s: invalid stripComponents %d", name, ec.StripComponents)
+		}
+		if ec.RefreshPeriod != "" {
+			external.RefreshPeriod, err = time.ParseDuration(ec.RefreshPeriod)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		targetName := filepath.Clean(filepath.FromSlash(name))
+		if filepath.IsAbs(targetName) || targetName == "." || targetName == ".." || strings.HasPrefix(targetName, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s: invalid target", name)
+		}
+		externals[targetName] = external
+	}
+	return externals, nil
+}
+
+// guessArchiveFormat returns the format of the archive at url from its
+// extension, or an empty string if it is not known.
+func guessArchiveFormat(url string) string {
+	lowerURL := strings.ToLower(url)
+	if index := strings.IndexAny(lowerURL, "?#"); index != -1 {
+		lowerURL = lowerURL[:index]
+	}
+	for _, af := range archiveFormats {
+		if strings.HasSuffix(lowerURL, af.extension) {
+			return af.format
+		}
+	}
+	return ""
+}
+
+// newExternalArchiveDir returns a new Dir with sourceName and targetName
+// containing the entries in the archive data.
+func newExternalArchiveDir(sourceName, targetName string, external *External, data []byte) (*Dir, error) {
+	root := newDir(sourceName, targetName, external.Exact, 0o777)
+	if err := walkArchive(data, external.Format, func(name string, info os.FileInfo, r io.Reader, linkname string) error {
+		cleanName := strings.Trim(path.Clean(name), "/")
+		switch {
+		case cleanName == ".":
+			return nil
+		case cleanName == ".." || strings.HasPrefix(cleanName, "../"):
+			return fmt.Errorf("%s: outside archive", name)
+		}
+		components := strings.Split(cleanName, "/")
+		if len(components) <= external.StripComponents {
+			return nil
+		}
+		components = components[external.StripComponents:]
+
+		// Find the parent directory, creating it if the archive does not
+		// contain it.
+		dir := root
+		for _, component := range components[:len(components)-1] {
+			entry, ok := dir.Entries[component]
+			if !ok {
+				entry = newDir(sourceName, filepath.Join(dir.targetName, component), external.Exact, 0o777)
+				dir.Entries[component] = entry
+			}
+			var isDir bool
+			dir, isDir = entry.(*Dir)
+			if !isDir {
+				return fmt.Errorf("%s: parent is not a directory", name)
+			}
+		}
+
+		entryName := components[len(components)-1]
+		entryTargetName := filepath.Join(dir.targetName, entryName)
+		switch {
+		case info.IsDir():
+			perm := os.FileMode(0o777)
+			if info.Mode().Perm()&0o77 == 0 {
+				perm &= 0o700
+			}
+			if entry, ok := dir.Entries[entryName].(*Dir); ok {
+				entry.Perm = perm
+			} else {
+				dir.Entries[entryName] = newDir(sourceName, entryTargetName, external.Exact, perm)
+			}
+		case info.Mode().IsRegular():
+			contents, err := ioutil.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			perm := os.FileMode(0o666)
+			if info.Mode().Perm()&0o111 != 0 {
+				perm |= 0o111
+			}
+			if info.Mode().Perm()&0o77 == 0 {
+				perm &= 0o700
+			}
+			dir.Entries[entryName] = &File{
+				sourceName: sourceName,
+				targetName: entryTargetName,
+				Empty:      true,
+				Perm:       perm,
+				contents:   contents,
+			}
+		case info.Mode()&os.ModeType == os.ModeSymlink:
+			dir.Entries[entryName] = &Symlink{
+				sourceName: sourceName,
+				targetName: entryTargetName,
+				linkname:   linkname,
+			}
+		default:
+			return fmt.Errorf("%s: unsupported file type", name)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// walkArchive calls f for each member of the archive data in format.
+func walkArchive(data []byte, format string, f func(name string, info os.FileInfo, r io.Reader, linkname string) error) error {
+	switch format {
+	case "tar", "tar.bz2", "tar.gz":
+		var r io.Reader = bytes.NewReader(data)
+		switch format {
+		case "tar.bz2":
+			r = bzip2.NewReader(r)
+		case "tar.gz":
+			gzipReader, err := gzip.NewReader(r)
+			if err != nil {
+				return err
+			}
+			defer gzipReader.Close()
+			r = gzipReader
+		}
+		tarReader := tar.NewReader(r)
+		for {
+			header, err := tarReader.Next()
+			switch {
+			case err == io.EOF:
+				return nil
+			case err != nil:
+				return err
+			}
+			switch header.Typeflag {
+			case tar.TypeXGlobalHeader:
+				continue
+			case tar.TypeLink:
+				return fmt.Errorf("%s: hard links are not supported", header.Name)
+			}
+			if err := f(header.Name, header.FileInfo(), tarReader, header.Linkname); err != nil {
+				return err
+			}
+		}
+	case "zip":
+		zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return err
+		}
+		// Sort the files so that parent directories are visited before their
+		// children.
+		files := append([]*zip.File(nil), zipReader.File...)
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].Name < files[j].Name
+		})
+		for _, file := range files {
+			if err := walkZipFile(file, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "":
+		return fmt.Errorf("unknown archive format")
+	default:
+		return fmt.Errorf("%s: unknown archive format", format)
+	}
+}
+
+// walkZipFile calls f for file.
+func walkZipFile(file *zip.File, f func(name string, info os.FileInfo, r io.Reader, linkname string) error) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	info := file.FileInfo()
+	linkname := ""
+	if info.Mode()&os.ModeType == os.ModeSymlink {
+		linknameData, err := ioutil.ReadAll(rc)
+		if err != nil {
+			return err
+		}
+		linkname = string(linknameData)
+	}
+	return f(file.Name, info, rc, linkname)
+}
@@ -0,0 +1,266 @@
+package chezmoi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuessArchiveFormat(t *testing.T) {
+	for _, tc := range []struct {
+		url  string
+		want string
+	}{
+		{url: "https://example.com/archive.tar", want: "tar"},
+		{url: "https://example.com/archive.tar.bz2", want: "tar.bz2"},
+		{url: "https://example.com/archive.tar.gz", want: "tar.gz"},
+		{url: "https://example.com/archive.tar.gz?raw=true", want: "tar.gz"},
+		{url: "https://example.com/archive.TGZ", want: "tar.gz"},
+		{url: "https://example.com/archive.zip", want: "zip"},
+		{url: "https://example.com/archive", want: ""},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			assert.Equal(t, tc.want, guessArchiveFormat(tc.url))
+		})
+	}
+}
+
+func TestParseExternals(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		data      map[string]interface{}
+		want      map[string]*External
+		expectErr bool
+	}{
+		{
+			name: "file",
+			data: map[string]interface{}{
+				".vim/autoload/plug.vim": map[string]interface{}{
+					"type":          "file",
+					"url":           "https://example.com/plug.vim",
+					"refreshPeriod": "168h",
+				},
+			},
+			want: map[string]*External{
+				".vim/autoload/plug.vim": {
+					Type:          ExternalTypeFile,
+					URL:           "https://example.com/plug.vim",
+					RefreshPeriod: 168 * time.Hour,
+				},
+			},
+		},
+		{
+			name: "archive",
+			data: map[string]interface{}{
+				".oh-my-zsh": map[string]interface{}{
+					"type":            "archive",
+					"url":             "https://example.com/master.tar.gz",
+					"exact":           true,
+					"stripComponents": int64(1),
+				},
+			},
+			want: map[string]*External{
+				".oh-my-zsh": {
+					Type:            ExternalTypeArchive,
+					URL:             "https://example.com/master.tar.gz",
+					Exact:           true,
+					Format:          "tar.gz",
+					StripComponents: 1,
+				},
+			},
+		},
+		{
+			name: "git_repo",
+			data: map[string]interface{}{
+				".repo": map[string]interface{}{
+					"type": "git-repo",
+					"url":  "https://example.com/repo.git",
+				},
+			},
+			want: map[string]*External{
+				".repo": {
+					Type:   ExternalTypeGitRepo,
+					URL:    "https://example.com/repo.git",
+					Format: "tar",
+				},
+			},
+		},
+		{
+			name: "unknown_type",
+			data: map[string]interface{}{
+				".file": map[string]interface{}{
+					"type": "unknown",
+					"url":  "https://example.com/file",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "missing_url",
+			data: map[string]interface{}{
+				".file": map[string]interface{}{
+					"type": "file",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid_refresh_period",
+			data: map[string]interface{}{
+				".file": map[string]interface{}{
+					"type":          "file",
+					"url":           "https://example.com/file",
+					"refreshPeriod": "weekly",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "outside_destination",
+			data: map[string]interface{}{
+				"../file": map[string]interface{}{
+					"type": "file",
+					"url":  "https://example.com/file",
+				},
+			},
+			expectErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseExternals(tc.data)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewExternalArchiveDir(t *testing.T) {
+	tarBuffer := &bytes.Buffer{}
+	tarWriter := tar.NewWriter(tarBuffer)
+	for _, header := range []struct {
+		tar.Header
+		contents string
+	}{
+		{Header: tar.Header{Typeflag: tar.TypeDir, Name: "archive/", Mode: 0o755}},
+		{Header: tar.Header{Typeflag: tar.TypeDir, Name: "archive/bin/", Mode: 0o755}},
+		{Header: tar.Header{Typeflag: tar.TypeReg, Name: "archive/bin/foo", Mode: 0o755}, contents: "#!/bin/sh\n"},
+		{Header: tar.Header{Typeflag: tar.TypeReg, Name: "archive/private/key", Mode: 0o600}, contents: "key"},
+		{Header: tar.Header{Typeflag: tar.TypeSymlink, Name: "archive/link", Linkname: "bin/foo"}},
+	} {
+		header.Size = int64(len(header.contents))
+		require.NoError(t, tarWriter.WriteHeader(&header.Header))
+		_, err := tarWriter.Write([]byte(header.contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+
+	got, err := newExternalArchiveDir(".chezmoiexternal.toml", ".archive", &External{
+		Type:            ExternalTypeArchive,
+		Exact:           true,
+		Format:          "tar",
+		StripComponents: 1,
+	}, tarBuffer.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, &Dir{
+		sourceName: ".chezmoiexternal.toml",
+		targetName: ".archive",
+		Exact:      true,
+		Perm:       0o777,
+		Entries: map[string]Entry{
+			"bin": &Dir{
+				sourceName: ".chezmoiexternal.toml",
+				targetName: ".archive/bin",
+				Exact:      true,
+				Perm:       0o777,
+				Entries: map[string]Entry{
+					"foo": &File{
+						sourceName: ".chezmoiexternal.toml",
+						targetName: ".archive/bin/foo",
+						Empty:      true,
+						Perm:       0o777,
+						contents:   []byte("#!/bin/sh\n"),
+					},
+				},
+			},
+			"private": &Dir{
+				sourceName: ".chezmoiexternal.toml",
+				targetName: ".archive/private",
+				Exact:      true,
+				Perm:       0o777,
+				Entries: map[string]Entry{
+					"key": &File{
+						sourceName: ".chezmoiexternal.toml",
+						targetName: ".archive/private/key",
+						Empty:      true,
+						Perm:       0o600,
+						contents:   []byte("key"),
+					},
+				},
+			},
+			"link": &Symlink{
+				sourceName: ".chezmoiexternal.toml",
+				targetName: ".archive/link",
+				linkname:   "bin/foo",
+			},
+		},
+	}, got)
+}
+
+func TestNewExternalArchiveDirZip(t *testing.T) {
+	zipBuffer := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(zipBuffer)
+	w, err := zipWriter.Create("dir/file")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("# contents of dir/file\n"))
+	require.NoError(t, err)
+	require.NoError(t, zipWriter.Close())
+
+	got, err := newExternalArchiveDir(".chezmoiexternal.toml", ".archive", &External{
+		Type:   ExternalTypeArchive,
+		Format: "zip",
+	}, zipBuffer.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, &Dir{
+		sourceName: ".chezmoiexternal.toml",
+		targetName: ".archive",
+		Perm:       0o777,
+		Entries: map[string]Entry{
+			"dir": &Dir{
+				sourceName: ".chezmoiexternal.toml",
+				targetName: ".archive/dir",
+				Perm:       0o777,
+				Entries: map[string]Entry{
+					"file": &File{
+						sourceName: ".chezmoiexternal.toml",
+						targetName: ".archive/dir/file",
+						Empty:      true,
+						Perm:       0o666,
+						contents:   []byte("# contents of dir/file\n"),
+					},
+				},
+			},
+		},
+	}, got)
+}
+
+func TestNewExternalArchiveDirOutsideArchive(t *testing.T) {
+	tarBuffer := &bytes.Buffer{}
+	tarWriter := tar.NewWriter(tarBuffer)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "../file", Mode: 0o644}))
+	require.NoError(t, tarWriter.Close())
+
+	_, err := newExternalArchiveDir(".chezmoiexternal.toml", ".archive", &External{
+		Type:   ExternalTypeArchive,
+		Format: "tar",
+	}, tarBuffer.Bytes())
+	assert.Error(t, err)
+}
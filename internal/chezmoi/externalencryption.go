@@ -0,0 +1,150 @@
+package chezmoi
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// An ExternalEncryption uses an arbitrary external command for encryption and
+// decryption.
+//
+// Each argument is a template executed with the fields Input, the name of the
+// file containing the input, and Output, the name of the file that the command
+// should write. If no argument uses Input then the input is passed on the
+// command's standard input. If no argument uses Output then the output is read
+// from the command's standard output.
+type ExternalEncryption struct {
+	Command     string
+	Args        []string
+	DecryptArgs []string
+	EncryptArgs []string
+}
+
+// externalEncryptionTemplateData is the data passed to ExternalEncryption
+// argument templates. It records which fields were used.
+type externalEncryptionTemplateData struct {
+	input      string
+	inputUsed  bool
+	output     string
+	outputUsed bool
+}
+
+// Decrypt implements Encryption.Decrypt.
+func (e *ExternalEncryption) Decrypt(filename string, ciphertext []byte) ([]byte, error) {
+	return e.runWithTempFiles(e.DecryptArgs, filename, ciphertext)
+}
+
+// DecryptToFile implements Encryption.DecryptToFile.
+func (e *ExternalEncryption) DecryptToFile(filename string, ciphertext []byte) error {
+	plaintext, err := e.Decrypt(filename, ciphertext)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, plaintext, 0o600)
+}
+
+// Encrypt implements Encryption.Encrypt.
+func (e *ExternalEncryption) Encrypt(filename string, plaintext []byte) ([]byte, error) {
+	return e.runWithTempFiles(e.EncryptArgs, filename, plaintext)
+}
+
+// EncryptFile implements Encryption.EncryptFile.
+func (e *ExternalEncryption) EncryptFile(filename string) ([]byte, error) {
+	plaintext, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return e.Encrypt(filename, plaintext)
+}
+
+// run runs e's command with argTemplates, reading from inputFilename and
+// writing to outputFilename.
+func (e *ExternalEncryption) run(argTemplates []string, inputFilename, outputFilename string) error {
+	data := &externalEncryptionTemplateData{
+		input:  inputFilename,
+		output: outputFilename,
+	}
+	args := make([]string, 0, len(e.Args)+len(argTemplates))
+	for _, argTemplate := range append(append([]string(nil), e.Args...), argTemplates...) {
+		tmpl, err := template.New(argTemplate).Option(DefaultTemplateOptions...).Parse(argTemplate)
+		if err != nil {
+			return err
+		}
+		sb := &strings.Builder{}
+		if err := tmpl.Execute(sb, data); err != nil {
+			return err
+		}
+		args = append(args, sb.String())
+	}
+
+	//nolint:gosec
+	cmd := exec.Command(e.Command, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if !data.inputUsed {
+		input, err := os.Open(inputFilename)
+		if err != nil {
+			return err
+		}
+		defer input.Close()
+		cmd.Stdin = input
+	}
+	if data.outputUsed {
+		return cmd.Run()
+	}
+	cmd.Stdout = nil
+	output, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(outputFilename, output, 0o600)
+}
+
+// runWithTempFiles runs e's command with argTemplates on input, using
+// temporary files named after filename, and returns its output.
+func (e *ExternalEncryption) runWithTempFiles(argTemplates []string, filename string, input []byte) ([]byte, error) {
+	tempDir, err := ioutil.TempDir("", "chezmoi-encryption")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Put the input and output files in separate directories so that they
+	// can both keep filename's base name, as some commands infer the format
+	// from the file extension.
+	var (
+		inputFilename  = filepath.Join(tempDir, "input", filepath.Base(filename))
+		outputFilename = filepath.Join(tempDir, "output", filepath.Base(filename))
+	)
+	for _, dir := range []string{filepath.Dir(inputFilename), filepath.Dir(outputFilename)} {
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+	if err := ioutil.WriteFile(inputFilename, input, 0o600); err != nil {
+		return nil, err
+	}
+
+	if err := e.run(argTemplates, inputFilename, outputFilename); err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadFile(outputFilename)
+}
+
+// Input returns the input filename and records that it was used.
+func (d *externalEncryptionTemplateData) Input() string {
+	d.inputUsed = true
+	return d.input
+}
+
+// Output returns the output filename and records that it was used.
+func (d *externalEncryptionTemplateData) Output() string {
+	d.outputUsed = true
+	return d.output
+}
@@ -0,0 +1,47 @@
+// +build !windows
+
+package chezmoi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ Encryption = &ExternalEncryption{}
+
+func TestExternalEncryption(t *testing.T) {
+	rot13Args := []string{"A-Za-z", "N-ZA-Mn-za-m"}
+	for _, tc := range []struct {
+		name               string
+		externalEncryption *ExternalEncryption
+	}{
+		{
+			name: "stdin_stdout",
+			externalEncryption: &ExternalEncryption{
+				Command:     "tr",
+				DecryptArgs: rot13Args,
+				EncryptArgs: rot13Args,
+			},
+		},
+		{
+			name: "input_output",
+			externalEncryption: &ExternalEncryption{
+				Command:     "sh",
+				Args:        []string{"-c"},
+				DecryptArgs: []string{"tr A-Za-z N-ZA-Mn-za-m < {{ .Input }} > {{ .Output }}"},
+				EncryptArgs: []string{"tr A-Za-z N-ZA-Mn-za-m < {{ .Input }} > {{ .Output }}"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ciphertext, err := tc.externalEncryption.Encrypt(".netrc", []byte("plaintext\n"))
+			require.NoError(t, err)
+			assert.Equal(t, []byte("cynvagrkg\n"), ciphertext)
+			plaintext, err := tc.externalEncryption.Decrypt(".netrc", ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("plaintext\n"), plaintext)
+		})
+	}
+}
@@ -15,8 +15,12 @@ import (
 type FileAttributes struct {
 	Name      string
 	Mode      os.FileMode
+	Block     bool
+	Create    bool
 	Empty     bool
 	Encrypted bool
+	Merge     bool
+	Modify    bool
 	Template  bool
 }
 
@@ -24,8 +28,12 @@ type FileAttributes struct {
 type File struct {
 	sourceName       string
 	targetName       string
+	Block            bool
+	Create           bool
 	Empty            bool
 	Encrypted        bool
+	Merge            bool
+	Modify           bool
 	Perm             os.FileMode
 	Template         bool
 	contents         []byte
@@ -37,8 +45,12 @@ type fileConcreteValue struct {
 	Type       string `json:"type" yaml:"type"`
 	SourcePath string `json:"sourcePath" yaml:"sourcePath"`
 	TargetPath string `json:"targetPath" yaml:"targetPath"`
+	Block      bool   `json:"block" yaml:"block"`
+	Create     bool   `json:"create" yaml:"create"`
 	Empty      bool   `json:"empty" yaml:"empty"`
 	Encrypted  bool   `json:"encrypted" yaml:"encrypted"`
+	Merge      bool   `json:"merge" yaml:"merge"`
+	Modify     bool   `json:"modify" yaml:"modify"`
 	Perm       int    `json:"perm" yaml:"perm"`
 	Template   bool   `json:"template" yaml:"template"`
 	Contents   string `json:"contents" yaml:"contents"`
@@ -48,14 +60,32 @@ type fileConcreteValue struct {
 func ParseFileAttributes(sourceName string) FileAttributes {
 	name := sourceName
 	mode := os.FileMode(0o666)
+	block := false
+	create := false
 	empty := false
 	encrypted := false
+	merge := false
+	modify := false
 	template := false
 	if strings.HasPrefix(name, symlinkPrefix) {
 		name = strings.TrimPrefix(name, symlinkPrefix)
 		mode |= os.ModeSymlink
 	} else {
 		private := false
+		switch {
+		case strings.HasPrefix(name, blockPrefix):
+			name = strings.TrimPrefix(name, blockPrefix)
+			block = true
+		case strings.HasPrefix(name, createPrefix):
+			name = strings.TrimPrefix(name, createPrefix)
+			create = true
+		case strings.HasPrefix(name, mergePrefix):
+			name = strings.TrimPrefix(name, mergePrefix)
+			merge = true
+		case strings.HasPrefix(name, modifyPrefix):
+			name = strings.TrimPrefix(name, modifyPrefix)
+			modify = true
+		}
 		if strings.HasPrefix(name, encryptedPrefix) {
 			name = strings.TrimPrefix(name, encryptedPrefix)
 			encrypted = true
@@ -86,8 +116,12 @@ func ParseFileAttributes(sourceName string) FileAttributes {
 	return FileAttributes{
 		Name:      name,
 		Mode:      mode,
+		Block:     block,
+		Create:    create,
 		Empty:     empty,
 		Encrypted: encrypted,
+		Merge:     merge,
+		Modify:    modify,
 		Template:  template,
 	}
 }
@@ -98,6 +132,16 @@ func (fa FileAttributes) SourceName() string {
 	//nolint:exhaustive
 	switch fa.Mode & os.ModeType {
 	case 0:
+		switch {
+		case fa.Block:
+			sourceName += blockPrefix
+		case fa.Create:
+			sourceName += createPrefix
+		case fa.Merge:
+			sourceName += mergePrefix
+		case fa.Modify:
+			sourceName += modifyPrefix
+		}
 		if fa.Encrypted {
 			sourceName += encryptedPrefix
 		}
@@ -136,11 +180,28 @@ func (f *File) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *Appl
 	if applyOptions.Ignore(f.targetName) {
 		return nil
 	}
-	contents, err := f.Contents()
+	targetPath := filepath.Join(applyOptions.DestDir, f.targetName)
+	if f.Create {
+		// Only create the target if it does not already exist.
+		switch _, err := fs.Lstat(targetPath); {
+		case err == nil:
+			return nil
+		case !os.IsNotExist(err):
+			return err
+		}
+	}
+	contents, err := f.TargetContents(fs, mutator, applyOptions)
 	if err != nil {
 		return err
 	}
-	targetPath := filepath.Join(applyOptions.DestDir, f.targetName)
+	if f.partial() {
+		// The rest of the target is not managed by chezmoi, so changes to it
+		// are not modifications and the target's state is not recorded.
+		partialApplyOptions := *applyOptions
+		partialApplyOptions.EntryStateBucket = nil
+		applyOptions = &partialApplyOptions
+	}
+	perm := f.Perm &^ applyOptions.Umask
 	var info os.FileInfo
 	if follow {
 		info, err = fs.Stat(targetPath)
@@ -151,22 +212,40 @@ func (f *File) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *Appl
 	switch {
 	case err == nil && info.Mode().IsRegular():
 		if isEmpty(contents) && !f.Empty {
-			return mutator.RemoveAll(targetPath)
+			if skip, err := applyOptions.skipModified(fs, f.targetName, targetPath, follow); err != nil || skip {
+				return err
+			}
+			if err := mutator.RemoveAll(targetPath); err != nil {
+				return err
+			}
+			return applyOptions.recordEntryState(fs, targetPath, follow)
 		}
 		currData, err = fs.ReadFile(targetPath)
 		if err != nil {
 			return err
 		}
+		if f.partial() {
+			perm = f.partialPerm(info.Mode().Perm(), applyOptions.Umask)
+		}
 		if !bytes.Equal(currData, contents) {
+			if skip, err := applyOptions.skipModified(fs, f.targetName, targetPath, follow); err != nil || skip {
+				return err
+			}
 			break
 		}
-		if info.Mode().Perm() != f.Perm&^applyOptions.Umask {
-			if err := mutator.Chmod(targetPath, f.Perm&^applyOptions.Umask); err != nil {
+		if info.Mode().Perm() != perm {
+			if skip, err := applyOptions.skipModified(fs, f.targetName, targetPath, follow); err != nil || skip {
+				return err
+			}
+			if err := mutator.Chmod(targetPath, perm); err != nil {
 				return err
 			}
 		}
-		return nil
+		return applyOptions.recordEntryState(fs, targetPath, follow)
 	case err == nil:
+		if skip, err := applyOptions.skipModified(fs, f.targetName, targetPath, follow); err != nil || skip {
+			return err
+		}
 		if err := mutator.RemoveAll(targetPath); err != nil {
 			return err
 		}
@@ -175,17 +254,20 @@ func (f *File) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *Appl
 		return err
 	}
 	if isEmpty(contents) && !f.Empty {
-		return nil
+		return applyOptions.recordEntryState(fs, targetPath, follow)
 	}
-	return mutator.WriteFile(targetPath, contents, f.Perm&^applyOptions.Umask, currData)
+	if err := mutator.WriteFile(targetPath, contents, perm, currData); err != nil {
+		return err
+	}
+	return applyOptions.recordEntryState(fs, targetPath, follow)
 }
 
 // ConcreteValue implements Entry.ConcreteValue.
-func (f *File) ConcreteValue(ignore func(string) bool, sourceDir string, umask os.FileMode, recursive bool) (interface{}, error) {
-	if ignore(f.targetName) {
+func (f *File) ConcreteValue(fs vfs.FS, applyOptions *ApplyOptions, sourceDir string, recursive bool) (interface{}, error) {
+	if applyOptions.Ignore(f.targetName) {
 		return nil, nil
 	}
-	contents, err := f.Contents()
+	contents, err := f.TargetContents(fs, NullMutator{}, applyOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -193,9 +275,13 @@ func (f *File) ConcreteValue(ignore func(string) bool, sourceDir string, umask o
 		Type:       "file",
 		SourcePath: filepath.Join(sourceDir, f.SourceName()),
 		TargetPath: f.TargetName(),
+		Block:      f.Block,
+		Create:     f.Create,
 		Empty:      f.Empty,
 		Encrypted:  f.Encrypted,
-		Perm:       int(f.Perm &^ umask),
+		Merge:      f.Merge,
+		Modify:     f.Modify,
+		Perm:       int(f.Perm &^ applyOptions.Umask),
 		Template:   f.Template,
 		Contents:   string(contents),
 	}, nil
@@ -229,22 +315,62 @@ func (f *File) Private() bool {
 	return f.Perm&0o77 == 0
 }
 
+// partial returns true if chezmoi only manages part of f's target.
+func (f *File) partial() bool {
+	return f.Block || f.Merge || f.Modify
+}
+
+// partialPerm returns the permissions of the existing target of a partial
+// file, which has permissions currPerm, after applying f. The existing
+// permissions are kept, except that executable_ and private_ are applied.
+func (f *File) partialPerm(currPerm, umask os.FileMode) os.FileMode {
+	perm := currPerm
+	if f.Executable() {
+		perm |= 0o111 &^ umask
+	}
+	if f.Private() {
+		perm &^= 0o77
+	}
+	return perm
+}
+
 // SourceName implements Entry.SourceName.
 func (f *File) SourceName() string {
 	return f.sourceName
 }
 
+// TargetContents returns the contents of f in the destination directory. For
+// block_, merge_, and modify_ files these are computed from the current
+// contents of the target in fs.
+func (f *File) TargetContents(fs vfs.FS, mutator Mutator, applyOptions *ApplyOptions) ([]byte, error) {
+	contents, err := f.Contents()
+	if err != nil {
+		return nil, err
+	}
+	targetPath := filepath.Join(applyOptions.DestDir, f.targetName)
+	switch {
+	case f.Block:
+		return f.blockContents(fs, targetPath, contents, applyOptions.BlockName)
+	case f.Merge:
+		return f.mergeContents(fs, targetPath, contents)
+	case f.Modify:
+		return f.modifyContents(fs, mutator, targetPath, contents, applyOptions)
+	default:
+		return contents, nil
+	}
+}
+
 // TargetName implements Entry.TargetName.
 func (f *File) TargetName() string {
 	return f.targetName
 }
 
 // archive writes f to w.
-func (f *File) archive(w *tar.Writer, ignore func(string) bool, headerTemplate *tar.Header, umask os.FileMode) error {
-	if ignore(f.targetName) {
+func (f *File) archive(w *tar.Writer, fs vfs.FS, applyOptions *ApplyOptions, headerTemplate *tar.Header) error {
+	if applyOptions.Ignore(f.targetName) {
 		return nil
 	}
-	contents, err := f.Contents()
+	contents, err := f.TargetContents(fs, NullMutator{}, applyOptions)
 	if err != nil {
 		return err
 	}
@@ -255,10 +381,83 @@ func (f *File) archive(w *tar.Writer, ignore func(string) bool, headerTemplate *
 	header.Typeflag = tar.TypeReg
 	header.Name = f.targetName
 	header.Size = int64(len(contents))
-	header.Mode = int64(f.Perm &^ umask)
+	header.Mode = int64(f.Perm &^ applyOptions.Umask)
 	if err := w.WriteHeader(&header); err != nil {
 		return nil
 	}
 	_, err = w.Write(contents)
 	return err
 }
+
+// blockContents returns the current contents of targetPath with the block
+// named name, or f's target name if name is empty, replaced by block.
+func (f *File) blockContents(fs vfs.FS, targetPath string, block []byte, name string) ([]byte, error) {
+	currContents, err := readCurrContents(fs, targetPath)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = filepath.ToSlash(f.targetName)
+	}
+	contents, err := replaceBlock(currContents, name, block)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.targetName, err)
+	}
+	return contents, nil
+}
+
+// mergeContents returns the result of merging fragment into the current
+// contents of targetPath.
+func (f *File) mergeContents(fs vfs.FS, targetPath string, fragment []byte) ([]byte, error) {
+	currContents, err := readCurrContents(fs, targetPath)
+	if err != nil {
+		return nil, err
+	}
+	return mergeContents(f.targetName, currContents, fragment)
+}
+
+// modifyContents returns the contents of targetPath in fs after modifying them
+// with f's modify script, given by script. The current contents are passed to
+// the script on its stdin and the new contents are read from its stdout. The
+// script is run with mutator.IdempotentCmdOutput, so it is run even in dry run
+// mode and must not have any side effects.
+func (f *File) modifyContents(fs vfs.FS, mutator Mutator, targetPath string, script []byte, applyOptions *ApplyOptions) ([]byte, error) {
+	currContents, err := readCurrContents(fs, targetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	scriptFilename, err := writeTempScript(f.targetName, script)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(scriptFilename)
+	}()
+
+	cmd := interpreterCmd(applyOptions.Interpreters, f.targetName, scriptFilename)
+	cmd.Dir = scriptWorkingDir(applyOptions.DestDir, f.targetName)
+	if len(applyOptions.ScriptEnv) != 0 {
+		cmd.Env = append(os.Environ(), applyOptions.ScriptEnv...)
+	}
+	cmd.Stdin = bytes.NewReader(currContents)
+	cmd.Stderr = os.Stderr
+	contents, err := mutator.IdempotentCmdOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.targetName, err)
+	}
+	return contents, nil
+}
+
+// readCurrContents returns the contents of targetPath if it is a regular file,
+// or nil if it does not exist.
+func readCurrContents(fs vfs.FS, targetPath string) ([]byte, error) {
+	switch info, err := fs.Stat(targetPath); {
+	case err == nil && info.Mode().IsRegular():
+		return fs.ReadFile(targetPath)
+	case err == nil || os.IsNotExist(err):
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
@@ -0,0 +1,81 @@
+// +build !windows
+
+package chezmoi
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-vfs/vfst"
+)
+
+func TestFileApplyPartial(t *testing.T) {
+	fs, cleanup, err := vfst.NewTestFS(map[string]interface{}{
+		"/home/user": map[string]interface{}{
+			".profile": &vfst.File{
+				Perm:     0o600,
+				Contents: []byte("export PATH=$HOME/bin:$PATH\n"),
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer cleanup()
+
+	var modifiedTargetNames []string
+	persistentState := NewMemoryPersistentState()
+	applyOptions := &ApplyOptions{
+		DestDir:          "/home/user",
+		EntryStateBucket: []byte("entryState"),
+		Ignore:           func(string) bool { return false },
+		Modified: func(targetName string) (bool, error) {
+			modifiedTargetNames = append(modifiedTargetNames, targetName)
+			return false, nil
+		},
+		PersistentState: persistentState,
+		Umask:           0o22,
+	}
+	apply := func(perm os.FileMode, block string) {
+		f := &File{
+			sourceName: "block_dot_profile",
+			targetName: ".profile",
+			Block:      true,
+			Perm:       perm,
+			contents:   []byte(block),
+		}
+		require.NoError(t, f.Apply(fs, NewFSMutator(fs), false, applyOptions))
+	}
+
+	// Test that the block is added without changing the file's permissions or
+	// recording its state.
+	apply(0o666, "export EDITOR=vi\n")
+	vfst.RunTests(t, fs, "",
+		vfst.TestPath("/home/user/.profile",
+			vfst.TestModePerm(0o600),
+			vfst.TestContentsString("export PATH=$HOME/bin:$PATH\n# BEGIN chezmoi .profile\nexport EDITOR=vi\n# END chezmoi\n"),
+		),
+	)
+	entryState, err := persistentState.Get(applyOptions.EntryStateBucket, []byte("/home/user/.profile"))
+	require.NoError(t, err)
+	assert.Nil(t, entryState)
+
+	// Test that changes to the rest of the file are not modifications.
+	require.NoError(t, fs.WriteFile("/home/user/.profile", []byte("# BEGIN chezmoi .profile\nexport EDITOR=vi\n# END chezmoi\nexport PATH=/bin\n"), 0o600))
+	apply(0o666, "export EDITOR=emacs\n")
+	assert.Nil(t, modifiedTargetNames)
+	vfst.RunTests(t, fs, "",
+		vfst.TestPath("/home/user/.profile",
+			vfst.TestModePerm(0o600),
+			vfst.TestContentsString("# BEGIN chezmoi .profile\nexport EDITOR=emacs\n# END chezmoi\nexport PATH=/bin\n"),
+		),
+	)
+
+	// Test that executable_ is applied to the existing permissions.
+	apply(0o777, "export EDITOR=emacs\n")
+	vfst.RunTests(t, fs, "",
+		vfst.TestPath("/home/user/.profile",
+			vfst.TestModePerm(0o711),
+		),
+	)
+}
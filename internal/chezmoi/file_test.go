@@ -114,6 +114,75 @@ func TestFileAttributes(t *testing.T) {
 				Encrypted: true,
 			},
 		},
+		{
+			sourceName: "modify_dot_foo",
+			fa: FileAttributes{
+				Name:   ".foo",
+				Mode:   0o666,
+				Modify: true,
+			},
+		},
+		{
+			sourceName: "modify_encrypted_private_executable_dot_foo.tmpl",
+			fa: FileAttributes{
+				Name:      ".foo",
+				Mode:      0o700,
+				Encrypted: true,
+				Modify:    true,
+				Template:  true,
+			},
+		},
+		{
+			sourceName: "create_dot_foo",
+			fa: FileAttributes{
+				Name:   ".foo",
+				Mode:   0o666,
+				Create: true,
+			},
+		},
+		{
+			sourceName: "block_dot_profile",
+			fa: FileAttributes{
+				Name:  ".profile",
+				Mode:  0o666,
+				Block: true,
+			},
+		},
+		{
+			sourceName: "block_private_authorized_keys.tmpl",
+			fa: FileAttributes{
+				Name:     "authorized_keys",
+				Mode:     0o600,
+				Block:    true,
+				Template: true,
+			},
+		},
+		{
+			sourceName: "merge_dot_foo.json",
+			fa: FileAttributes{
+				Name:  ".foo.json",
+				Mode:  0o666,
+				Merge: true,
+			},
+		},
+		{
+			sourceName: "merge_private_dot_foo.ini.tmpl",
+			fa: FileAttributes{
+				Name:     ".foo.ini",
+				Mode:     0o600,
+				Merge:    true,
+				Template: true,
+			},
+		},
+		{
+			sourceName: "create_private_executable_dot_foo.tmpl",
+			fa: FileAttributes{
+				Name:     ".foo",
+				Mode:     0o700,
+				Create:   true,
+				Template: true,
+			},
+		},
 	} {
 		t.Run(tc.sourceName, func(t *testing.T) {
 			assert.Equal(t, tc.fa, ParseFileAttributes(tc.sourceName))
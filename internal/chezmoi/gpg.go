@@ -14,8 +14,7 @@ type GPG struct {
 	Symmetric bool
 }
 
-// Decrypt decrypts ciphertext. filename is used as a hint for naming temporary
-// files.
+// Decrypt implements Encryption.Decrypt.
 func (g *GPG) Decrypt(filename string, ciphertext []byte) ([]byte, error) {
 	tempDir, err := ioutil.TempDir("", "chezmoi-decrypt")
 	if err != nil {
@@ -24,30 +23,40 @@ func (g *GPG) Decrypt(filename string, ciphertext []byte) ([]byte, error) {
 	defer os.RemoveAll(tempDir)
 
 	outputFilename := filepath.Join(tempDir, filepath.Base(filename))
-	inputFilename := outputFilename + ".gpg"
-	if err := ioutil.WriteFile(inputFilename, ciphertext, 0o600); err != nil {
+	if err := g.DecryptToFile(outputFilename, ciphertext); err != nil {
 		return nil, err
 	}
 
+	return ioutil.ReadFile(outputFilename)
+}
+
+// DecryptToFile implements Encryption.DecryptToFile.
+func (g *GPG) DecryptToFile(filename string, ciphertext []byte) error {
+	tempDir, err := ioutil.TempDir("", "chezmoi-decrypt")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFilename := filepath.Join(tempDir, filepath.Base(filename)+".gpg")
+	if err := ioutil.WriteFile(inputFilename, ciphertext, 0o600); err != nil {
+		return err
+	}
+
 	//nolint:gosec
 	cmd := exec.Command(
 		g.Command,
-		"--output", outputFilename,
+		"--output", filename,
 		"--quiet",
 		"--decrypt", inputFilename,
 	)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return nil, err
-	}
-
-	return ioutil.ReadFile(outputFilename)
+	return cmd.Run()
 }
 
-// Encrypt encrypts plaintext for ts's recipient. filename is used as a hint for
-// naming temporary files.
+// Encrypt implements Encryption.Encrypt.
 func (g *GPG) Encrypt(filename string, plaintext []byte) ([]byte, error) {
 	tempDir, err := ioutil.TempDir("", "chezmoi-encrypt")
 	if err != nil {
@@ -59,7 +68,20 @@ func (g *GPG) Encrypt(filename string, plaintext []byte) ([]byte, error) {
 	if err := ioutil.WriteFile(inputFilename, plaintext, 0o600); err != nil {
 		return nil, err
 	}
-	outputFilename := inputFilename + ".gpg"
+
+	return g.EncryptFile(inputFilename)
+}
+
+// EncryptFile implements Encryption.EncryptFile. The file is encrypted for g's
+// recipient.
+func (g *GPG) EncryptFile(filename string) ([]byte, error) {
+	tempDir, err := ioutil.TempDir("", "chezmoi-encrypt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	outputFilename := filepath.Join(tempDir, filepath.Base(filename)+".gpg")
 
 	args := []string{
 		"--armor",
@@ -0,0 +1,28 @@
+package chezmoi
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// An Interpreter interprets scripts.
+type Interpreter struct {
+	Command string
+	Args    []string
+}
+
+// interpreterCmd returns the command to run the script in filename. If
+// interpreters contains an interpreter for the extension of name then the
+// script is passed as an argument to the interpreter, otherwise the script is
+// executed directly.
+func interpreterCmd(interpreters map[string]*Interpreter, name, filename string) *exec.Cmd {
+	extension := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	if interpreter, ok := interpreters[extension]; ok && interpreter != nil && interpreter.Command != "" {
+		args := append(append([]string{}, interpreter.Args...), filename)
+		//nolint:gosec
+		return exec.Command(interpreter.Command, args...)
+	}
+	//nolint:gosec
+	return exec.Command(filename)
+}
@@ -0,0 +1,42 @@
+package chezmoi
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpreterCmd(t *testing.T) {
+	interpreters := map[string]*Interpreter{
+		"py": {
+			Command: "python3",
+		},
+		"ps1": {
+			Command: "powershell",
+			Args:    []string{"-NoLogo", "-File"},
+		},
+	}
+	for _, tc := range []struct {
+		name         string
+		expectedArgs []string
+	}{
+		{
+			name:         "script.sh",
+			expectedArgs: []string{"/tmp/script.sh"},
+		},
+		{
+			name:         "script.py",
+			expectedArgs: []string{"python3", "/tmp/script.py"},
+		},
+		{
+			name:         "dir/script.PS1",
+			expectedArgs: []string{"powershell", "-NoLogo", "-File", "/tmp/script.PS1"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := interpreterCmd(interpreters, tc.name, path.Join("/tmp", path.Base(tc.name)))
+			assert.Equal(t, tc.expectedArgs, c.Args)
+		})
+	}
+}
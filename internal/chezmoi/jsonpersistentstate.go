@@ -0,0 +1,184 @@
+package chezmoi
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	vfs "github.com/twpayne/go-vfs"
+)
+
+// A JSONPersistentState is a state persisted in a JSON file. The file is
+// re-read before every operation and written atomically and durably after every
+// change. Changes hold an exclusive lock on a separate lock file for the whole
+// read-modify-write, so that concurrent changes from several processes are not
+// lost.
+type JSONPersistentState struct {
+	fs          vfs.FS
+	path        string
+	lockTimeout time.Duration
+}
+
+// NewJSONPersistentState returns a new JSONPersistentState. Changes fail if the
+// lock cannot be taken within lockTimeout.
+func NewJSONPersistentState(fs vfs.FS, path string, lockTimeout time.Duration) (*JSONPersistentState, error) {
+	s := &JSONPersistentState{
+		fs:          fs,
+		path:        path,
+		lockTimeout: lockTimeout,
+	}
+	if _, err := s.read(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes s.
+func (s *JSONPersistentState) Close() error {
+	return nil
+}
+
+// Data returns all the data in s, keyed by bucket and then by key.
+func (s *JSONPersistentState) Data() (map[string]map[string][]byte, error) {
+	return s.read()
+}
+
+// Delete deletes the value associated with key in bucket. If bucket or key
+// does not exist then Delete does nothing.
+func (s *JSONPersistentState) Delete(bucket, key []byte) error {
+	return s.update(func(data map[string]map[string][]byte) bool {
+		bucketData, ok := data[string(bucket)]
+		if !ok {
+			return false
+		}
+		if _, ok := bucketData[string(key)]; !ok {
+			return false
+		}
+		delete(bucketData, string(key))
+		return true
+	})
+}
+
+// DeleteBucket deletes bucket and all its contents. If bucket does not exist
+// then DeleteBucket does nothing.
+func (s *JSONPersistentState) DeleteBucket(bucket []byte) error {
+	return s.update(func(data map[string]map[string][]byte) bool {
+		if _, ok := data[string(bucket)]; !ok {
+			return false
+		}
+		delete(data, string(bucket))
+		return true
+	})
+}
+
+// Get returns the value associated with key in bucket.
+func (s *JSONPersistentState) Get(bucket, key []byte) ([]byte, error) {
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return data[string(bucket)][string(key)], nil
+}
+
+// Set sets the value associated with key in bucket. bucket will be created if
+// it does not already exist.
+func (s *JSONPersistentState) Set(bucket, key, value []byte) error {
+	return s.update(func(data map[string]map[string][]byte) bool {
+		bucketData, ok := data[string(bucket)]
+		if !ok {
+			bucketData = make(map[string][]byte)
+			data[string(bucket)] = bucketData
+		}
+		bucketData[string(key)] = copyBytes(value)
+		return true
+	})
+}
+
+// read reads the data in s's file. If the file does not exist then it returns
+// empty data.
+func (s *JSONPersistentState) read() (map[string]map[string][]byte, error) {
+	data := make(map[string]map[string][]byte)
+	contents, err := s.fs.ReadFile(s.path)
+	switch {
+	case os.IsNotExist(err):
+		return data, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(contents, &data); err != nil {
+		return nil, &os.PathError{Op: "read", Path: s.path, Err: err}
+	}
+	return data, nil
+}
+
+// update applies f to the data in s's file while holding the lock, and writes
+// the data back if f returns true.
+func (s *JSONPersistentState) update(f func(map[string]map[string][]byte) bool) (err error) {
+	if err := vfs.MkdirAll(s.fs, filepath.Dir(s.path), 0o777); err != nil {
+		return err
+	}
+	unlock, err := lockFile(s.fs, s.path+".lock", s.lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if unlockErr := unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	if !f(data) {
+		return nil
+	}
+	return s.write(data)
+}
+
+// write atomically writes data to s's file by writing and syncing a
+// uniquely-named temporary file in the same directory and then renaming it.
+func (s *JSONPersistentState) write(data map[string]map[string][]byte) error {
+	contents, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tempPath, f, err := s.createTempFile()
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(contents, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = s.fs.Rename(tempPath, s.path)
+	}
+	if err != nil {
+		_ = s.fs.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+// createTempFile creates a new, uniquely-named temporary file next to s's file
+// and returns its path.
+func (s *JSONPersistentState) createTempFile() (string, *os.File, error) {
+	for {
+		var suffix [8]byte
+		if _, err := rand.Read(suffix[:]); err != nil {
+			return "", nil, err
+		}
+		tempPath := s.path + "." + hex.EncodeToString(suffix[:]) + ".tmp"
+		f, err := s.fs.OpenFile(tempPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o666)
+		if !os.IsExist(err) {
+			return tempPath, f, err
+		}
+	}
+}
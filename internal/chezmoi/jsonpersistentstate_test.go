@@ -0,0 +1,118 @@
+package chezmoi
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-vfs/vfst"
+)
+
+var _ PersistentState = &JSONPersistentState{}
+
+func TestJSONPersistentState(t *testing.T) {
+	fs, cleanup, err := vfst.NewTestFS(map[string]interface{}{
+		"/home/user/.config/chezmoi": &vfst.Dir{Perm: 0o755},
+	})
+	require.NoError(t, err)
+	defer cleanup()
+
+	path := "/home/user/.config/chezmoi/chezmoistate.json"
+	s, err := NewJSONPersistentState(fs, path, time.Second)
+	require.NoError(t, err)
+	testPersistentState(t, s)
+
+	var (
+		bucket = []byte("bucket")
+		key    = []byte("key")
+		value  = []byte("value")
+	)
+
+	s, err = NewJSONPersistentState(fs, path, time.Second)
+	require.NoError(t, err)
+	require.NoError(t, s.Set(bucket, key, value))
+	require.NoError(t, s.Close())
+	vfst.RunTests(t, fs, "",
+		vfst.TestPath(path,
+			vfst.TestModeIsRegular,
+		),
+	)
+	infos, err := fs.ReadDir("/home/user/.config/chezmoi")
+	require.NoError(t, err)
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	assert.Equal(t, []string{"chezmoistate.json", "chezmoistate.json.lock"}, names)
+
+	s, err = NewJSONPersistentState(fs, path, time.Second)
+	require.NoError(t, err)
+	actualValue, err := s.Get(bucket, key)
+	require.NoError(t, err)
+	assert.Equal(t, value, actualValue)
+}
+
+func TestJSONPersistentStateConcurrent(t *testing.T) {
+	fs, cleanup, err := vfst.NewTestFS(map[string]interface{}{
+		"/home/user/.config/chezmoi": &vfst.Dir{Perm: 0o755},
+	})
+	require.NoError(t, err)
+	defer cleanup()
+
+	path := "/home/user/.config/chezmoi/chezmoistate.json"
+	bucket := []byte("bucket")
+	n := 16
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			s, err := NewJSONPersistentState(fs, path, 10*time.Second)
+			if err != nil {
+				errs <- err
+				return
+			}
+			errs <- s.Set(bucket, []byte(strconv.Itoa(i)), []byte("value"))
+		}(i)
+	}
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	s, err := NewJSONPersistentState(fs, path, time.Second)
+	require.NoError(t, err)
+	data, err := s.Data()
+	require.NoError(t, err)
+	assert.Len(t, data[string(bucket)], n)
+}
+
+func TestJSONPersistentStateLockTimeout(t *testing.T) {
+	fs, cleanup, err := vfst.NewTestFS(map[string]interface{}{
+		"/home/user/.config/chezmoi": &vfst.Dir{Perm: 0o755},
+	})
+	require.NoError(t, err)
+	defer cleanup()
+
+	path := "/home/user/.config/chezmoi/chezmoistate.json"
+	unlock, err := lockFile(fs, path+".lock", time.Second)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, unlock())
+	}()
+
+	s, err := NewJSONPersistentState(fs, path, 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, errors.Is(s.Set([]byte("bucket"), []byte("key"), []byte("value")), errLockTimeout))
+}
+
+func TestJSONPersistentStateInvalid(t *testing.T) {
+	fs, cleanup, err := vfst.NewTestFS(map[string]interface{}{
+		"/home/user/.config/chezmoi/chezmoistate.json": "invalid",
+	})
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, err = NewJSONPersistentState(fs, "/home/user/.config/chezmoi/chezmoistate.json", time.Second)
+	assert.Error(t, err)
+}
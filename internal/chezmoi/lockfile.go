@@ -0,0 +1,41 @@
+package chezmoi
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	vfs "github.com/twpayne/go-vfs"
+)
+
+// lockFileRetryInterval is the interval between attempts to take a lock.
+const lockFileRetryInterval = 10 * time.Millisecond
+
+// errLockTimeout is returned when a lock cannot be taken within the timeout.
+var errLockTimeout = errors.New("timeout")
+
+// lockFile creates the file name in fs if needed and takes an exclusive lock on
+// it, retrying until timeout has elapsed. It returns a function that releases
+// the lock.
+func lockFile(fs vfs.FS, name string, timeout time.Duration) (func() error, error) {
+	f, err := fs.OpenFile(name, os.O_CREATE|os.O_RDWR, 0o666)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLockFile(f)
+		switch {
+		case err != nil:
+			f.Close()
+			return nil, &os.PathError{Op: "lock", Path: name, Err: err}
+		case locked:
+			// Closing the file releases the lock.
+			return f.Close, nil
+		case time.Now().After(deadline):
+			f.Close()
+			return nil, &os.PathError{Op: "lock", Path: name, Err: errLockTimeout}
+		}
+		time.Sleep(lockFileRetryInterval)
+	}
+}
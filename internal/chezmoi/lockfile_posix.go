@@ -0,0 +1,21 @@
+// +build !windows
+
+package chezmoi
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLockFile tries to take an exclusive lock on f without blocking. It returns
+// false if the lock is held by someone else.
+func tryLockFile(f *os.File) (bool, error) {
+	switch err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err {
+	case nil:
+		return true, nil
+	case syscall.EWOULDBLOCK:
+		return false, nil
+	default:
+		return false, err
+	}
+}
@@ -0,0 +1,23 @@
+// +build windows
+
+package chezmoi
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// tryLockFile tries to take an exclusive lock on f without blocking. It returns
+// false if the lock is held by someone else.
+func tryLockFile(f *os.File) (bool, error) {
+	overlapped := &windows.Overlapped{}
+	switch err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, overlapped); err {
+	case nil:
+		return true, nil
+	case windows.ERROR_LOCK_VIOLATION:
+		return false, nil
+	default:
+		return false, err
+	}
+}
@@ -0,0 +1,60 @@
+package chezmoi
+
+// A MemoryPersistentState is a state persisted in memory.
+type MemoryPersistentState struct {
+	data map[string]map[string][]byte
+}
+
+// NewMemoryPersistentState returns a new MemoryPersistentState.
+func NewMemoryPersistentState() *MemoryPersistentState {
+	return &MemoryPersistentState{
+		data: make(map[string]map[string][]byte),
+	}
+}
+
+// Close closes s.
+func (s *MemoryPersistentState) Close() error {
+	return nil
+}
+
+// Data returns all the data in s, keyed by bucket and then by key.
+func (s *MemoryPersistentState) Data() (map[string]map[string][]byte, error) {
+	return copyPersistentStateData(s.data), nil
+}
+
+// Delete deletes the value associated with key in bucket. If bucket or key
+// does not exist then Delete does nothing.
+func (s *MemoryPersistentState) Delete(bucket, key []byte) error {
+	if bucketData, ok := s.data[string(bucket)]; ok {
+		delete(bucketData, string(key))
+	}
+	return nil
+}
+
+// DeleteBucket deletes bucket and all its contents. If bucket does not exist
+// then DeleteBucket does nothing.
+func (s *MemoryPersistentState) DeleteBucket(bucket []byte) error {
+	delete(s.data, string(bucket))
+	return nil
+}
+
+// Get returns the value associated with key in bucket.
+func (s *MemoryPersistentState) Get(bucket, key []byte) ([]byte, error) {
+	value, ok := s.data[string(bucket)][string(key)]
+	if !ok {
+		return nil, nil
+	}
+	return copyBytes(value), nil
+}
+
+// Set sets the value associated with key in bucket. bucket will be created if
+// it does not already exist.
+func (s *MemoryPersistentState) Set(bucket, key, value []byte) error {
+	bucketData, ok := s.data[string(bucket)]
+	if !ok {
+		bucketData = make(map[string][]byte)
+		s.data[string(bucket)] = bucketData
+	}
+	bucketData[string(key)] = copyBytes(value)
+	return nil
+}
@@ -0,0 +1,11 @@
+package chezmoi
+
+import (
+	"testing"
+)
+
+var _ PersistentState = &MemoryPersistentState{}
+
+func TestMemoryPersistentState(t *testing.T) {
+	testPersistentState(t, NewMemoryPersistentState())
+}
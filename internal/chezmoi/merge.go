@@ -0,0 +1,445 @@
+package chezmoi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	ini "gopkg.in/ini.v1"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A mergeFunc merges fragment into the document dest and returns the result.
+type mergeFunc func(dest, fragment []byte) ([]byte, error)
+
+// mergeFormats maps file extensions of merge_ targets to functions that merge
+// fragments into them.
+var mergeFormats = map[string]mergeFunc{
+	"gitconfig": mergeINI,
+	"ini":       mergeINI,
+	"json":      mergeJSON,
+	"toml":      mergeMap(dataFormats["toml"], encodeTOML),
+	"yaml":      mergeMap(dataFormats["yaml"], yaml.Marshal),
+	"yml":       mergeMap(dataFormats["yaml"], yaml.Marshal),
+}
+
+// mergeContents merges fragment into dest, using the format determined by
+// targetName's extension.
+func mergeContents(targetName string, dest, fragment []byte) ([]byte, error) {
+	format := strings.TrimPrefix(filepath.Ext(targetName), ".")
+	merge, ok := mergeFormats[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("%s: cannot determine merge format", targetName)
+	}
+	contents, err := merge(dest, fragment)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", targetName, err)
+	}
+	return contents, nil
+}
+
+// mergeINI merges the keys of every section in fragment into dest. The values
+// of each key in fragment, including keys that are repeated like git's
+// remote.<name>.fetch, replace all values of the same key in dest, and keys
+// that are not in dest are added to the end of their section. All other lines
+// of dest are preserved exactly.
+func mergeINI(dest, fragment []byte) ([]byte, error) {
+	// Load both documents with go-ini to report syntax errors, allowing
+	// repeated keys.
+	loadOptions := ini.LoadOptions{
+		AllowShadows: true,
+	}
+	if _, err := ini.LoadSources(loadOptions, dest); err != nil {
+		return nil, err
+	}
+	if _, err := ini.LoadSources(loadOptions, fragment); err != nil {
+		return nil, err
+	}
+
+	destSections := parseINISections(dest)
+	fragmentSections := parseINISections(fragment)
+
+	// Collect the keys in fragment, in order, and their lines.
+	fragmentKeys := make(map[string][]string)
+	fragmentLines := make(map[string]map[string][]string)
+	for _, section := range fragmentSections {
+		if fragmentLines[section.name] == nil {
+			fragmentLines[section.name] = make(map[string][]string)
+		}
+		for _, line := range section.lines {
+			if line.key == "" {
+				continue
+			}
+			if _, ok := fragmentLines[section.name][line.key]; !ok {
+				fragmentKeys[section.name] = append(fragmentKeys[section.name], line.key)
+			}
+			fragmentLines[section.name][line.key] = append(fragmentLines[section.name][line.key], strings.TrimSpace(line.text))
+		}
+	}
+
+	// New keys are added to the last occurrence of their section in dest.
+	lastDestSection := make(map[string]int)
+	for i, section := range destSections {
+		lastDestSection[section.name] = i
+	}
+
+	b := &strings.Builder{}
+	written := make(map[string]bool)
+	for i, section := range destSections {
+		var lines []string
+		// end is the index in lines after the section's header and last key.
+		end := 0
+		if i != 0 {
+			end = 1
+		}
+		indent := ""
+		for _, line := range section.lines {
+			values, ok := fragmentLines[section.name][line.key]
+			switch {
+			case line.key == "" || !ok:
+				lines = append(lines, line.text)
+				if line.key != "" {
+					end = len(lines)
+					indent = iniIndent(line.text)
+				}
+			case !written[section.name+"\x00"+line.key]:
+				indent = iniIndent(line.text)
+				for _, value := range values {
+					lines = append(lines, indent+value+"\n")
+				}
+				written[section.name+"\x00"+line.key] = true
+				end = len(lines)
+			}
+		}
+		if lastDestSection[section.name] == i {
+			var newLines []string
+			for _, key := range fragmentKeys[section.name] {
+				if written[section.name+"\x00"+key] {
+					continue
+				}
+				for _, value := range fragmentLines[section.name][key] {
+					newLines = append(newLines, indent+value+"\n")
+				}
+				written[section.name+"\x00"+key] = true
+			}
+			lines = append(lines[:end], append(newLines, lines[end:]...)...)
+		}
+		for _, line := range lines {
+			b.WriteString(line)
+		}
+	}
+
+	// Append sections that are not in dest.
+	for _, section := range fragmentSections {
+		if _, ok := lastDestSection[section.name]; ok {
+			continue
+		}
+		for _, line := range section.lines {
+			b.WriteString(line.text)
+		}
+	}
+
+	return []byte(b.String()), nil
+}
+
+// An iniSection is a section of an INI document. Every section except the
+// default section, which has an empty name, starts with its header line.
+type iniSection struct {
+	name  string
+	lines []iniLine
+}
+
+// An iniLine is a line of an INI document, including its trailing newline,
+// and the key that it sets, if any.
+type iniLine struct {
+	key  string
+	text string
+}
+
+// parseINISections splits data, which must be a valid INI document, into its
+// sections.
+func parseINISections(data []byte) []*iniSection {
+	s := string(data)
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	sections := []*iniSection{{}}
+	for _, text := range strings.SplitAfter(s, "\n") {
+		if text == "" {
+			continue
+		}
+		trimmed := strings.TrimSpace(text)
+		switch {
+		case strings.HasPrefix(trimmed, "["):
+			name := strings.TrimSpace(trimmed[1:strings.Index(trimmed, "]")])
+			sections = append(sections, &iniSection{
+				name:  name,
+				lines: []iniLine{{text: text}},
+			})
+		case trimmed == "" || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#"):
+			section := sections[len(sections)-1]
+			section.lines = append(section.lines, iniLine{text: text})
+		default:
+			key := trimmed
+			if i := strings.IndexAny(trimmed, "=:"); i != -1 {
+				key = strings.TrimSpace(trimmed[:i])
+			}
+			section := sections[len(sections)-1]
+			section.lines = append(section.lines, iniLine{key: key, text: text})
+		}
+	}
+	return sections
+}
+
+// iniIndent returns the leading whitespace of line.
+func iniIndent(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// mergeMap returns a mergeFunc that decodes dest and fragment with decode,
+// deep-merges fragment into dest, and encodes the result with encode.
+func mergeMap(decode func([]byte) (map[string]interface{}, error), encode func(interface{}) ([]byte, error)) mergeFunc {
+	return func(dest, fragment []byte) ([]byte, error) {
+		destData := make(map[string]interface{})
+		if len(bytes.TrimSpace(dest)) != 0 {
+			var err error
+			destData, err = decode(dest)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(bytes.TrimSpace(fragment)) != 0 {
+			fragmentData, err := decode(fragment)
+			if err != nil {
+				return nil, err
+			}
+			if destData == nil {
+				destData = make(map[string]interface{})
+			}
+			mergeData(destData, fragmentData)
+		}
+		return encode(destData)
+	}
+}
+
+// mergeJSON merges the object fragment into the object dest. dest and
+// fragment may contain comments and trailing commas, as in JSONC files like
+// Visual Studio Code's settings.json, but comments are not preserved. The order
+// of keys in dest is preserved and new keys are added after them. Numbers are
+// preserved exactly.
+func mergeJSON(dest, fragment []byte) ([]byte, error) {
+	destObject := newJSONObject()
+	if dest = stripJSONComments(dest); len(bytes.TrimSpace(dest)) != 0 {
+		var err error
+		destObject, err = decodeJSONObject(dest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if fragment = stripJSONComments(fragment); len(bytes.TrimSpace(fragment)) != 0 {
+		fragmentObject, err := decodeJSONObject(fragment)
+		if err != nil {
+			return nil, err
+		}
+		destObject.merge(fragmentObject)
+	}
+	b := &bytes.Buffer{}
+	e := json.NewEncoder(b)
+	e.SetEscapeHTML(false)
+	e.SetIndent("", "  ")
+	if err := e.Encode(destObject); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// A jsonObject is a JSON object that preserves the order of its keys.
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newJSONObject() *jsonObject {
+	return &jsonObject{
+		values: make(map[string]interface{}),
+	}
+}
+
+// decodeJSONObject decodes data, which must contain a single JSON object.
+func decodeJSONObject(data []byte) (*jsonObject, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	value, err := decodeJSONValue(d)
+	if err != nil {
+		return nil, err
+	}
+	object, ok := value.(*jsonObject)
+	if !ok {
+		return nil, errors.New("not a JSON object")
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, errors.New("invalid data after JSON object")
+	}
+	return object, nil
+}
+
+// decodeJSONValue decodes the next value from d, decoding objects as
+// *jsonObjects.
+func decodeJSONValue(d *json.Decoder) (interface{}, error) {
+	token, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := newJSONObject()
+		for d.More() {
+			keyToken, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyToken.(string)
+			value, err := decodeJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			object.set(key, value)
+		}
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return object, nil
+	case json.Delim('['):
+		array := []interface{}{}
+		for d.More() {
+			value, err := decodeJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return array, nil
+	default:
+		return token, nil
+	}
+}
+
+// MarshalJSON implements encoding/json.Marshaler.
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	b := &bytes.Buffer{}
+	e := json.NewEncoder(b)
+	e.SetEscapeHTML(false)
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+		if err := e.Encode(key); err != nil {
+			return nil, err
+		}
+		b.WriteByte(':')
+		if err := e.Encode(o.values[key]); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// merge recursively merges other into o. Values in other take precedence over
+// values in o, except that objects are merged.
+func (o *jsonObject) merge(other *jsonObject) {
+	for _, key := range other.keys {
+		otherObject, otherOK := other.values[key].(*jsonObject)
+		object, ok := o.values[key].(*jsonObject)
+		if otherOK && ok {
+			object.merge(otherObject)
+		} else {
+			o.set(key, other.values[key])
+		}
+	}
+}
+
+// set sets the value of key in o to value, adding key to the end of o's keys
+// if it is not already present.
+func (o *jsonObject) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// stripJSONComments returns data with all comments and trailing commas
+// removed, converting JSONC to JSON.
+func stripJSONComments(data []byte) []byte {
+	result := make([]byte, 0, len(data))
+	// comma is the index in result of a comma that might be a trailing comma,
+	// or -1.
+	comma := -1
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			start := i
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			if i == len(data) {
+				i--
+			}
+			result = append(result, data[start:i+1]...)
+			comma = -1
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				result = append(result, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end == -1 {
+				// Leave unterminated comments to be reported as syntax
+				// errors.
+				result = append(result, data[i:]...)
+				i = len(data)
+			} else {
+				i += end + 3
+			}
+		case c == ',':
+			result = append(result, c)
+			comma = len(result) - 1
+		case c == '}' || c == ']':
+			if comma != -1 {
+				result[comma] = ' '
+			}
+			result = append(result, c)
+			comma = -1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			result = append(result, c)
+		default:
+			result = append(result, c)
+			comma = -1
+		}
+	}
+	return result
+}
+
+// encodeTOML encodes value, which must be a map[string]interface{}, as TOML.
+func encodeTOML(value interface{}) ([]byte, error) {
+	tree, err := toml.TreeFromMap(value.(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(tree.String()), nil
+}
@@ -0,0 +1,302 @@
+package chezmoi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeContents(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		targetName string
+		dest       string
+		fragment   string
+		expected   string
+	}{
+		{
+			name:       "json",
+			targetName: "settings.json",
+			dest:       `{"editor":{"fontSize":12,"tabSize":4},"theme":"dark"}`,
+			fragment:   `{"editor":{"fontSize":14}}`,
+			expected: joinLines(
+				`{`,
+				`  "editor": {`,
+				`    "fontSize": 14,`,
+				`    "tabSize": 4`,
+				`  },`,
+				`  "theme": "dark"`,
+				`}`,
+			),
+		},
+		{
+			name:       "json_comments",
+			targetName: "settings.json",
+			dest: joinLines(
+				`// Place your settings in this file.`,
+				`{`,
+				`  /* editor settings */`,
+				`  "editor.fontSize": 12, // points`,
+				`  "http.proxy": "http://proxy.example.com/",`,
+				`}`,
+			),
+			fragment: `{"editor.fontSize": 14}`,
+			expected: joinLines(
+				`{`,
+				`  "editor.fontSize": 14,`,
+				`  "http.proxy": "http://proxy.example.com/"`,
+				`}`,
+			),
+		},
+		{
+			name:       "json_key_order",
+			targetName: "settings.json",
+			dest:       `{"z":1,"a":{"y":2,"b":3},"m":[3,1,2]}`,
+			fragment:   `{"n":4,"a":{"c":5,"b":6}}`,
+			expected: joinLines(
+				`{`,
+				`  "z": 1,`,
+				`  "a": {`,
+				`    "y": 2,`,
+				`    "b": 6,`,
+				`    "c": 5`,
+				`  },`,
+				`  "m": [`,
+				`    3,`,
+				`    1,`,
+				`    2`,
+				`  ],`,
+				`  "n": 4`,
+				`}`,
+			),
+		},
+		{
+			name:       "json_numbers",
+			targetName: "settings.json",
+			dest:       `{"id":9007199254740993,"ratio":1.10}`,
+			fragment:   `{"big":12345678901234567890}`,
+			expected: joinLines(
+				`{`,
+				`  "id": 9007199254740993,`,
+				`  "ratio": 1.10,`,
+				`  "big": 12345678901234567890`,
+				`}`,
+			),
+		},
+		{
+			name:       "json_empty_dest",
+			targetName: "settings.json",
+			fragment:   `{"theme":"light"}`,
+			expected: joinLines(
+				`{`,
+				`  "theme": "light"`,
+				`}`,
+			),
+		},
+		{
+			name:       "toml",
+			targetName: "config.toml",
+			dest: joinLines(
+				`name = "foo"`,
+				`[server]`,
+				`  port = 80`,
+			),
+			fragment: joinLines(
+				`[server]`,
+				`  host = "localhost"`,
+			),
+			expected: joinLines(
+				`name = "foo"`,
+				``,
+				`[server]`,
+				`  host = "localhost"`,
+				`  port = 80`,
+			),
+		},
+		{
+			name:       "yaml",
+			targetName: "config.yml",
+			dest: joinLines(
+				`a: 1`,
+				`b:`,
+				`  c: 2`,
+			),
+			fragment: joinLines(
+				`b:`,
+				`  d: 3`,
+			),
+			expected: joinLines(
+				`a: 1`,
+				`b:`,
+				`  c: 2`,
+				`  d: 3`,
+			),
+		},
+		{
+			name:       "ini",
+			targetName: ".gitconfig",
+			dest: joinLines(
+				`; comment`,
+				`[core]`,
+				`editor = vi`,
+				`[user]`,
+				`name = Alice`,
+			),
+			fragment: joinLines(
+				`[user]`,
+				`email = alice@example.com`,
+				`name = Alice Smith`,
+			),
+			expected: joinLines(
+				`; comment`,
+				`[core]`,
+				`editor = vi`,
+				`[user]`,
+				`name = Alice Smith`,
+				`email = alice@example.com`,
+			),
+		},
+		{
+			name:       "ini_preserve_formatting",
+			targetName: ".gitconfig",
+			dest: joinLines(
+				`[user]`,
+				"\tname  = User",
+				"\temail = user@example.com",
+				`[core]`,
+				"\teditor=vi # comment",
+			),
+			fragment: joinLines(
+				`[core]`,
+				`pager = less`,
+			),
+			expected: joinLines(
+				`[user]`,
+				"\tname  = User",
+				"\temail = user@example.com",
+				`[core]`,
+				"\teditor=vi # comment",
+				"\tpager = less",
+			),
+		},
+		{
+			name:       "ini_shadows",
+			targetName: ".gitconfig",
+			dest: joinLines(
+				`[remote "origin"]`,
+				"\turl = https://example.com/old.git",
+				"\tfetch = +refs/heads/*:refs/remotes/origin/*",
+				"\tfetch = +refs/tags/*:refs/tags/*",
+			),
+			fragment: joinLines(
+				`[remote "origin"]`,
+				`url = https://example.com/new.git`,
+			),
+			expected: joinLines(
+				`[remote "origin"]`,
+				"\turl = https://example.com/new.git",
+				"\tfetch = +refs/heads/*:refs/remotes/origin/*",
+				"\tfetch = +refs/tags/*:refs/tags/*",
+			),
+		},
+		{
+			name:       "ini_replace_shadows",
+			targetName: ".gitconfig",
+			dest: joinLines(
+				`[remote "origin"]`,
+				"\tfetch = +refs/heads/*:refs/remotes/origin/*",
+				"\turl = https://example.com/repo.git",
+				"\tfetch = +refs/tags/*:refs/tags/*",
+			),
+			fragment: joinLines(
+				`[remote "origin"]`,
+				`fetch = +refs/heads/main:refs/remotes/origin/main`,
+				`fetch = +refs/heads/dev:refs/remotes/origin/dev`,
+			),
+			expected: joinLines(
+				`[remote "origin"]`,
+				"\tfetch = +refs/heads/main:refs/remotes/origin/main",
+				"\tfetch = +refs/heads/dev:refs/remotes/origin/dev",
+				"\turl = https://example.com/repo.git",
+			),
+		},
+		{
+			name:       "ini_new_section",
+			targetName: "config.ini",
+			dest: joinLines(
+				`key = value`,
+				`[a]`,
+				`b = c`,
+			),
+			fragment: joinLines(
+				`default = true`,
+				`[d]`,
+				`e = f`,
+			),
+			expected: joinLines(
+				`key = value`,
+				`default = true`,
+				`[a]`,
+				`b = c`,
+				`[d]`,
+				`e = f`,
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := mergeContents(tc.targetName, []byte(tc.dest), []byte(tc.fragment))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(actual))
+		})
+	}
+}
+
+func TestMergeContentsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		targetName string
+		dest       string
+		fragment   string
+	}{
+		{
+			name:       "unknown_format",
+			targetName: "foo.txt",
+		},
+		{
+			name:       "invalid_dest",
+			targetName: "settings.json",
+			dest:       "{",
+			fragment:   "{}",
+		},
+		{
+			name:       "json_not_object",
+			targetName: "settings.json",
+			dest:       "[]",
+			fragment:   "{}",
+		},
+		{
+			name:       "json_trailing_data",
+			targetName: "settings.json",
+			dest:       "{}{}",
+			fragment:   "{}",
+		},
+		{
+			name:       "invalid_fragment",
+			targetName: "settings.json",
+			dest:       "{}",
+			fragment:   "{",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := mergeContents(tc.targetName, []byte(tc.dest), []byte(tc.fragment))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// joinLines joins lines, terminating each with a newline.
+func joinLines(lines ...string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -1,47 +1,275 @@
 package chezmoi
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/bmatcuk/doublestar/v2"
 )
 
-// An PatternSet is a set of patterns.
+// A patternSetEntry is a single pattern in a PatternSet.
+type patternSetEntry struct {
+	pattern string
+	include bool
+	dirOnly bool
+}
+
+// An PatternSet is an ordered set of patterns with gitignore-like semantics.
 type PatternSet struct {
-	includes map[string]struct{}
-	excludes map[string]struct{}
+	entries []patternSetEntry
 }
 
 // NewPatternSet returns a new PatternSet.
 func NewPatternSet() *PatternSet {
-	return &PatternSet{
-		includes: make(map[string]struct{}),
-		excludes: make(map[string]struct{}),
-	}
+	return &PatternSet{}
 }
 
-// Add adds a pattern to ps.
+// Add adds a pattern to ps. pattern is slash-separated and relative to the
+// root of ps. If pattern has a trailing slash then it only matches
+// directories. Patterns added later take precedence over patterns added
+// earlier.
 func (ps *PatternSet) Add(pattern string, include bool) error {
-	if _, err := doublestar.PathMatch(pattern, ""); err != nil {
-		return nil
-	}
-	if include {
-		ps.includes[pattern] = struct{}{}
-	} else {
-		ps.excludes[pattern] = struct{}{}
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	// doublestar only reports malformed patterns when matching reaches them,
+	// so validate the whole pattern up front.
+	if err := validatePattern(pattern); err != nil {
+		return err
 	}
+	ps.entries = append(ps.entries, patternSetEntry{
+		pattern: pattern,
+		include: include,
+		dirOnly: dirOnly,
+	})
 	return nil
 }
 
-// Match returns if name matches any pattern in ps.
+// Match returns if name matches ps. name is treated as a directory if it has a
+// trailing slash. As with gitignore, the last pattern that matches name
+// determines the result, and name matches if any of its parent directories
+// match.
 func (ps *PatternSet) Match(name string) bool {
-	for pattern := range ps.excludes {
-		if ok, _ := doublestar.PathMatch(pattern, name); ok {
-			return false
+	name = filepath.ToSlash(name)
+	isDir := strings.HasSuffix(name, "/")
+	name = strings.TrimSuffix(name, "/")
+	for i := 0; i < len(name); i++ {
+		if name[i] == '/' && ps.match(name[:i], true) {
+			return true
 		}
 	}
-	for pattern := range ps.includes {
-		if ok, _ := doublestar.PathMatch(pattern, name); ok {
-			return true
+	return ps.match(name, isDir)
+}
+
+// includePatterns returns the patterns of all includes in ps.
+func (ps *PatternSet) includePatterns() []string {
+	var patterns []string
+	for _, entry := range ps.entries {
+		if entry.include {
+			patterns = append(patterns, entry.pattern)
+		}
+	}
+	return patterns
+}
+
+// match returns if name, without considering its parent directories, matches
+// ps.
+func (ps *PatternSet) match(name string, isDir bool) bool {
+	for i := len(ps.entries) - 1; i >= 0; i-- {
+		entry := ps.entries[i]
+		if entry.dirOnly && !isDir {
+			continue
+		}
+		ok, err := doublestar.Match(entry.pattern, name)
+		if err != nil {
+			// Add validates every pattern, so this is a bug in validatePattern.
+			panic(fmt.Sprintf("%s: %v", entry.pattern, err))
+		}
+		if ok {
+			return entry.include
 		}
 	}
 	return false
 }
+
+// parsePatternLine parses line from a pattern file in dir, which is
+// slash-separated and relative to the root of the PatternSet, into a pattern
+// suitable for PatternSet.Add. ok is false if line contains no pattern. As
+// with gitignore, a leading slash or a slash in the middle of the pattern
+// anchors it to dir, otherwise it matches at any depth below dir, unless
+// anchored is true. Comments start with an unescaped # at the beginning of the
+// line or after whitespace, and a leading ! negates the pattern. Both can be
+// escaped with a backslash.
+func parsePatternLine(dir, line string, anchored bool) (pattern string, include, ok bool) {
+	line = stripPatternComment(line)
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", false, false
+	}
+
+	include = true
+	if strings.HasPrefix(line, "!") {
+		include = false
+		line = strings.TrimPrefix(line, "!")
+	}
+
+	dirOnly := strings.HasSuffix(line, "/")
+	line = strings.TrimSuffix(line, "/")
+
+	switch {
+	case strings.HasPrefix(line, "/"):
+		line = strings.TrimPrefix(line, "/")
+	case strings.Contains(line, "/"):
+	case !anchored:
+		line = "**/" + line
+	}
+
+	if dir != "" && dir != "." {
+		line = dir + "/" + line
+	}
+	if dirOnly {
+		line += "/"
+	}
+	return line, include, true
+}
+
+// stripPatternComment returns line with any comment removed.
+func stripPatternComment(line string) string {
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// validatePattern returns doublestar.ErrBadPattern if pattern is not a valid
+// doublestar pattern. It accepts the same grammar as doublestar.Match:
+// backslash escapes, character classes, and nested {alt1,alt2,...}
+// alternatives.
+func validatePattern(pattern string) error {
+	for i := 0; i < len(pattern); {
+		switch pattern[i] {
+		case '\\':
+			if i+1 >= len(pattern) {
+				return doublestar.ErrBadPattern
+			}
+			_, size := utf8.DecodeRuneInString(pattern[i+1:])
+			i += 1 + size
+		case '[':
+			end := indexByteWithEscaping(pattern[i+1:], ']')
+			if end == -1 {
+				return doublestar.ErrBadPattern
+			}
+			if err := validatePatternClass([]rune(pattern[i+1 : i+1+end])); err != nil {
+				return err
+			}
+			i += end + 2
+		case '{':
+			alternatives, end := splitPatternAlternatives(pattern[i+1:])
+			if end == -1 {
+				return doublestar.ErrBadPattern
+			}
+			// As in doublestar, each alternative continues with the rest of
+			// the pattern.
+			rest := pattern[i+1+end:]
+			for _, alternative := range alternatives {
+				if err := validatePattern(alternative + rest); err != nil {
+					return err
+				}
+			}
+			return nil
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
+// validatePatternClass returns doublestar.ErrBadPattern if class, the contents
+// of a character class without its brackets, is malformed.
+func validatePatternClass(class []rune) error {
+	if len(class) == 0 {
+		return doublestar.ErrBadPattern
+	}
+	i := 0
+	if class[0] == '^' {
+		i++
+	}
+	// classRune consumes a single, possibly escaped, rune.
+	classRune := func() error {
+		switch {
+		case class[i] == '-':
+			return doublestar.ErrBadPattern
+		case class[i] == '\\' && i+1 >= len(class):
+			return doublestar.ErrBadPattern
+		case class[i] == '\\':
+			i += 2
+		default:
+			i++
+		}
+		return nil
+	}
+	for i < len(class) {
+		if err := classRune(); err != nil {
+			return err
+		}
+		if i < len(class) && class[i] == '-' {
+			i++
+			if i >= len(class) {
+				return doublestar.ErrBadPattern
+			}
+			if err := classRune(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// indexByteWithEscaping returns the index of the first c in s that is not
+// preceded by a backslash, or -1 if there is none. As in doublestar, only the
+// preceding byte is considered.
+func indexByteWithEscaping(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == c && (i == 0 || s[i-1] != '\\') {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitPatternAlternatives splits s, the part of a pattern after an opening
+// brace, into its top-level alternatives, and returns the index after the
+// matching closing brace, or -1 if there is none.
+func splitPatternAlternatives(s string) ([]string, int) {
+	var alternatives []string
+	depth := 1
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return append(alternatives, s[start:i]), i + 1
+			}
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return nil, -1
+}
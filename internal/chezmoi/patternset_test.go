@@ -4,10 +4,16 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/bmatcuk/doublestar/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+type patternSetTestPattern struct {
+	pattern string
+	include bool
+}
+
 func TestPatternSet(t *testing.T) {
 	for _, tc := range []struct {
 		name          string
@@ -23,8 +29,8 @@ func TestPatternSet(t *testing.T) {
 		},
 		{
 			name: "exact",
-			ps: mustNewPatternSet(t, map[string]bool{
-				"foo": true,
+			ps: mustNewPatternSet(t, []patternSetTestPattern{
+				{"foo", true},
 			}),
 			expectMatches: map[string]bool{
 				"foo": true,
@@ -33,8 +39,8 @@ func TestPatternSet(t *testing.T) {
 		},
 		{
 			name: "wildcard",
-			ps: mustNewPatternSet(t, map[string]bool{
-				"b*": true,
+			ps: mustNewPatternSet(t, []patternSetTestPattern{
+				{"b*", true},
 			}),
 			expectMatches: map[string]bool{
 				"foo": false,
@@ -44,9 +50,9 @@ func TestPatternSet(t *testing.T) {
 		},
 		{
 			name: "exclude",
-			ps: mustNewPatternSet(t, map[string]bool{
-				"b*":  true,
-				"baz": false,
+			ps: mustNewPatternSet(t, []patternSetTestPattern{
+				{"b*", true},
+				{"baz", false},
 			}),
 			expectMatches: map[string]bool{
 				"foo": false,
@@ -54,10 +60,21 @@ func TestPatternSet(t *testing.T) {
 				"baz": false,
 			},
 		},
+		{
+			name: "last_match_wins",
+			ps: mustNewPatternSet(t, []patternSetTestPattern{
+				{"baz", false},
+				{"b*", true},
+			}),
+			expectMatches: map[string]bool{
+				"bar": true,
+				"baz": true,
+			},
+		},
 		{
 			name: "doublestar",
-			ps: mustNewPatternSet(t, map[string]bool{
-				"**/foo": true,
+			ps: mustNewPatternSet(t, []patternSetTestPattern{
+				{"**/foo", true},
 			}),
 			expectMatches: map[string]bool{
 				"foo":                              true,
@@ -65,19 +82,109 @@ func TestPatternSet(t *testing.T) {
 				filepath.Join("baz", "bar", "foo"): true,
 			},
 		},
+		{
+			name: "parent_dir",
+			ps: mustNewPatternSet(t, []patternSetTestPattern{
+				{"foo", true},
+				{"foo/bar", false},
+			}),
+			expectMatches: map[string]bool{
+				"foo":                       true,
+				filepath.Join("foo", "bar"): true,
+				filepath.Join("foo", "baz"): true,
+			},
+		},
+		{
+			name: "dir_only",
+			ps: mustNewPatternSet(t, []patternSetTestPattern{
+				{"foo/", true},
+			}),
+			expectMatches: map[string]bool{
+				"foo":                       false,
+				"foo/":                      true,
+				filepath.Join("foo", "bar"): true,
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			for s, expectMatch := range tc.expectMatches {
-				assert.Equal(t, expectMatch, tc.ps.Match(s))
+				assert.Equal(t, expectMatch, tc.ps.Match(s), s)
+			}
+		})
+	}
+}
+
+func TestPatternSetAdd(t *testing.T) {
+	for _, tc := range []struct {
+		pattern     string
+		expectedErr error
+	}{
+		{pattern: "*.txt"},
+		{pattern: "**/{a,b{c,d}}/[^x-z\\]]"},
+		{pattern: "\\{a"},
+		{pattern: "{[a,b}]"},
+		{pattern: "[", expectedErr: doublestar.ErrBadPattern},
+		{pattern: "[]", expectedErr: doublestar.ErrBadPattern},
+		{pattern: "[a-]", expectedErr: doublestar.ErrBadPattern},
+		{pattern: "[-a]", expectedErr: doublestar.ErrBadPattern},
+		{pattern: "a\\", expectedErr: doublestar.ErrBadPattern},
+		{pattern: "{a,b", expectedErr: doublestar.ErrBadPattern},
+		{pattern: "x/{a,b{c}", expectedErr: doublestar.ErrBadPattern},
+		{pattern: "{a,[b}", expectedErr: doublestar.ErrBadPattern},
+		{pattern: "z/y/{a,b}[", expectedErr: doublestar.ErrBadPattern},
+	} {
+		t.Run(tc.pattern, func(t *testing.T) {
+			ps := NewPatternSet()
+			assert.Equal(t, tc.expectedErr, ps.Add(tc.pattern, true))
+			if tc.expectedErr == nil {
+				for _, name := range []string{"", "a", "a]", "[a]", "x/bd/]", "{a", "z/y/a"} {
+					assert.NotPanics(t, func() { ps.Match(name) }, name)
+				}
 			}
 		})
 	}
 }
 
-func mustNewPatternSet(t *testing.T, patterns map[string]bool) *PatternSet {
+func TestParsePatternLine(t *testing.T) {
+	for _, tc := range []struct {
+		dir             string
+		line            string
+		anchored        bool
+		expectedPattern string
+		expectedInclude bool
+		expectedOK      bool
+	}{
+		{line: ""},
+		{line: "   "},
+		{line: "# comment"},
+		{line: "foo", expectedPattern: "**/foo", expectedInclude: true, expectedOK: true},
+		{line: "foo # comment", expectedPattern: "**/foo", expectedInclude: true, expectedOK: true},
+		{line: "foo#bar", expectedPattern: "**/foo#bar", expectedInclude: true, expectedOK: true},
+		{line: `\#foo`, expectedPattern: `**/\#foo`, expectedInclude: true, expectedOK: true},
+		{line: "!foo", expectedPattern: "**/foo", expectedOK: true},
+		{line: `\!foo`, expectedPattern: `**/\!foo`, expectedInclude: true, expectedOK: true},
+		{line: "/foo", expectedPattern: "foo", expectedInclude: true, expectedOK: true},
+		{line: "foo/bar", expectedPattern: "foo/bar", expectedInclude: true, expectedOK: true},
+		{line: "foo/", expectedPattern: "**/foo/", expectedInclude: true, expectedOK: true},
+		{line: "/foo/", expectedPattern: "foo/", expectedInclude: true, expectedOK: true},
+		{line: "foo", anchored: true, expectedPattern: "foo", expectedInclude: true, expectedOK: true},
+		{dir: ".", line: "foo", expectedPattern: "**/foo", expectedInclude: true, expectedOK: true},
+		{dir: "dir", line: "foo", expectedPattern: "dir/**/foo", expectedInclude: true, expectedOK: true},
+		{dir: "dir", line: "/foo", expectedPattern: "dir/foo", expectedInclude: true, expectedOK: true},
+	} {
+		t.Run(tc.line, func(t *testing.T) {
+			actualPattern, actualInclude, actualOK := parsePatternLine(tc.dir, tc.line, tc.anchored)
+			assert.Equal(t, tc.expectedPattern, actualPattern)
+			assert.Equal(t, tc.expectedInclude, actualInclude)
+			assert.Equal(t, tc.expectedOK, actualOK)
+		})
+	}
+}
+
+func mustNewPatternSet(t *testing.T, patterns []patternSetTestPattern) *PatternSet {
 	ps := NewPatternSet()
-	for pattern, exclude := range patterns {
-		require.NoError(t, ps.Add(pattern, exclude))
+	for _, p := range patterns {
+		require.NoError(t, ps.Add(p.pattern, p.include))
 	}
 	return ps
 }
@@ -8,35 +8,41 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	vfs "github.com/twpayne/go-vfs"
 )
 
-// FIXME allow encrypted scripts
-// FIXME add pre- and post- attributes
-
 // A ScriptAttributes holds attributes parsed from a source script name.
 type ScriptAttributes struct {
-	Name     string
-	Once     bool
-	Template bool
+	Name      string
+	Encrypted bool
+	Once      bool
+	OnChange  bool
+	Before    bool
+	After     bool
+	Template  bool
 }
 
 // A ScriptState represents the state of a script.
 type ScriptState struct {
-	Name       string    `json:"name"`
-	ExecutedAt time.Time `json:"executedAt"`
+	Name           string    `json:"name"`
+	ExecutedAt     time.Time `json:"executedAt"`
+	ContentsSHA256 string    `json:"contentsSHA256,omitempty"`
 }
 
 // A Script represents a script to run.
 type Script struct {
 	sourceName       string
 	targetName       string
+	Encrypted        bool
 	Once             bool
+	OnChange         bool
+	Before           bool
+	After            bool
 	Template         bool
 	contents         []byte
 	contentsErr      error
@@ -47,7 +53,11 @@ type scriptConcreteValue struct {
 	Type       string `json:"type" yaml:"type"`
 	SourcePath string `json:"sourcePath" yaml:"sourcePath"`
 	TargetPath string `json:"targetPath" yaml:"targetPath"`
+	Encrypted  bool   `json:"encrypted" yaml:"encrypted"`
 	Once       bool   `json:"once" yaml:"once"`
+	OnChange   bool   `json:"onChange" yaml:"onChange"`
+	Before     bool   `json:"before" yaml:"before"`
+	After      bool   `json:"after" yaml:"after"`
 	Template   bool   `json:"template" yaml:"template"`
 	Contents   string `json:"contents" yaml:"contents"`
 }
@@ -55,28 +65,64 @@ type scriptConcreteValue struct {
 // ParseScriptAttributes parses a source script file name.
 func ParseScriptAttributes(sourceName string) ScriptAttributes {
 	name := strings.TrimPrefix(sourceName, runPrefix)
+	encrypted := false
 	once := false
+	onChange := false
+	before := false
+	after := false
 	template := false
-	if strings.HasPrefix(name, oncePrefix) {
+	if strings.HasPrefix(name, encryptedPrefix) {
+		encrypted = true
+		name = strings.TrimPrefix(name, encryptedPrefix)
+	}
+	switch {
+	case strings.HasPrefix(name, oncePrefix):
 		once = true
 		name = strings.TrimPrefix(name, oncePrefix)
+	case strings.HasPrefix(name, onChangePrefix):
+		onChange = true
+		name = strings.TrimPrefix(name, onChangePrefix)
+	}
+	switch {
+	case strings.HasPrefix(name, beforePrefix):
+		before = true
+		name = strings.TrimPrefix(name, beforePrefix)
+	case strings.HasPrefix(name, afterPrefix):
+		after = true
+		name = strings.TrimPrefix(name, afterPrefix)
 	}
 	if strings.HasSuffix(name, TemplateSuffix) {
 		template = true
 		name = strings.TrimSuffix(name, TemplateSuffix)
 	}
 	return ScriptAttributes{
-		Name:     name,
-		Once:     once,
-		Template: template,
+		Name:      name,
+		Encrypted: encrypted,
+		Once:      once,
+		OnChange:  onChange,
+		Before:    before,
+		After:     after,
+		Template:  template,
 	}
 }
 
 // SourceName returns sa's source name.
 func (sa ScriptAttributes) SourceName() string {
 	sourceName := runPrefix
-	if sa.Once {
+	if sa.Encrypted {
+		sourceName += encryptedPrefix
+	}
+	switch {
+	case sa.Once:
 		sourceName += oncePrefix
+	case sa.OnChange:
+		sourceName += onChangePrefix
+	}
+	switch {
+	case sa.Before:
+		sourceName += beforePrefix
+	case sa.After:
+		sourceName += afterPrefix
 	}
 	sourceName += sa.Name
 	if sa.Template {
@@ -103,10 +149,12 @@ func (s *Script) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *Ap
 		return nil
 	}
 
+	contentsSHA256Arr := sha256.Sum256(contents)
+	contentsSHA256 := hex.EncodeToString(contentsSHA256Arr[:])
 	var key []byte
-	if s.Once {
-		contentsKeyArr := sha256.Sum256(contents)
-		key = []byte(s.targetName + ":" + hex.EncodeToString(contentsKeyArr[:]))
+	switch {
+	case s.Once:
+		key = []byte(s.targetName + ":" + contentsSHA256)
 		scriptStateData, err := applyOptions.PersistentState.Get(applyOptions.ScriptStateBucket, key)
 		if err != nil {
 			return err
@@ -114,41 +162,53 @@ func (s *Script) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *Ap
 		if scriptStateData != nil {
 			return nil
 		}
+	case s.OnChange:
+		// Key run_onchange_ scripts by target name alone so that the state of
+		// the last successful run replaces any earlier state.
+		key = []byte(s.targetName)
+		scriptStateData, err := applyOptions.PersistentState.Get(applyOptions.ScriptStateBucket, key)
+		if err != nil {
+			return err
+		}
+		if scriptStateData != nil {
+			var scriptState ScriptState
+			if err := json.Unmarshal(scriptStateData, &scriptState); err != nil {
+				return err
+			}
+			if scriptState.ContentsSHA256 == contentsSHA256 {
+				return nil
+			}
+		}
 	}
 
-	if applyOptions.Verbose {
+	// Do not echo the decrypted contents of encrypted scripts, they are only
+	// shown when explicitly requested with cat or dump.
+	if applyOptions.Verbose && !s.Encrypted {
 		if _, err := applyOptions.Stdout.Write(contents); err != nil {
 			return err
 		}
 	}
 	if applyOptions.DryRun {
+		if applyOptions.DryRunScript != nil {
+			applyOptions.DryRunScript(filepath.Join(applyOptions.DestDir, s.targetName))
+		}
 		return nil
 	}
 
-	// Write the temporary script file. Put the randomness on the front of the
-	// filename to preserve any file extension for Windows scripts.
-	f, err := ioutil.TempFile("", "*."+filepath.Base(s.targetName))
+	scriptFilename, err := writeTempScript(s.targetName, contents)
 	if err != nil {
 		return err
 	}
-
 	defer func() {
-		_ = os.RemoveAll(f.Name())
+		_ = os.RemoveAll(scriptFilename)
 	}()
-	if err := os.Chmod(f.Name(), 0o700); err != nil {
-		return err
-	}
-	if _, err := f.Write(contents); err != nil {
-		return err
-	}
-	if err := f.Close(); err != nil {
-		return err
-	}
 
-	// Run the temporary script file.
-	//nolint:gosec
-	c := exec.Command(f.Name())
-	c.Dir = filepath.Join(applyOptions.DestDir, filepath.Dir(s.targetName))
+	// Run the temporary script file, either with its interpreter or directly.
+	c := interpreterCmd(applyOptions.Interpreters, s.targetName, scriptFilename)
+	c.Dir = scriptWorkingDir(applyOptions.DestDir, s.targetName)
+	if len(applyOptions.ScriptEnv) != 0 {
+		c.Env = append(os.Environ(), applyOptions.ScriptEnv...)
+	}
 	c.Stdout = os.Stdout
 	c.Stderr = os.Stderr
 	c.Stdin = os.Stdin
@@ -156,10 +216,11 @@ func (s *Script) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *Ap
 		return err
 	}
 
-	if s.Once {
+	if s.Once || s.OnChange {
 		scriptState := &ScriptState{
-			Name:       s.sourceName,
-			ExecutedAt: time.Now(),
+			Name:           s.sourceName,
+			ExecutedAt:     time.Now(),
+			ContentsSHA256: contentsSHA256,
 		}
 		scriptStateData, err := json.Marshal(&scriptState)
 		if err != nil {
@@ -173,20 +234,39 @@ func (s *Script) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *Ap
 	return err
 }
 
-// ConcreteValue implements Entry.ConcreteValue.
-func (s *Script) ConcreteValue(ignore func(string) bool, sourceDir string, umask os.FileMode, recursive bool) (interface{}, error) {
+// ConcreteValue implements Entry.ConcreteValue. The contents of encrypted
+// scripts are not included, use DecryptedConcreteValue to include them.
+func (s *Script) ConcreteValue(fs vfs.FS, applyOptions *ApplyOptions, sourceDir string, recursive bool) (interface{}, error) {
+	return s.concreteValue(applyOptions.Ignore, sourceDir, false)
+}
+
+// DecryptedConcreteValue returns s's concrete value, including its contents
+// even if s is encrypted.
+func (s *Script) DecryptedConcreteValue(ignore func(string) bool, sourceDir string) (interface{}, error) {
+	return s.concreteValue(ignore, sourceDir, true)
+}
+
+func (s *Script) concreteValue(ignore func(string) bool, sourceDir string, decrypt bool) (interface{}, error) {
 	if ignore(s.targetName) {
 		return nil, nil
 	}
-	contents, err := s.Contents()
-	if err != nil {
-		return nil, err
+	var contents []byte
+	if !s.Encrypted || decrypt {
+		var err error
+		contents, err = s.Contents()
+		if err != nil {
+			return nil, err
+		}
 	}
 	return &scriptConcreteValue{
 		Type:       "script",
 		SourcePath: filepath.Join(sourceDir, s.SourceName()),
 		TargetPath: s.TargetName(),
+		Encrypted:  s.Encrypted,
 		Once:       s.Once,
+		OnChange:   s.OnChange,
+		Before:     s.Before,
+		After:      s.After,
 		Template:   s.Template,
 		Contents:   string(contents),
 	}, nil
@@ -220,9 +300,10 @@ func (s *Script) TargetName() string {
 	return s.targetName
 }
 
-// archive writes s to w.
-func (s *Script) archive(w *tar.Writer, ignore func(string) bool, headerTemplate *tar.Header, umask os.FileMode) error {
-	if ignore(s.targetName) {
+// archive writes s to w. Encrypted scripts are not included, so that their
+// decrypted contents are not written to the archive.
+func (s *Script) archive(w *tar.Writer, fs vfs.FS, applyOptions *ApplyOptions, headerTemplate *tar.Header) error {
+	if applyOptions.Ignore(s.targetName) || s.Encrypted {
 		return nil
 	}
 	contents, err := s.Contents()
@@ -233,10 +314,89 @@ func (s *Script) archive(w *tar.Writer, ignore func(string) bool, headerTemplate
 	header.Typeflag = tar.TypeReg
 	header.Name = s.targetName
 	header.Size = int64(len(contents))
-	header.Mode = int64(0o777 &^ umask)
+	header.Mode = int64(0o777 &^ applyOptions.Umask)
 	if err := w.WriteHeader(&header); err != nil {
 		return nil
 	}
 	_, err = w.Write(contents)
 	return err
 }
+
+// scriptWorkingDir returns the directory in which to run the script
+// targetName, which is the closest existing parent directory of the target in
+// destDir.
+func scriptWorkingDir(destDir, targetName string) string {
+	dir := filepath.Join(destDir, filepath.Dir(targetName))
+	for dir != destDir {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			break
+		}
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			break
+		}
+		dir = parentDir
+	}
+	return dir
+}
+
+// writeTempScript writes contents to a new executable temporary file for the
+// script targetName and returns its filename. The caller is responsible for
+// removing the file.
+func writeTempScript(targetName string, contents []byte) (string, error) {
+	// Put the randomness on the front of the filename to preserve any file
+	// extension for Windows scripts.
+	f, err := ioutil.TempFile("", "*."+filepath.Base(targetName))
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chmod(f.Name(), 0o700); err != nil {
+		_ = f.Close()
+		_ = os.RemoveAll(f.Name())
+		return "", err
+	}
+	if _, err := f.Write(contents); err != nil {
+		_ = f.Close()
+		_ = os.RemoveAll(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.RemoveAll(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// isBeforeOrAfterScript returns true if entry is a script that is run before
+// or after all other entries are applied.
+func isBeforeOrAfterScript(entry Entry) bool {
+	s, ok := entry.(*Script)
+	return ok && (s.Before || s.After)
+}
+
+// sortedScripts returns all scripts in entries, recursively, for which f
+// returns true, sorted by target name. Scripts in ignored directories are
+// skipped.
+func sortedScripts(entries map[string]Entry, ignore func(string) bool, f func(*Script) bool) []*Script {
+	var scripts []*Script
+	var walk func(map[string]Entry)
+	walk = func(entries map[string]Entry) {
+		for _, entry := range entries {
+			switch entry := entry.(type) {
+			case *Dir:
+				if !ignore(entry.targetName) {
+					walk(entry.Entries)
+				}
+			case *Script:
+				if f(entry) {
+					scripts = append(scripts, entry)
+				}
+			}
+		}
+	}
+	walk(entries)
+	sort.Slice(scripts, func(i, j int) bool {
+		return scripts[i].targetName < scripts[j].targetName
+	})
+	return scripts
+}
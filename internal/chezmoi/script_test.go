@@ -0,0 +1,97 @@
+package chezmoi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptAttributes(t *testing.T) {
+	for _, tc := range []struct {
+		sourceName string
+		sa         ScriptAttributes
+	}{
+		{
+			sourceName: "run_foo",
+			sa: ScriptAttributes{
+				Name: "foo",
+			},
+		},
+		{
+			sourceName: "run_encrypted_foo",
+			sa: ScriptAttributes{
+				Name:      "foo",
+				Encrypted: true,
+			},
+		},
+		{
+			sourceName: "run_encrypted_onchange_before_foo.tmpl",
+			sa: ScriptAttributes{
+				Name:      "foo",
+				Encrypted: true,
+				OnChange:  true,
+				Before:    true,
+				Template:  true,
+			},
+		},
+		{
+			sourceName: "run_once_foo",
+			sa: ScriptAttributes{
+				Name: "foo",
+				Once: true,
+			},
+		},
+		{
+			sourceName: "run_onchange_foo",
+			sa: ScriptAttributes{
+				Name:     "foo",
+				OnChange: true,
+			},
+		},
+		{
+			sourceName: "run_onchange_after_foo.tmpl",
+			sa: ScriptAttributes{
+				Name:     "foo",
+				OnChange: true,
+				After:    true,
+				Template: true,
+			},
+		},
+		{
+			sourceName: "run_before_foo",
+			sa: ScriptAttributes{
+				Name:   "foo",
+				Before: true,
+			},
+		},
+		{
+			sourceName: "run_after_foo",
+			sa: ScriptAttributes{
+				Name:  "foo",
+				After: true,
+			},
+		},
+		{
+			sourceName: "run_once_before_foo.tmpl",
+			sa: ScriptAttributes{
+				Name:     "foo",
+				Once:     true,
+				Before:   true,
+				Template: true,
+			},
+		},
+		{
+			sourceName: "run_once_after_foo",
+			sa: ScriptAttributes{
+				Name:  "foo",
+				Once:  true,
+				After: true,
+			},
+		},
+	} {
+		t.Run(tc.sourceName, func(t *testing.T) {
+			assert.Equal(t, tc.sa, ParseScriptAttributes(tc.sourceName))
+			assert.Equal(t, tc.sourceName, tc.sa.SourceName())
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package chezmoi
+
+import (
+	"os"
+	"os/exec"
+
+	vfs "github.com/twpayne/go-vfs"
+)
+
+// Status codes recorded by a StatusMutator.
+const (
+	StatusAdded      = 'A'
+	StatusDeleted    = 'D'
+	StatusModified   = 'M'
+	StatusPermission = 'P'
+	StatusRun        = 'R'
+)
+
+// A StatusMutator is a Mutator that records the changes that would be made to
+// each path, without making them.
+type StatusMutator struct {
+	fs       vfs.FS
+	statuses map[string]byte
+}
+
+// NewStatusMutator returns a new StatusMutator that checks the existing state
+// of paths in fs.
+func NewStatusMutator(fs vfs.FS) *StatusMutator {
+	return &StatusMutator{
+		fs:       fs,
+		statuses: make(map[string]byte),
+	}
+}
+
+// Chmod implements Mutator.Chmod.
+func (m *StatusMutator) Chmod(name string, mode os.FileMode) error {
+	if _, ok := m.statuses[name]; !ok {
+		m.statuses[name] = StatusPermission
+	}
+	return nil
+}
+
+// IdempotentCmdOutput implements Mutator.IdempotentCmdOutput.
+func (m *StatusMutator) IdempotentCmdOutput(cmd *exec.Cmd) ([]byte, error) {
+	return cmd.Output()
+}
+
+// Mkdir implements Mutator.Mkdir.
+func (m *StatusMutator) Mkdir(name string, perm os.FileMode) error {
+	m.write(name)
+	return nil
+}
+
+// RecordScript records that the script at name would be run. It can be used as
+// ApplyOptions.DryRunScript.
+func (m *StatusMutator) RecordScript(name string) {
+	m.statuses[name] = StatusRun
+}
+
+// RemoveAll implements Mutator.RemoveAll.
+func (m *StatusMutator) RemoveAll(name string) error {
+	m.statuses[name] = StatusDeleted
+	return nil
+}
+
+// Rename implements Mutator.Rename.
+func (m *StatusMutator) Rename(oldpath, newpath string) error {
+	m.statuses[oldpath] = StatusDeleted
+	m.write(newpath)
+	return nil
+}
+
+// RunCmd implements Mutator.RunCmd.
+func (m *StatusMutator) RunCmd(cmd *exec.Cmd) error {
+	return nil
+}
+
+// Stat implements Mutator.Stat.
+func (m *StatusMutator) Stat(name string) (os.FileInfo, error) {
+	return m.fs.Stat(name)
+}
+
+// Statuses returns a map of paths to the status code of the change that would
+// be made to them.
+func (m *StatusMutator) Statuses() map[string]byte {
+	statuses := make(map[string]byte, len(m.statuses))
+	for name, status := range m.statuses {
+		statuses[name] = status
+	}
+	return statuses
+}
+
+// WriteFile implements Mutator.WriteFile.
+func (m *StatusMutator) WriteFile(name string, data []byte, perm os.FileMode, currData []byte) error {
+	m.write(name)
+	return nil
+}
+
+// WriteSymlink implements Mutator.WriteSymlink.
+func (m *StatusMutator) WriteSymlink(oldname, newname string) error {
+	m.write(newname)
+	return nil
+}
+
+// write records that name would be written, either replacing an existing entry
+// or adding a new one.
+func (m *StatusMutator) write(name string) {
+	if m.statuses[name] == StatusDeleted {
+		m.statuses[name] = StatusModified
+		return
+	}
+	if _, err := m.fs.Lstat(name); err == nil {
+		m.statuses[name] = StatusModified
+	} else {
+		m.statuses[name] = StatusAdded
+	}
+}
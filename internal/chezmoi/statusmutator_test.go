@@ -0,0 +1,55 @@
+package chezmoi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-vfs/vfst"
+)
+
+var _ Mutator = &StatusMutator{}
+
+func TestStatusMutator(t *testing.T) {
+	fs, cleanup, err := vfst.NewTestFS(map[string]interface{}{
+		"/home/user": map[string]interface{}{
+			".bashrc":  "# contents of .bashrc\n",
+			".dir":     &vfst.Dir{Perm: 0o755},
+			".inputrc": "# contents of .inputrc\n",
+			".profile": "# contents of .profile\n",
+		},
+	})
+	require.NoError(t, err)
+	defer cleanup()
+
+	m := NewStatusMutator(fs)
+	require.NoError(t, m.WriteFile("/home/user/.bashrc", []byte("# new contents of .bashrc\n"), 0o644, nil))
+	require.NoError(t, m.Chmod("/home/user/.dir", 0o700))
+	require.NoError(t, m.RemoveAll("/home/user/.inputrc"))
+	require.NoError(t, m.RemoveAll("/home/user/.profile"))
+	require.NoError(t, m.WriteSymlink(".bashrc", "/home/user/.profile"))
+	require.NoError(t, m.Mkdir("/home/user/.newdir", 0o755))
+	require.NoError(t, m.Chmod("/home/user/.newdir", 0o700))
+	m.RecordScript("/home/user/script.sh")
+
+	assert.Equal(t, map[string]byte{
+		"/home/user/.bashrc":   StatusModified,
+		"/home/user/.dir":      StatusPermission,
+		"/home/user/.inputrc":  StatusDeleted,
+		"/home/user/.newdir":   StatusAdded,
+		"/home/user/.profile":  StatusModified,
+		"/home/user/script.sh": StatusRun,
+	}, m.Statuses())
+
+	vfst.RunTests(t, fs, "",
+		vfst.TestPath("/home/user/.bashrc",
+			vfst.TestContentsString("# contents of .bashrc\n"),
+		),
+		vfst.TestPath("/home/user/.inputrc",
+			vfst.TestModeIsRegular,
+		),
+		vfst.TestPath("/home/user/.newdir",
+			vfst.TestDoesNotExist,
+		),
+	)
+}
@@ -50,28 +50,43 @@ func (s *Symlink) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *A
 	}
 	switch {
 	case err == nil && target == "":
-		return mutator.RemoveAll(targetPath)
+		if skip, err := applyOptions.skipModified(fs, s.targetName, targetPath, follow); err != nil || skip {
+			return err
+		}
+		if err := mutator.RemoveAll(targetPath); err != nil {
+			return err
+		}
+		return applyOptions.recordEntryState(fs, targetPath, follow)
 	case os.IsNotExist(err) && target == "":
-		return nil
+		return applyOptions.recordEntryState(fs, targetPath, follow)
 	case err == nil && info.Mode()&os.ModeType == os.ModeSymlink:
 		currentTarget, err := fs.Readlink(targetPath)
 		if err != nil {
 			return err
 		}
 		if currentTarget == target {
-			return nil
+			return applyOptions.recordEntryState(fs, targetPath, follow)
+		}
+		if skip, err := applyOptions.skipModified(fs, s.targetName, targetPath, follow); err != nil || skip {
+			return err
 		}
 	case err == nil:
+		if skip, err := applyOptions.skipModified(fs, s.targetName, targetPath, follow); err != nil || skip {
+			return err
+		}
 	case os.IsNotExist(err):
 	default:
 		return err
 	}
-	return mutator.WriteSymlink(target, targetPath)
+	if err := mutator.WriteSymlink(target, targetPath); err != nil {
+		return err
+	}
+	return applyOptions.recordEntryState(fs, targetPath, follow)
 }
 
 // ConcreteValue implements Entry.ConcreteValue.
-func (s *Symlink) ConcreteValue(ignore func(string) bool, sourceDir string, umask os.FileMode, recursive bool) (interface{}, error) {
-	if ignore(s.targetName) {
+func (s *Symlink) ConcreteValue(fs vfs.FS, applyOptions *ApplyOptions, sourceDir string, recursive bool) (interface{}, error) {
+	if applyOptions.Ignore(s.targetName) {
 		return nil, nil
 	}
 	linkname, err := s.Linkname()
@@ -116,8 +131,8 @@ func (s *Symlink) TargetName() string {
 }
 
 // archive writes s to w.
-func (s *Symlink) archive(w *tar.Writer, ignore func(string) bool, headerTemplate *tar.Header, umask os.FileMode) error {
-	if ignore(s.targetName) {
+func (s *Symlink) archive(w *tar.Writer, fs vfs.FS, applyOptions *ApplyOptions, headerTemplate *tar.Header) error {
+	if applyOptions.Ignore(s.targetName) {
 		return nil
 	}
 	linkname, err := s.Linkname()
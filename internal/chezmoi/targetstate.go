@@ -23,6 +23,8 @@ import (
 var DefaultTemplateOptions = []string{"missingkey=error"}
 
 const (
+	dataName         = ".chezmoidata"
+	externalName     = ".chezmoiexternal"
 	ignoreName       = ".chezmoiignore"
 	removeName       = ".chezmoiremove"
 	templatesDirName = ".chezmoitemplates"
@@ -31,6 +33,7 @@ const (
 
 // An AddOptions contains options for TargetState.Add.
 type AddOptions struct {
+	Create       bool
 	Empty        bool
 	Encrypt      bool
 	Exact        bool
@@ -54,9 +57,11 @@ type PopulateOptions struct {
 // A TargetState represents the root target state.
 type TargetState struct {
 	DestDir         string
+	Encryption      Encryption
 	Entries         map[string]Entry
-	GPG             *GPG
+	Externals       map[string]*External
 	MinVersion      *semver.Version
+	ReadExternal    func(*External) ([]byte, error)
 	SourceDir       string
 	TargetIgnore    *PatternSet
 	TargetRemove    *PatternSet
@@ -65,6 +70,10 @@ type TargetState struct {
 	TemplateOptions []string
 	Templates       map[string]*template.Template
 	Umask           os.FileMode
+
+	// externalLoaders maps the target names of archive externals to functions
+	// that download them and replace their placeholder entries.
+	externalLoaders map[string]func() error
 }
 
 // A TargetStateOption sets an option on a TargeState.
@@ -77,6 +86,13 @@ func WithDestDir(destDir string) TargetStateOption {
 	}
 }
 
+// WithEncryption sets the encryption.
+func WithEncryption(encryption Encryption) TargetStateOption {
+	return func(ts *TargetState) {
+		ts.Encryption = encryption
+	}
+}
+
 // WithEntries sets the entries.
 func WithEntries(entries map[string]Entry) TargetStateOption {
 	return func(ts *TargetState) {
@@ -84,10 +100,10 @@ func WithEntries(entries map[string]Entry) TargetStateOption {
 	}
 }
 
-// WithGPG sets the GPG options.
-func WithGPG(gpg *GPG) TargetStateOption {
+// WithReadExternal sets the function used to read the contents of externals.
+func WithReadExternal(readExternal func(*External) ([]byte, error)) TargetStateOption {
 	return func(ts *TargetState) {
-		ts.GPG = gpg
+		ts.ReadExternal = readExternal
 	}
 }
 
@@ -158,6 +174,8 @@ func WithUmask(umask os.FileMode) TargetStateOption {
 func NewTargetState(options ...TargetStateOption) *TargetState {
 	ts := &TargetState{
 		Entries:         make(map[string]Entry),
+		Externals:       make(map[string]*External),
+		externalLoaders: make(map[string]func() error),
 		TargetIgnore:    NewPatternSet(),
 		TargetRemove:    NewPatternSet(),
 		TemplateOptions: DefaultTemplateOptions,
@@ -181,6 +199,9 @@ func (ts *TargetState) Add(fs vfs.FS, addOptions AddOptions, targetPath string,
 	if err != nil {
 		return err
 	}
+	if ts.IsExternal(targetName) {
+		return fmt.Errorf("%s: part of an external", targetName)
+	}
 	if info == nil {
 		var err error
 		if follow {
@@ -261,7 +282,7 @@ func (ts *TargetState) Add(fs vfs.FS, addOptions AddOptions, targetPath string,
 			contents = autoTemplate(contents, ts.TemplateData)
 		}
 		if addOptions.Encrypt {
-			contents, err = ts.GPG.Encrypt(targetPath, contents)
+			contents, err = ts.Encryption.Encrypt(targetPath, contents)
 			if err != nil {
 				return err
 			}
@@ -274,7 +295,7 @@ func (ts *TargetState) Add(fs vfs.FS, addOptions AddOptions, targetPath string,
 		if private {
 			perm &^= 0o77
 		}
-		return ts.addFile(targetName, entries, parentDirSourceName, info, perm, addOptions.Encrypt, addOptions.Template, contents, mutator)
+		return ts.addFile(targetName, entries, parentDirSourceName, info, perm, addOptions.Create, addOptions.Encrypt, addOptions.Template, contents, mutator)
 	case info.Mode()&os.ModeType == os.ModeSymlink:
 		linkname, err := fs.Readlink(targetPath)
 		if err != nil {
@@ -287,30 +308,35 @@ func (ts *TargetState) Add(fs vfs.FS, addOptions AddOptions, targetPath string,
 }
 
 // AllEntries returns all Entrys in ts.
-func (ts *TargetState) AllEntries() []Entry {
+func (ts *TargetState) AllEntries() ([]Entry, error) {
+	if err := ts.loadExternals(allTargetNames); err != nil {
+		return nil, err
+	}
 	var allEntries []Entry
 	for _, entry := range ts.Entries {
 		allEntries = entry.AppendAllEntries(allEntries)
 	}
-	return allEntries
+	return allEntries, nil
 }
 
 // Apply ensures that ts.DestDir in fs matches ts.
 func (ts *TargetState) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptions *ApplyOptions) error {
+	if err := ts.loadExternals(allTargetNames); err != nil {
+		return err
+	}
 	if applyOptions.Remove {
 		// Build a set of targets to remove.
 		targetsToRemove := make(map[string]struct{})
-		includes := make([]string, 0, len(ts.TargetRemove.includes))
-		for include := range ts.TargetRemove.includes {
-			includes = append(includes, include)
-		}
-		for _, include := range includes {
-			matches, err := doublestar.GlobOS(doubleStarOS{FS: fs}, filepath.Join(ts.DestDir, include))
+		for _, include := range ts.TargetRemove.includePatterns() {
+			matches, err := doublestar.GlobOS(doubleStarOS{FS: fs}, filepath.Join(ts.DestDir, filepath.FromSlash(include)))
 			if err != nil {
 				return err
 			}
 			for _, match := range matches {
 				relPath := strings.TrimPrefix(match, ts.DestDir+string(filepath.Separator))
+				if info, err := fs.Lstat(match); err == nil && info.IsDir() {
+					relPath += "/"
+				}
 				// Don't remove targets that are ignored.
 				if ts.TargetIgnore.Match(relPath) {
 					continue
@@ -340,34 +366,48 @@ func (ts *TargetState) Apply(fs vfs.FS, mutator Mutator, follow bool, applyOptio
 		}
 	}
 
-	for _, entryName := range sortedEntryNames(ts.Entries) {
-		if err := ts.Entries[entryName].Apply(fs, mutator, follow, applyOptions); err != nil {
-			return err
-		}
+	return applyEntries(fs, mutator, follow, applyOptions, ts.Entries)
+}
+
+// ApplyEntries ensures that the targets of entries in fs match entries. As
+// with Apply, the before_ and after_ scripts in entries, including those in
+// their subdirectories, are run before and after all other entries.
+func (ts *TargetState) ApplyEntries(fs vfs.FS, mutator Mutator, follow bool, applyOptions *ApplyOptions, entries []Entry) error {
+	entriesByName := make(map[string]Entry, len(entries))
+	for _, entry := range entries {
+		entriesByName[entry.TargetName()] = entry
 	}
-	return nil
+	return applyEntries(fs, mutator, follow, applyOptions, entriesByName)
 }
 
-// Archive writes ts to w.
-func (ts *TargetState) Archive(w *tar.Writer, umask os.FileMode) error {
+// Archive writes ts to w. The contents of block_, merge_, and modify_ files are
+// computed from their targets in fs.
+func (ts *TargetState) Archive(fs vfs.FS, w *tar.Writer, applyOptions *ApplyOptions) error {
+	if err := ts.loadExternals(allTargetNames); err != nil {
+		return err
+	}
 	headerTemplate, err := ts.getTarHeaderTemplate()
 	if err != nil {
 		return err
 	}
 
 	for _, entryName := range sortedEntryNames(ts.Entries) {
-		if err := ts.Entries[entryName].archive(w, ts.TargetIgnore.Match, headerTemplate, umask); err != nil {
+		if err := ts.Entries[entryName].archive(w, fs, applyOptions, headerTemplate); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// ConcreteValue returns a value suitable for serialization.
-func (ts *TargetState) ConcreteValue(recursive bool) (interface{}, error) {
+// ConcreteValue returns a value suitable for serialization. The contents of
+// block_, merge_, and modify_ files are computed from their targets in fs.
+func (ts *TargetState) ConcreteValue(fs vfs.FS, applyOptions *ApplyOptions, recursive bool) (interface{}, error) {
+	if err := ts.loadExternals(allTargetNames); err != nil {
+		return nil, err
+	}
 	var entryConcreteValues []interface{}
 	for _, entryName := range sortedEntryNames(ts.Entries) {
-		entryConcreteValue, err := ts.Entries[entryName].ConcreteValue(ts.TargetIgnore.Match, ts.SourceDir, ts.Umask, recursive)
+		entryConcreteValue, err := ts.Entries[entryName].ConcreteValue(fs, applyOptions, ts.SourceDir, recursive)
 		if err != nil {
 			return nil, err
 		}
@@ -380,6 +420,9 @@ func (ts *TargetState) ConcreteValue(recursive bool) (interface{}, error) {
 
 // Evaluate evaluates all of the entries in ts.
 func (ts *TargetState) Evaluate() error {
+	if err := ts.loadExternals(allTargetNames); err != nil {
+		return err
+	}
 	for _, entryName := range sortedEntryNames(ts.Entries) {
 		if err := ts.Entries[entryName].Evaluate(ts.TargetIgnore.Match); err != nil {
 			return err
@@ -420,6 +463,13 @@ func (ts *TargetState) Get(fs vfs.Stater, target string) (Entry, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Only download the externals that are, contain, or are contained by the
+	// target.
+	if err := ts.loadExternals(func(externalTargetName string) bool {
+		return isSameOrParentTargetName(externalTargetName, targetName) || isSameOrParentTargetName(targetName, externalTargetName)
+	}); err != nil {
+		return nil, err
+	}
 	return ts.findEntry(targetName)
 }
 
@@ -445,9 +495,29 @@ func (ts *TargetState) ImportTAR(r *tar.Reader, importTAROptions ImportTAROption
 	return nil
 }
 
+// IsExternal returns true if targetName is, or is in, an external.
+func (ts *TargetState) IsExternal(targetName string) bool {
+	for {
+		if _, ok := ts.Externals[targetName]; ok {
+			return true
+		}
+		parentName := filepath.Dir(targetName)
+		if parentName == targetName || parentName == "." {
+			return false
+		}
+		targetName = parentName
+	}
+}
+
 // Populate walks fs from ts.SourceDir to populate ts.
 func (ts *TargetState) Populate(fs vfs.FS, options *PopulateOptions) error {
-	return vfs.Walk(fs, ts.SourceDir, func(path string, info os.FileInfo, _ error) error {
+	// Read data before anything else, as .chezmoiignore and .chezmoiremove
+	// templates are executed as soon as they are found.
+	if err := ts.addData(fs); err != nil {
+		return err
+	}
+	var externalPaths []string
+	if err := vfs.Walk(fs, ts.SourceDir, func(path string, info os.FileInfo, _ error) error {
 		relPath, err := filepath.Rel(ts.SourceDir, path)
 		if err != nil {
 			return err
@@ -458,12 +528,19 @@ func (ts *TargetState) Populate(fs vfs.FS, options *PopulateOptions) error {
 		// Treat all files and directories beginning with "." specially.
 		if _, name := filepath.Split(relPath); strings.HasPrefix(name, ".") {
 			switch {
+			case strings.HasPrefix(info.Name(), externalName+".") && !info.IsDir():
+				// Add externals after all other entries so that their parent
+				// directories exist.
+				externalPaths = append(externalPaths, path)
+				return nil
 			case info.Name() == ignoreName:
 				dns := dirNames(parseDirNameComponents(splitPathList(relPath)))
-				return ts.addPatterns(fs, ts.TargetIgnore, path, filepath.Join(dns...))
+				return ts.addPatterns(fs, ts.TargetIgnore, path, filepath.Join(dns...), false)
 			case info.Name() == removeName:
 				dns := dirNames(parseDirNameComponents(splitPathList(relPath)))
-				return ts.addPatterns(fs, ts.TargetRemove, path, filepath.Join(dns...))
+				// Anchor remove patterns so that they never match targets
+				// deep in the destination directory by accident.
+				return ts.addPatterns(fs, ts.TargetRemove, path, filepath.Join(dns...), true)
 			case info.Name() == templatesDirName:
 				if err := ts.addTemplatesDir(fs, path); err != nil {
 					return err
@@ -514,14 +591,14 @@ func (ts *TargetState) Populate(fs vfs.FS, options *PopulateOptions) error {
 					return fs.ReadFile(path)
 				}
 				evaluateContents := readFile
-				if psfp.fileAttributes != nil && psfp.fileAttributes.Encrypted {
+				if psfp.fileAttributes != nil && psfp.fileAttributes.Encrypted || psfp.scriptAttributes != nil && psfp.scriptAttributes.Encrypted {
 					prevEvaluateContents := evaluateContents
 					evaluateContents = func() ([]byte, error) {
 						ciphertext, err := prevEvaluateContents()
 						if err != nil {
 							return nil, err
 						}
-						return ts.GPG.Decrypt(path, ciphertext)
+						return ts.Encryption.Decrypt(path, ciphertext)
 					}
 				}
 				if psfp.fileAttributes != nil && psfp.fileAttributes.Template || psfp.scriptAttributes != nil && psfp.scriptAttributes.Template {
@@ -541,8 +618,12 @@ func (ts *TargetState) Populate(fs vfs.FS, options *PopulateOptions) error {
 					entry := &File{
 						sourceName:       relPath,
 						targetName:       filepath.Join(append(dns, psfp.fileAttributes.Name)...),
+						Block:            psfp.fileAttributes.Block,
+						Create:           psfp.fileAttributes.Create,
 						Empty:            psfp.fileAttributes.Empty,
 						Encrypted:        psfp.fileAttributes.Encrypted,
+						Merge:            psfp.fileAttributes.Merge,
+						Modify:           psfp.fileAttributes.Modify,
 						Perm:             psfp.fileAttributes.Mode.Perm(),
 						Template:         psfp.fileAttributes.Template,
 						evaluateContents: evaluateContents,
@@ -552,7 +633,11 @@ func (ts *TargetState) Populate(fs vfs.FS, options *PopulateOptions) error {
 					entry := &Script{
 						sourceName:       relPath,
 						targetName:       filepath.Join(append(dns, psfp.scriptAttributes.Name)...),
+						Encrypted:        psfp.scriptAttributes.Encrypted,
 						Once:             psfp.scriptAttributes.Once,
+						OnChange:         psfp.scriptAttributes.OnChange,
+						Before:           psfp.scriptAttributes.Before,
+						After:            psfp.scriptAttributes.After,
 						Template:         psfp.scriptAttributes.Template,
 						evaluateContents: evaluateContents,
 					}
@@ -583,7 +668,66 @@ func (ts *TargetState) Populate(fs vfs.FS, options *PopulateOptions) error {
 			return fmt.Errorf("%s: unsupported file type", path)
 		}
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+	for _, externalPath := range externalPaths {
+		if err := ts.addExternals(fs, externalPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addData merges the data in all .chezmoidata files in the source directory
+// into ts.TemplateData. Files are read in lexical order, with later files
+// taking precedence over earlier ones, and ts.TemplateData taking precedence
+// over all files.
+func (ts *TargetState) addData(fs vfs.FS) error {
+	data := make(map[string]interface{})
+	found := false
+	if err := vfs.Walk(fs, ts.SourceDir, func(path string, info os.FileInfo, err error) error {
+		if path == ts.SourceDir {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if !strings.HasPrefix(name, ".") {
+			return nil
+		}
+		if info.IsDir() {
+			// Don't recurse into ignored subdirectories.
+			return filepath.SkipDir
+		}
+		if !strings.HasPrefix(name, dataName+".") {
+			return nil
+		}
+		decode, ok := dataFormats[strings.TrimPrefix(name, dataName+".")]
+		if !ok {
+			return nil
+		}
+		contents, err := fs.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fileData, err := decode(contents)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		mergeData(data, fileData)
+		found = true
+		return nil
+	}); err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	mergeData(data, ts.TemplateData)
+	ts.TemplateData = data
+	return nil
 }
 
 func (ts *TargetState) addDir(targetName string, entries map[string]Entry, parentDirSourceName string, exact bool, perm os.FileMode, createKeepFile bool, mutator Mutator) error {
@@ -615,7 +759,127 @@ func (ts *TargetState) addDir(targetName string, entries map[string]Entry, paren
 	return nil
 }
 
-func (ts *TargetState) addFile(targetName string, entries map[string]Entry, parentDirSourceName string, info os.FileInfo, perm os.FileMode, encrypted, template bool, contents []byte, mutator Mutator) error {
+// addExternal adds the entries for external with targetName, declared in the
+// .chezmoiexternal file sourceName.
+func (ts *TargetState) addExternal(sourceName, targetName string, external *External) error {
+	if ts.ReadExternal == nil {
+		return fmt.Errorf("%s: externals not supported", targetName)
+	}
+
+	// Find the parent directory, adding it to the target state if needed.
+	names := splitPathList(targetName)
+	entries := ts.Entries
+	dirSourceName := ""
+	for i, name := range names[:len(names)-1] {
+		entry, ok := entries[name]
+		if !ok {
+			da := DirAttributes{
+				Name: name,
+				Perm: 0o777,
+			}
+			dirSourceName = filepath.Join(dirSourceName, da.SourceName())
+			entry = newDir(dirSourceName, filepath.Join(names[:i+1]...), false, 0o777)
+			entries[name] = entry
+		}
+		dir, ok := entry.(*Dir)
+		if !ok {
+			return fmt.Errorf("%s: parent is not a directory", targetName)
+		}
+		dirSourceName = dir.sourceName
+		entries = dir.Entries
+	}
+	name := names[len(names)-1]
+	if _, ok := entries[name]; ok {
+		return fmt.Errorf("%s: already in source state", targetName)
+	}
+
+	switch external.Type {
+	case ExternalTypeFile:
+		perm := os.FileMode(0o666)
+		if external.Executable {
+			perm |= 0o111
+		}
+		entries[name] = &File{
+			sourceName: sourceName,
+			targetName: targetName,
+			Empty:      true,
+			Perm:       perm,
+			evaluateContents: func() ([]byte, error) {
+				return ts.ReadExternal(external)
+			},
+		}
+	case ExternalTypeArchive, ExternalTypeGitRepo:
+		// Archives are only downloaded when their entries are needed, see
+		// loadExternals. Until then they are represented by an empty
+		// directory, which is filled in place so that externals nested inside
+		// it are kept.
+		dir := newDir(sourceName, targetName, external.Exact, 0o777)
+		entries[name] = dir
+		ts.externalLoaders[targetName] = func() error {
+			data, err := ts.ReadExternal(external)
+			if err != nil {
+				return err
+			}
+			archiveDir, err := newExternalArchiveDir(sourceName, targetName, external, data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", targetName, err)
+			}
+			for entryName, entry := range archiveDir.Entries {
+				if _, ok := dir.Entries[entryName]; ok {
+					return fmt.Errorf("%s: already in source state", filepath.Join(targetName, entryName))
+				}
+				dir.Entries[entryName] = entry
+			}
+			dir.Perm = archiveDir.Perm
+			return nil
+		}
+	}
+	ts.Externals[targetName] = external
+	return nil
+}
+
+// addExternals adds the externals declared in the .chezmoiexternal file at
+// path.
+func (ts *TargetState) addExternals(fs vfs.FS, path string) error {
+	relPath, err := filepath.Rel(ts.SourceDir, path)
+	if err != nil {
+		return err
+	}
+	decode, ok := dataFormats[strings.TrimPrefix(filepath.Base(path), externalName+".")]
+	if !ok {
+		return nil
+	}
+	contents, err := ts.executeTemplate(fs, path)
+	if err != nil {
+		return err
+	}
+	data, err := decode(contents)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	externals, err := parseExternals(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	dns := dirNames(parseDirNameComponents(splitPathList(filepath.Dir(relPath))))
+	if filepath.Dir(relPath) == "." {
+		dns = nil
+	}
+	names := make([]string, 0, len(externals))
+	for name := range externals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		targetName := filepath.Join(append(dns, name)...)
+		if err := ts.addExternal(relPath, targetName, externals[name]); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (ts *TargetState) addFile(targetName string, entries map[string]Entry, parentDirSourceName string, info os.FileInfo, perm os.FileMode, create, encrypted, template bool, contents []byte, mutator Mutator) error {
 	name := filepath.Base(targetName)
 	var existingFile *File
 	var existingContents []byte
@@ -635,6 +899,7 @@ func (ts *TargetState) addFile(targetName string, entries map[string]Entry, pare
 	sourceName := FileAttributes{
 		Name:      name,
 		Mode:      perm,
+		Create:    create,
 		Empty:     empty,
 		Encrypted: encrypted,
 		Template:  template,
@@ -645,6 +910,7 @@ func (ts *TargetState) addFile(targetName string, entries map[string]Entry, pare
 	file := &File{
 		sourceName: sourceName,
 		targetName: targetName,
+		Create:     create,
 		Empty:      empty,
 		Encrypted:  encrypted,
 		Perm:       perm,
@@ -666,30 +932,20 @@ func (ts *TargetState) addFile(targetName string, entries map[string]Entry, pare
 	return mutator.WriteFile(filepath.Join(ts.SourceDir, sourceName), contents, 0o666&^ts.Umask, existingContents)
 }
 
-func (ts *TargetState) addPatterns(fs vfs.FS, ps *PatternSet, path, relPath string) error {
+func (ts *TargetState) addPatterns(fs vfs.FS, ps *PatternSet, path, relPath string, anchored bool) error {
 	data, err := ts.executeTemplate(fs, path)
 	if err != nil {
 		return err
 	}
-	dir := filepath.Dir(relPath)
+	dir := filepath.ToSlash(filepath.Dir(relPath))
 	s := bufio.NewScanner(bytes.NewReader(data))
-	for s.Scan() {
-		text := s.Text()
-		if index := strings.IndexRune(text, '#'); index != -1 {
-			text = text[:index]
-		}
-		text = strings.TrimSpace(text)
-		if text == "" {
+	for lineNumber := 1; s.Scan(); lineNumber++ {
+		pattern, include, ok := parsePatternLine(dir, s.Text(), anchored)
+		if !ok {
 			continue
 		}
-		include := true
-		if strings.HasPrefix(text, "!") {
-			include = false
-			text = strings.TrimPrefix(text, "!")
-		}
-		pattern := filepath.Join(dir, text)
 		if err := ps.Add(pattern, include); err != nil {
-			return fmt.Errorf("%s: %w", path, err)
+			return fmt.Errorf("%s:%d: %s: %w", path, lineNumber, strings.TrimSpace(s.Text()), err)
 		}
 	}
 	if err := s.Err(); err != nil {
@@ -778,6 +1034,26 @@ func (ts *TargetState) executeTemplate(fs vfs.FS, path string) ([]byte, error) {
 	return ts.ExecuteTemplateData(path, data)
 }
 
+// loadExternals downloads the archive externals whose target names match f and
+// that are not ignored.
+func (ts *TargetState) loadExternals(f func(string) bool) error {
+	targetNames := make([]string, 0, len(ts.externalLoaders))
+	for targetName := range ts.externalLoaders {
+		targetNames = append(targetNames, targetName)
+	}
+	sort.Strings(targetNames)
+	for _, targetName := range targetNames {
+		if !f(targetName) || ts.TargetIgnore.Match(targetName) || ts.TargetIgnore.Match(targetName+"/") {
+			continue
+		}
+		if err := ts.externalLoaders[targetName](); err != nil {
+			return err
+		}
+		delete(ts.externalLoaders, targetName)
+	}
+	return nil
+}
+
 func (ts *TargetState) findEntries(dirNames []string) (map[string]Entry, error) {
 	entries := ts.Entries
 	for i, dirName := range dirNames {
@@ -819,6 +1095,9 @@ func (ts *TargetState) importHeader(r io.Reader, importTAROptions ImportTAROptio
 	if err != nil {
 		return err
 	}
+	if ts.IsExternal(targetName) {
+		return fmt.Errorf("%s: part of an external", targetName)
+	}
 	parentDirSourceName := ""
 	entries := ts.Entries
 	if parentDirName := filepath.Dir(targetName); parentDirName != "." {
@@ -844,7 +1123,7 @@ func (ts *TargetState) importHeader(r io.Reader, importTAROptions ImportTAROptio
 		if err != nil {
 			return err
 		}
-		return ts.addFile(targetName, entries, parentDirSourceName, info, info.Mode().Perm(), false, false, contents, mutator)
+		return ts.addFile(targetName, entries, parentDirSourceName, info, info.Mode().Perm(), false, false, false, contents, mutator)
 	case tar.TypeSymlink:
 		linkname := header.Linkname
 		return ts.addSymlink(targetName, entries, parentDirSourceName, linkname, mutator)
@@ -852,3 +1131,72 @@ func (ts *TargetState) importHeader(r io.Reader, importTAROptions ImportTAROptio
 		return fmt.Errorf("%s: unspported typeflag '%c'", header.Name, header.Typeflag)
 	}
 }
+
+// applyEntries runs all before_ scripts in entries, then applies all other
+// entries, then runs all after_ scripts in entries. The directories in entries
+// that contain a before_ script are created, if they do not already exist,
+// before the script is run so that it runs in its own directory.
+func applyEntries(fs vfs.FS, mutator Mutator, follow bool, applyOptions *ApplyOptions, entries map[string]Entry) error {
+	for _, script := range sortedScripts(entries, applyOptions.Ignore, func(s *Script) bool { return s.Before }) {
+		for _, dir := range parentDirs(entries, script.targetName) {
+			targetPath := filepath.Join(applyOptions.DestDir, dir.targetName)
+			if _, err := fs.Lstat(targetPath); os.IsNotExist(err) {
+				if err := mutator.Mkdir(targetPath, dir.Perm&^applyOptions.Umask); err != nil {
+					return err
+				}
+			} else if err != nil {
+				return err
+			}
+		}
+		if err := script.Apply(fs, mutator, follow, applyOptions); err != nil {
+			return err
+		}
+	}
+	for _, entryName := range sortedEntryNames(entries) {
+		entry := entries[entryName]
+		if isBeforeOrAfterScript(entry) {
+			continue
+		}
+		if err := entry.Apply(fs, mutator, follow, applyOptions); err != nil {
+			return err
+		}
+	}
+	for _, script := range sortedScripts(entries, applyOptions.Ignore, func(s *Script) bool { return s.After }) {
+		if err := script.Apply(fs, mutator, follow, applyOptions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parentDirs returns the directories in entries that are parents of
+// targetName, outermost first.
+func parentDirs(entries map[string]Entry, targetName string) []*Dir {
+	var dirs []*Dir
+	parentTargetName := filepath.Dir(targetName)
+	for {
+		var parentDir *Dir
+		for _, entry := range entries {
+			if dir, ok := entry.(*Dir); ok && isSameOrParentTargetName(dir.targetName, parentTargetName) {
+				parentDir = dir
+				break
+			}
+		}
+		if parentDir == nil {
+			return dirs
+		}
+		dirs = append(dirs, parentDir)
+		entries = parentDir.Entries
+	}
+}
+
+// allTargetNames returns true for all target names.
+func allTargetNames(string) bool {
+	return true
+}
+
+// isSameOrParentTargetName returns true if parentName is targetName or one of
+// its parent directories.
+func isSameOrParentTargetName(parentName, targetName string) bool {
+	return parentName == targetName || strings.HasPrefix(targetName, parentName+string(filepath.Separator))
+}
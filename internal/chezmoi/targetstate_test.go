@@ -366,11 +366,9 @@ func TestTargetStatePopulate(t *testing.T) {
 				WithDestDir("/"),
 				WithSourceDir("/"),
 				WithTargetIgnore(&PatternSet{
-					includes: map[string]struct{}{
-						"f*": {},
-					},
-					excludes: map[string]struct{}{
-						"g": {},
+					entries: []patternSetEntry{
+						{pattern: "**/f*", include: true},
+						{pattern: "**/g", include: false},
 					},
 				}),
 			),
@@ -387,11 +385,9 @@ func TestTargetStatePopulate(t *testing.T) {
 				WithDestDir("/"),
 				WithSourceDir("/"),
 				WithTargetRemove(&PatternSet{
-					includes: map[string]struct{}{
-						"f*": {},
-					},
-					excludes: map[string]struct{}{
-						"g": {},
+					entries: []patternSetEntry{
+						{pattern: "f*", include: true},
+						{pattern: "g", include: false},
 					},
 				}),
 			),
@@ -416,11 +412,9 @@ func TestTargetStatePopulate(t *testing.T) {
 				}),
 				WithSourceDir("/"),
 				WithTargetIgnore(&PatternSet{
-					includes: map[string]struct{}{
-						filepath.Join("dir", "foo"): {},
-					},
-					excludes: map[string]struct{}{
-						filepath.Join("dir", "bar"): {},
+					entries: []patternSetEntry{
+						{pattern: "dir/**/foo", include: true},
+						{pattern: "dir/**/bar", include: false},
 					},
 				}),
 			),
@@ -457,6 +451,47 @@ func TestTargetStatePopulate(t *testing.T) {
 				WithSourceDir("/"),
 			),
 		},
+		{
+			name: "data",
+			root: map[string]interface{}{
+				"/.chezmoidata.json":     `{"colors":{"bg":"black","fg":"white"},"host":"json"}`,
+				"/.chezmoidata.yaml":     "colors:\n  fg: green\nshell: zsh\n",
+				"/dir/.chezmoidata.toml": "editor = \"vim\"\n",
+				"/foo.tmpl":              "{{ .colors.bg }} {{ .colors.fg }} {{ .editor }} {{ .host }} {{ .shell }}",
+			},
+			sourceDir: "/",
+			data: map[string]interface{}{
+				"host": "example.com",
+			},
+			want: NewTargetState(
+				WithDestDir("/"),
+				WithEntries(map[string]Entry{
+					"dir": &Dir{
+						sourceName: "dir",
+						targetName: "dir",
+						Perm:       0o777,
+						Entries:    map[string]Entry{},
+					},
+					"foo": &File{
+						sourceName: "foo.tmpl",
+						targetName: "foo",
+						Perm:       0o666,
+						Template:   true,
+						contents:   []byte("black green vim example.com zsh"),
+					},
+				}),
+				WithSourceDir("/"),
+				WithTemplateData(map[string]interface{}{
+					"colors": map[string]interface{}{
+						"bg": "black",
+						"fg": "green",
+					},
+					"editor": "vim",
+					"host":   "example.com",
+					"shell":  "zsh",
+				}),
+			),
+		},
 		{
 			name: "template_dir",
 			root: map[string]interface{}{
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 // A ParseError is a parse error.
@@ -65,8 +66,19 @@ type IgnoredStatus struct {
 	Path string
 }
 
+// A BranchStatus is the status of the current branch, parsed from the
+// "# branch." headers.
+type BranchStatus struct {
+	OID      string
+	Head     string
+	Upstream string
+	Ahead    int
+	Behind   int
+}
+
 // A Status is a status.
 type Status struct {
+	Branch          *BranchStatus
 	Ordinary        []OrdinaryStatus
 	RenamedOrCopied []RenamedOrCopiedStatus
 	Unmerged        []UnmergedStatus
@@ -119,6 +131,12 @@ var (
 		`(.*)` +
 		`$`,
 	)
+	statusPorcelainV2ZBranchABRegexp = regexp.MustCompile(`` +
+		`^# branch\.ab ` +
+		`\+([0-9]+) ` +
+		`-([0-9]+)` +
+		`$`,
+	)
 	statusPorcelainV2ZIgnoredRegexp = regexp.MustCompile(`` +
 		`^! ` +
 		`(.*)` +
@@ -227,7 +245,9 @@ func ParseStatusPorcelainV2(output []byte) (*Status, error) {
 			}
 			status.Ignored = append(status.Ignored, us)
 		case '#':
-			continue
+			if err := parseBranchHeader(status, text); err != nil {
+				return nil, err
+			}
 		default:
 			return nil, ParseError(text)
 		}
@@ -235,13 +255,27 @@ func ParseStatusPorcelainV2(output []byte) (*Status, error) {
 	if err := s.Err(); err != nil {
 		return nil, err
 	}
-	if status.Empty() {
+	if status.Empty() && status.Branch == nil {
 		return nil, nil
 	}
 	return status, nil
 }
 
-// Empty returns true if s is empty.
+// Diverged returns true if bs's branch and its upstream both have commits that
+// the other does not.
+func (bs *BranchStatus) Diverged() bool {
+	return bs != nil && bs.Ahead > 0 && bs.Behind > 0
+}
+
+// Dirty returns true if s contains changes to tracked files.
+func (s *Status) Dirty() bool {
+	return s != nil && (false ||
+		len(s.Ordinary) != 0 ||
+		len(s.RenamedOrCopied) != 0 ||
+		len(s.Unmerged) != 0)
+}
+
+// Empty returns true if s contains no changes, regardless of its branch.
 func (s *Status) Empty() bool {
 	return s == nil || true &&
 		len(s.Ignored) == 0 &&
@@ -250,3 +284,30 @@ func (s *Status) Empty() bool {
 		len(s.Unmerged) == 0 &&
 		len(s.Untracked) == 0
 }
+
+// parseBranchHeader parses the "# branch." header text into status. Other
+// headers are ignored.
+func parseBranchHeader(status *Status, text string) error {
+	if !strings.HasPrefix(text, "# branch.") {
+		return nil
+	}
+	if status.Branch == nil {
+		status.Branch = &BranchStatus{}
+	}
+	switch {
+	case strings.HasPrefix(text, "# branch.oid "):
+		status.Branch.OID = strings.TrimPrefix(text, "# branch.oid ")
+	case strings.HasPrefix(text, "# branch.head "):
+		status.Branch.Head = strings.TrimPrefix(text, "# branch.head ")
+	case strings.HasPrefix(text, "# branch.upstream "):
+		status.Branch.Upstream = strings.TrimPrefix(text, "# branch.upstream ")
+	case strings.HasPrefix(text, "# branch.ab "):
+		m := statusPorcelainV2ZBranchABRegexp.FindStringSubmatch(text)
+		if m == nil {
+			return ParseError(text)
+		}
+		status.Branch.Ahead, _ = strconv.Atoi(m[1])
+		status.Branch.Behind, _ = strconv.Atoi(m[2])
+	}
+	return nil
+}
@@ -161,6 +161,47 @@ func TestParseStatusPorcelainV2(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "branch",
+			outputStr: "" +
+				"# branch.oid cea5c3500651a923bacd80f960dd20f04f71d509\n" +
+				"# branch.head master\n" +
+				"# branch.upstream origin/master\n" +
+				"# branch.ab +1 -2\n",
+			expectedEmpty: true,
+			expectedStatus: &Status{
+				Branch: &BranchStatus{
+					OID:      "cea5c3500651a923bacd80f960dd20f04f71d509",
+					Head:     "master",
+					Upstream: "origin/master",
+					Ahead:    1,
+					Behind:   2,
+				},
+			},
+		},
+		{
+			name: "branch_initial",
+			outputStr: "" +
+				"# branch.oid (initial)\n" +
+				"# branch.head master\n" +
+				"? chezmoi.go\n",
+			expectedStatus: &Status{
+				Branch: &BranchStatus{
+					OID:  "(initial)",
+					Head: "master",
+				},
+				Untracked: []UntrackedStatus{
+					{
+						Path: "chezmoi.go",
+					},
+				},
+			},
+		},
+		{
+			name:          "stash",
+			outputStr:     "# stash 1\n",
+			expectedEmpty: true,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			actualStatus, err := ParseStatusPorcelainV2([]byte(tc.outputStr))
@@ -170,3 +211,60 @@ func TestParseStatusPorcelainV2(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStatusPorcelainV2Error(t *testing.T) {
+	_, err := ParseStatusPorcelainV2([]byte("# branch.ab 1 2\n"))
+	assert.Error(t, err)
+}
+
+func TestStatusDirtyAndDiverged(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		status           *Status
+		expectedDirty    bool
+		expectedDiverged bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "untracked",
+			status: &Status{
+				Untracked: []UntrackedStatus{
+					{Path: "chezmoi.go"},
+				},
+			},
+		},
+		{
+			name: "modified",
+			status: &Status{
+				Ordinary: []OrdinaryStatus{
+					{X: '.', Y: 'M', Path: "chezmoi.go"},
+				},
+			},
+			expectedDirty: true,
+		},
+		{
+			name: "ahead",
+			status: &Status{
+				Branch: &BranchStatus{Ahead: 1},
+			},
+		},
+		{
+			name: "diverged",
+			status: &Status{
+				Branch: &BranchStatus{Ahead: 1, Behind: 1},
+			},
+			expectedDiverged: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedDirty, tc.status.Dirty())
+			var branch *BranchStatus
+			if tc.status != nil {
+				branch = tc.status.Branch
+			}
+			assert.Equal(t, tc.expectedDiverged, branch.Diverged())
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Package hg contains functions for interacting with Mercurial.
+package hg
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+
+	"github.com/twpayne/chezmoi/internal/git"
+)
+
+// A ParseError is a parse error.
+type ParseError string
+
+type statusEntry struct {
+	code     byte
+	path     string
+	origPath string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("%s: parse error", string(e))
+}
+
+// ParseStatus parses the output of
+//   hg status --copies
+// into a git.Status so that it can be handled in the same way as the status of
+// a git repository. Added files whose copy source was removed are reported as
+// renames. See https://www.mercurial-scm.org/doc/hg.1.html#status.
+func ParseStatus(output []byte) (*git.Status, error) {
+	var entries []*statusEntry
+	s := bufio.NewScanner(bytes.NewReader(output))
+	for s.Scan() {
+		text := s.Text()
+		switch {
+		case len(text) > 2 && text[:2] == "  ":
+			if len(entries) == 0 || entries[len(entries)-1].code != 'A' {
+				return nil, ParseError(text)
+			}
+			entries[len(entries)-1].origPath = text[2:]
+		case len(text) > 2 && text[1] == ' ':
+			entries = append(entries, &statusEntry{
+				code: text[0],
+				path: text[2:],
+			})
+		default:
+			return nil, ParseError(text)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	removed := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.code == 'R' {
+			removed[entry.path] = true
+		}
+	}
+	renamed := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.code == 'A' && removed[entry.origPath] {
+			renamed[entry.origPath] = true
+		}
+	}
+
+	status := &git.Status{}
+	for _, entry := range entries {
+		switch entry.code {
+		case 'A':
+			if renamed[entry.origPath] {
+				status.RenamedOrCopied = append(status.RenamedOrCopied, git.RenamedOrCopiedStatus{
+					X:        'R',
+					Y:        '.',
+					Sub:      "N...",
+					RC:       'R',
+					Path:     entry.path,
+					OrigPath: entry.origPath,
+				})
+			} else {
+				status.Ordinary = append(status.Ordinary, newOrdinaryStatus('A', '.', entry.path))
+			}
+		case 'M':
+			status.Ordinary = append(status.Ordinary, newOrdinaryStatus('M', '.', entry.path))
+		case 'R':
+			if !renamed[entry.path] {
+				status.Ordinary = append(status.Ordinary, newOrdinaryStatus('D', '.', entry.path))
+			}
+		case '!':
+			status.Ordinary = append(status.Ordinary, newOrdinaryStatus('.', 'D', entry.path))
+		case '?':
+			status.Untracked = append(status.Untracked, git.UntrackedStatus{
+				Path: entry.path,
+			})
+		case 'I':
+			status.Ignored = append(status.Ignored, git.IgnoredStatus{
+				Path: entry.path,
+			})
+		case 'C':
+		default:
+			return nil, ParseError(string(entry.code) + " " + entry.path)
+		}
+	}
+	return status, nil
+}
+
+func newOrdinaryStatus(x, y byte, path string) git.OrdinaryStatus {
+	return git.OrdinaryStatus{
+		X:    x,
+		Y:    y,
+		Sub:  "N...",
+		Path: path,
+	}
+}
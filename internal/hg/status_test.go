@@ -0,0 +1,120 @@
+package hg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/twpayne/chezmoi/internal/git"
+)
+
+func TestParseStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		outputStr      string
+		expectedEmpty  bool
+		expectedStatus *git.Status
+	}{
+		{
+			name:           "empty",
+			outputStr:      "",
+			expectedEmpty:  true,
+			expectedStatus: &git.Status{},
+		},
+		{
+			name:      "added",
+			outputStr: "A main.go\n",
+			expectedStatus: &git.Status{
+				Ordinary: []git.OrdinaryStatus{
+					{X: 'A', Y: '.', Sub: "N...", Path: "main.go"},
+				},
+			},
+		},
+		{
+			name:      "modified",
+			outputStr: "M main.go\n",
+			expectedStatus: &git.Status{
+				Ordinary: []git.OrdinaryStatus{
+					{X: 'M', Y: '.', Sub: "N...", Path: "main.go"},
+				},
+			},
+		},
+		{
+			name:      "removed",
+			outputStr: "R main.go\n",
+			expectedStatus: &git.Status{
+				Ordinary: []git.OrdinaryStatus{
+					{X: 'D', Y: '.', Sub: "N...", Path: "main.go"},
+				},
+			},
+		},
+		{
+			name:      "missing",
+			outputStr: "! main.go\n",
+			expectedStatus: &git.Status{
+				Ordinary: []git.OrdinaryStatus{
+					{X: '.', Y: 'D', Sub: "N...", Path: "main.go"},
+				},
+			},
+		},
+		{
+			name: "renamed",
+			outputStr: "" +
+				"A main2.go\n" +
+				"  main.go\n" +
+				"R main.go\n",
+			expectedStatus: &git.Status{
+				RenamedOrCopied: []git.RenamedOrCopiedStatus{
+					{X: 'R', Y: '.', Sub: "N...", RC: 'R', Path: "main2.go", OrigPath: "main.go"},
+				},
+			},
+		},
+		{
+			name: "copied",
+			outputStr: "" +
+				"A main2.go\n" +
+				"  main.go\n",
+			expectedStatus: &git.Status{
+				Ordinary: []git.OrdinaryStatus{
+					{X: 'A', Y: '.', Sub: "N...", Path: "main2.go"},
+				},
+			},
+		},
+		{
+			name: "untracked_and_ignored",
+			outputStr: "" +
+				"? main.go\n" +
+				"I main.go~\n" +
+				"C go.mod\n",
+			expectedStatus: &git.Status{
+				Untracked: []git.UntrackedStatus{
+					{Path: "main.go"},
+				},
+				Ignored: []git.IgnoredStatus{
+					{Path: "main.go~"},
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, err := ParseStatus([]byte(tc.outputStr))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedEmpty, status.Empty())
+			assert.Equal(t, tc.expectedStatus, status)
+		})
+	}
+}
+
+func TestParseStatusErrors(t *testing.T) {
+	for _, outputStr := range []string{
+		"  main.go\n",
+		"X main.go\n",
+		"main.go\n",
+	} {
+		t.Run(outputStr, func(t *testing.T) {
+			_, err := ParseStatus([]byte(outputStr))
+			assert.Error(t, err)
+		})
+	}
+}
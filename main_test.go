@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -40,6 +42,7 @@ func TestScript(t *testing.T) {
 			"chhome":      cmdChHome,
 			"cmpmod":      cmdCmpMod,
 			"edit":        cmdEdit,
+			"httpd":       cmdHTTPD,
 			"mkfile":      cmdMkFile,
 			"mkhomedir":   cmdMkHomeDir,
 			"mksourcedir": cmdMkSourceDir,
@@ -126,6 +129,21 @@ func cmdEdit(ts *testscript.TestScript, neg bool, args []string) {
 	}
 }
 
+// cmdHTTPD starts an HTTP server serving the directory given as its argument
+// and sets the HTTPD_URL environment variable to its URL. The server is stopped
+// when the script exits.
+func cmdHTTPD(ts *testscript.TestScript, neg bool, args []string) {
+	if neg {
+		ts.Fatalf("unsupported: ! httpd")
+	}
+	if len(args) != 1 {
+		ts.Fatalf("usage: httpd dir")
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(ts.MkAbs(args[0]))))
+	ts.Defer(server.Close)
+	ts.Setenv("HTTPD_URL", server.URL)
+}
+
 // cmdMkFile creates empty files.
 func cmdMkFile(ts *testscript.TestScript, neg bool, args []string) {
 	if neg {